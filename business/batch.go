@@ -0,0 +1,170 @@
+package business
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// MaxBatchSize — максимальное число переводов в пакете.
+const MaxBatchSize = 1000
+
+// Состояния переводов пакета.
+const (
+	BatchLineCompleted  = "completed"   // перевод выполнен
+	BatchLineFailed     = "failed"      // перевод неуспешен, причина в коде ошибки
+	BatchLineRolledBack = "rolled_back" // перевод был бы выполнен, но пакет отменён из-за других переводов
+)
+
+// BatchTransfer описывает один перевод пакета.
+type BatchTransfer struct {
+	ToAddress  string         // адрес получателя
+	Amount     money.Decimal  // сумма перевода в валюте кошелька отправителя
+	ToCurrency money.Currency // валюта зачисления для перевода с конвертацией
+}
+
+// BatchRequest описывает пакет переводов с одного кошелька.
+type BatchRequest struct {
+	FromAddress string          // адрес отправителя всех переводов пакета
+	Currency    money.Currency  // ожидаемая валюта переводов, пустая строка — валюта кошелька отправителя
+	Transfers   []BatchTransfer // переводы пакета, от 1 до MaxBatchSize
+}
+
+// BatchLineResult — результат одного перевода пакета.
+type BatchLineResult struct {
+	Index       int                  `json:"index"`                 // номер перевода в пакете, начиная с нуля
+	ToAddress   string               `json:"to"`                    // адрес получателя
+	Amount      money.Decimal        `json:"amount"`                // сумма перевода из запроса
+	Status      string               `json:"status"`                // BatchLineCompleted, BatchLineFailed или BatchLineRolledBack
+	Transaction *TransactionResponse `json:"transaction,omitempty"` // выполненный перевод
+	ErrorCode   string               `json:"error_code,omitempty"`  // код ошибки неуспешного перевода
+	Error       string               `json:"error,omitempty"`       // описание ошибки неуспешного перевода
+}
+
+// BatchResponse — результат пакета переводов.
+type BatchResponse struct {
+	ID          string            `json:"id,omitempty"`       // идентификатор выполненного пакета
+	FromAddress string            `json:"from"`               // адрес отправителя
+	Total       money.Decimal     `json:"total,omitempty"`    // сумма списаний выполненного пакета
+	Currency    money.Currency    `json:"currency,omitempty"` // валюта списаний
+	Lines       []BatchLineResult `json:"lines"`              // результаты переводов в порядке запроса
+}
+
+// SendBatch выполняет пакет переводов с одного кошелька по принципу «всё или ничего».
+//
+// Все переводы выполняются в одной транзакции хранилища так же, как SendMoney,
+// и помечаются общим идентификатором пакета. Если хотя бы один перевод
+// неуспешен, ни один перевод пакета не сохраняется: возвращается результат
+// с причиной ошибки каждого неуспешного перевода и ошибка ErrBatchFailed.
+// Переводы проверяются по порядку, поэтому недостаток средств относится
+// к первому переводу, на который их не хватило.
+// Возможные ошибки:
+// - ErrInvalidBatch — пакет пуст или содержит больше MaxBatchSize переводов
+// - ErrBatchFailed — вместе с результатами переводов
+func (s *Service) SendBatch(req BatchRequest) (*BatchResponse, error) {
+	if len(req.Transfers) == 0 || len(req.Transfers) > MaxBatchSize {
+		return nil, ErrInvalidBatch
+	}
+
+	batchID := uuid.New().String()
+	var (
+		lines        []BatchLineResult
+		transactions []database.Transaction
+	)
+	err := s.runInTransaction(func(tx database.Store) error {
+		// Транзакция может повторяться, результаты прежней попытки отбрасываются
+		lines = make([]BatchLineResult, len(req.Transfers))
+		transactions = transactions[:0]
+
+		if err := lockBatchWallets(tx, req); err != nil {
+			return err
+		}
+
+		failed := false
+		for i, transfer := range req.Transfers {
+			lines[i] = BatchLineResult{Index: i, ToAddress: transfer.ToAddress, Amount: transfer.Amount}
+			transaction, err := s.transfer(tx, TransferRequest{
+				FromAddress: req.FromAddress,
+				ToAddress:   transfer.ToAddress,
+				Amount:      transfer.Amount,
+				Currency:    req.Currency,
+				ToCurrency:  transfer.ToCurrency,
+				batchID:     &batchID,
+			}, nil, "")
+
+			// Бизнес-ошибки обнаруживаются до изменения данных, поэтому после них
+			// транзакция продолжается, чтобы проверить остальные переводы
+			var bizErr *Error
+			switch {
+			case errors.As(err, &bizErr):
+				failed = true
+				lines[i].Status = BatchLineFailed
+				lines[i].ErrorCode = bizErr.Code
+				lines[i].Error = bizErr.Message
+			case err != nil:
+				return err
+			default:
+				lines[i].Status = BatchLineCompleted
+				transactions = append(transactions, transaction)
+			}
+		}
+		if failed {
+			return ErrBatchFailed
+		}
+		return nil
+	})
+
+	if errors.Is(err, ErrBatchFailed) {
+		for i := range lines {
+			if lines[i].Status == BatchLineCompleted {
+				lines[i].Status = BatchLineRolledBack
+			}
+		}
+		return &BatchResponse{FromAddress: req.FromAddress, Lines: lines}, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Все переводы выполнены, поэтому транзакции соответствуют строкам пакета по порядку
+	response := &BatchResponse{ID: batchID, FromAddress: req.FromAddress, Lines: lines}
+	var total money.Amount
+	for i, transaction := range transactions {
+		s.streams.publish(transaction)
+		transactionResponse := newTransactionResponse(transaction)
+		lines[i].Transaction = &transactionResponse
+		total += transaction.Amount
+		response.Currency = transaction.Currency
+	}
+	response.Total = total.Decimal(response.Currency)
+	return response, nil
+}
+
+// lockBatchWallets блокирует кошельки отправителя и получателей пакета req
+// до конца транзакции tx.
+//
+// Как и в lockWallets, блокировки берутся в порядке возрастания адресов, поэтому
+// пакет не может взаимно заблокироваться с переводами и другими пакетами.
+// Отсутствующие кошельки пропускаются: ошибка относится к переводу, где они указаны.
+func lockBatchWallets(tx database.Store, req BatchRequest) error {
+	addresses := []string{req.FromAddress}
+	seen := map[string]bool{req.FromAddress: true}
+	for _, transfer := range req.Transfers {
+		if !seen[transfer.ToAddress] {
+			seen[transfer.ToAddress] = true
+			addresses = append(addresses, transfer.ToAddress)
+		}
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		if _, err := tx.Wallets().LockByAddress(address); err != nil && !errors.Is(err, database.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package business
+
+import (
+	"errors"
+	"testing"
+
+	"payment_system_api/database"
+)
+
+// TestSendBatch проверяет пакетные переводы в хранилище в памяти
+// и в базах данных PostgreSQL и SQLite.
+func TestSendBatch(t *testing.T) {
+	forEachStore(t, testSendBatch)
+}
+
+// testSendBatch выполняет проверки TestSendBatch над сервисом s:
+//   - пустой пакет отклоняется;
+//   - успешный пакет выполняет все переводы под общим идентификатором;
+//   - если хотя бы один перевод неуспешен, не выполняется ни один,
+//     а результат содержит причину ошибки каждого неуспешного перевода;
+//   - балансы кошельков остаются согласованы с главной книгой.
+func testSendBatch(t *testing.T, s *Service) {
+	createTestWallets(t, s, 10000, "batch-a")
+	createTestWallets(t, s, 0, "batch-b", "batch-c")
+
+	if _, err := s.SendBatch(BatchRequest{FromAddress: "batch-a"}); !errors.Is(err, ErrInvalidBatch) {
+		t.Errorf("Ожидалась ошибка ErrInvalidBatch, получена %v", err)
+	}
+
+	batch, err := s.SendBatch(BatchRequest{FromAddress: "batch-a", Transfers: []BatchTransfer{
+		{ToAddress: "batch-b", Amount: "10"},
+		{ToAddress: "batch-c", Amount: "20.50"},
+		{ToAddress: "batch-b", Amount: "5"},
+	}})
+	if err != nil {
+		t.Fatalf("Не удалось выполнить пакет: %v", err)
+	}
+	if batch.ID == "" || batch.Total != "35.50" || len(batch.Lines) != 3 {
+		t.Fatalf("Неверный результат пакета: %+v", batch)
+	}
+	for _, line := range batch.Lines {
+		if line.Status != BatchLineCompleted || line.Transaction == nil || line.Transaction.BatchID != batch.ID {
+			t.Errorf("Неверный результат перевода %d: %+v", line.Index, line)
+		}
+	}
+	if got := balances(t, s); got[0] != 6450 || got[1] != 1500 || got[2] != 2050 {
+		t.Errorf("Балансы %v, ожидалось [6450 1500 2050]", got)
+	}
+
+	failed, err := s.SendBatch(BatchRequest{FromAddress: "batch-a", Transfers: []BatchTransfer{
+		{ToAddress: "batch-b", Amount: "10"},
+		{ToAddress: "batch-unknown", Amount: "1"},
+		{ToAddress: "batch-c", Amount: "100"},
+		{ToAddress: "batch-a", Amount: "1"},
+	}})
+	if !errors.Is(err, ErrBatchFailed) {
+		t.Fatalf("Ожидалась ошибка ErrBatchFailed, получена %v", err)
+	}
+	want := []struct{ status, code string }{
+		{BatchLineRolledBack, ""},
+		{BatchLineFailed, ErrRecipientNotFound.Code},
+		{BatchLineFailed, ErrInsufficientFunds.Code},
+		{BatchLineFailed, ErrSameAddress.Code},
+	}
+	if failed == nil || failed.ID != "" || len(failed.Lines) != len(want) {
+		t.Fatalf("Неверный результат неуспешного пакета: %+v", failed)
+	}
+	for i, line := range failed.Lines {
+		if line.Status != want[i].status || line.ErrorCode != want[i].code || line.Transaction != nil {
+			t.Errorf("Перевод %d: %+v, ожидалось состояние %s и код %q", i, line, want[i].status, want[i].code)
+		}
+	}
+	if got := balances(t, s); got[0] != 6450 || got[1] != 1500 || got[2] != 2050 {
+		t.Errorf("Неуспешный пакет изменил балансы: %v", got)
+	}
+	transactions, err := s.store.Transactions().Find(database.TransactionQuery{})
+	if err != nil || len(transactions) != 3 {
+		t.Errorf("Неуспешный пакет сохранил транзакции: %d, %v", len(transactions), err)
+	}
+
+	report, err := s.VerifyLedger()
+	if err != nil || !report.Balanced {
+		t.Errorf("Главная книга не сбалансирована: %+v, %v", report, err)
+	}
+}
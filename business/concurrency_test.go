@@ -0,0 +1,215 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"payment_system_api/database"
+	"payment_system_api/memory"
+	"payment_system_api/money"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDSN содержит строку подключения к тестовой базе данных.
+const testDSN = "host=127.0.0.1 user=testuser password=testpass dbname=testdb port=5433 sslmode=disable"
+
+// testSchema — схема тестовой базы, в которой работают тесты пакета business,
+// чтобы не пересекаться с тестами пакета database.
+const testSchema = "business_test"
+
+// newTestService возвращает сервис над пустым хранилищем в памяти.
+func newTestService() *Service {
+	return NewService(memory.New(), Options{})
+}
+
+// setupTestDB подключает database.DB к тестовой базе данных в отдельной схеме,
+// создаёт в ней таблицы и возвращает сервис над ней.
+// Если база данных недоступна, тест пропускается.
+func setupTestDB(t *testing.T) *Service {
+	t.Helper()
+
+	config := &gorm.Config{TranslateError: true, Logger: logger.Default.LogMode(logger.Silent)}
+	admin, err := gorm.Open(postgres.Open(testDSN), config)
+	if err != nil {
+		t.Skipf("Тестовая база данных недоступна: %v", err)
+	}
+	if err := admin.Exec("DROP SCHEMA IF EXISTS " + testSchema + " CASCADE").Error; err != nil {
+		t.Fatalf("Не удалось удалить тестовую схему: %v", err)
+	}
+	if err := admin.Exec("CREATE SCHEMA " + testSchema).Error; err != nil {
+		t.Fatalf("Не удалось создать тестовую схему: %v", err)
+	}
+
+	database.DB, err = gorm.Open(postgres.Open(testDSN+" search_path="+testSchema), config)
+	if err != nil {
+		t.Fatalf("Не удалось подключиться к тестовой схеме: %v", err)
+	}
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		t.Fatalf("Не удалось получить пул соединений: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(20)
+	database.Migrate()
+
+	t.Cleanup(func() {
+		sqlDB.Close()
+		admin.Exec("DROP SCHEMA IF EXISTS " + testSchema + " CASCADE")
+	})
+	return NewService(database.NewStore(database.DB), Options{})
+}
+
+// setupTestSQLite подключает database.DB к новой базе данных SQLite
+// во временном каталоге теста, создаёт в ней таблицы и возвращает сервис над ней.
+func setupTestSQLite(t *testing.T) *Service {
+	t.Helper()
+
+	database.ConnectDB("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+	database.DB.Logger = logger.Default.LogMode(logger.Silent)
+	database.Migrate()
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		t.Fatalf("Не удалось получить пул соединений: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return NewService(database.NewStore(database.DB), Options{})
+}
+
+// forEachStore выполняет test над сервисами с хранилищем в памяти и с базами
+// данных PostgreSQL и SQLite, каждый в своём подтесте.
+func forEachStore(t *testing.T, test func(t *testing.T, s *Service)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, newTestService())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		test(t, setupTestDB(t))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		test(t, setupTestSQLite(t))
+	})
+}
+
+// createTestWallets создаёт в хранилище сервиса кошельки с адресами addresses
+// и балансом balance в валюте по умолчанию.
+func createTestWallets(t *testing.T, s *Service, balance money.Amount, addresses ...string) {
+	t.Helper()
+	for _, address := range addresses {
+		if err := s.store.Wallets().Create(&database.Wallet{Address: address, Balance: balance}); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+}
+
+// balances возвращает балансы всех открытых кошельков хранилища сервиса.
+func balances(t *testing.T, s *Service) []money.Amount {
+	t.Helper()
+	wallets, err := s.store.Wallets().List(0, math.MaxInt32)
+	if err != nil {
+		t.Fatalf("Не удалось получить кошельки: %v", err)
+	}
+	result := make([]money.Amount, 0, len(wallets))
+	for _, wallet := range wallets {
+		result = append(result, wallet.Balance)
+	}
+	return result
+}
+
+// sum возвращает сумму amounts.
+func sum(amounts []money.Amount) money.Amount {
+	var total money.Amount
+	for _, amount := range amounts {
+		total += amount
+	}
+	return total
+}
+
+// TestSendMoneyConcurrent проверяет SendMoney под конкурентной нагрузкой
+// в хранилище в памяти и в базах данных PostgreSQL и SQLite.
+//
+// Тест выполняет тысячи параллельных переводов между небольшим числом кошельков,
+// в том числе встречных, и проверяет, что:
+//   - суммарный баланс не изменился;
+//   - ни один баланс не стал отрицательным;
+//   - число записанных транзакций равно числу успешных переводов;
+//   - балансы кошельков совпадают с проводками главной книги.
+func TestSendMoneyConcurrent(t *testing.T) {
+	forEachStore(t, testSendMoneyConcurrent)
+}
+
+// testSendMoneyConcurrent выполняет проверки TestSendMoneyConcurrent над сервисом s.
+func testSendMoneyConcurrent(t *testing.T, s *Service) {
+	const (
+		walletCount   = 5
+		transferCount = 2000
+	)
+	addresses := make([]string, walletCount)
+	for i := range addresses {
+		addresses[i] = fmt.Sprintf("concurrent-wallet-%d", i)
+	}
+	createTestWallets(t, s, 10000, addresses...)
+	before := sum(balances(t, s))
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int64
+	)
+	for i := 0; i < transferCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			from := rand.IntN(walletCount)
+			to := (from + 1 + rand.IntN(walletCount-1)) % walletCount
+			amount := money.Amount(1 + rand.IntN(5000))
+
+			_, err := s.SendMoney(TransferRequest{
+				FromAddress: addresses[from],
+				ToAddress:   addresses[to],
+				Amount:      amount.Decimal(money.DefaultCurrency),
+			})
+			switch {
+			case err == nil:
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			case errors.Is(err, ErrInsufficientFunds):
+			default:
+				t.Errorf("Неожиданная ошибка перевода: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	after := balances(t, s)
+	if sum(after) != before {
+		t.Errorf("Суммарный баланс изменился: было %d, стало %d", before, sum(after))
+	}
+	for _, balance := range after {
+		if balance < 0 {
+			t.Errorf("Найден кошелек с отрицательным балансом: %d", balance)
+		}
+	}
+
+	recorded, err := s.store.Transactions().Find(database.TransactionQuery{})
+	if err != nil {
+		t.Fatalf("Не удалось получить транзакции: %v", err)
+	}
+	if int64(len(recorded)) != succeeded {
+		t.Errorf("Ожидалось %d транзакций, записано %d", succeeded, len(recorded))
+	}
+
+	report, err := s.VerifyLedger()
+	if err != nil {
+		t.Fatalf("Не удалось сверить главную книгу: %v", err)
+	}
+	if !report.Balanced {
+		t.Errorf("Главная книга не сходится: %+v", report)
+	}
+}
@@ -0,0 +1,106 @@
+package business
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"payment_system_api/database"
+)
+
+// MaxTransactionPageSize — максимальный размер страницы списков транзакций.
+// Запросы большего размера ограничиваются этим значением.
+const MaxTransactionPageSize = 100
+
+// encodeCursor кодирует позицию транзакции t в непрозрачную строку курсора.
+func encodeCursor(t database.Transaction) string {
+	raw := t.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatUint(uint64(t.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor разбирает строку курсора. Возвращает ErrInvalidCursor, если курсор повреждён.
+func decodeCursor(cursor string) (database.Position, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return database.Position{}, ErrInvalidCursor
+	}
+	timestampPart, idPart, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return database.Position{}, ErrInvalidCursor
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, timestampPart)
+	if err != nil {
+		return database.Position{}, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(idPart, 10, 64)
+	if err != nil {
+		return database.Position{}, ErrInvalidCursor
+	}
+	return database.Position{Timestamp: timestamp, ID: uint(id)}, nil
+}
+
+// pageSize ограничивает запрошенный размер страницы диапазоном [1, MaxTransactionPageSize].
+func pageSize(limit int) int {
+	if limit <= 0 || limit > MaxTransactionPageSize {
+		return MaxTransactionPageSize
+	}
+	return limit
+}
+
+// findTransactionPage выбирает по запросу query страницу транзакций размера limit,
+// начиная с позиции cursor (пустая строка — с начала), в порядке убывания (timestamp, id).
+//
+// Возвращает транзакции страницы и курсор следующей страницы,
+// пустой, если страница последняя.
+func (s *Service) findTransactionPage(query database.TransactionQuery, cursor string, limit int) ([]database.Transaction, string, error) {
+	limit = pageSize(limit)
+	if cursor != "" {
+		position, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query.Before = &position
+	}
+
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	query.Limit = limit + 1
+	transactions, err := s.store.Transactions().Find(query)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(transactions) <= limit {
+		return transactions, "", nil
+	}
+	transactions = transactions[:limit]
+	return transactions, encodeCursor(transactions[limit-1]), nil
+}
+
+// encodeStreamCursor кодирует позицию фиксации position в непрозрачный
+// идентификатор события потока транзакций.
+func encodeStreamCursor(position database.CommitPosition) string {
+	raw := strconv.FormatUint(position.XID, 10) + "|" + strconv.FormatUint(uint64(position.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeStreamCursor разбирает идентификатор события потока транзакций.
+// Возвращает ErrInvalidCursor, если он повреждён.
+func decodeStreamCursor(cursor string) (database.CommitPosition, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return database.CommitPosition{}, ErrInvalidCursor
+	}
+	xidPart, idPart, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return database.CommitPosition{}, ErrInvalidCursor
+	}
+	xid, err := strconv.ParseUint(xidPart, 10, 64)
+	if err != nil {
+		return database.CommitPosition{}, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(idPart, 10, 64)
+	if err != nil {
+		return database.CommitPosition{}, ErrInvalidCursor
+	}
+	return database.CommitPosition{XID: xid, ID: uint(id)}, nil
+}
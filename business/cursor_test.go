@@ -0,0 +1,34 @@
+package business
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"payment_system_api/database"
+)
+
+// TestCursor проверяет кодирование курсора страницы и отказ от повреждённых курсоров.
+func TestCursor(t *testing.T) {
+	timestamp := time.Date(2025, 8, 25, 16, 3, 10, 813810000, time.FixedZone("", 7*60*60))
+	cursor := encodeCursor(database.Transaction{ID: 42, Timestamp: timestamp})
+
+	position, err := decodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if !position.Timestamp.Equal(timestamp) || position.ID != 42 {
+		t.Errorf("Ожидалась позиция (%v, 42), получено (%v, %d)", timestamp, position.Timestamp, position.ID)
+	}
+
+	for _, bad := range []string{
+		"!!!",
+		base64.RawURLEncoding.EncodeToString([]byte("no-separator")),
+		base64.RawURLEncoding.EncodeToString([]byte("yesterday|1")),
+		base64.RawURLEncoding.EncodeToString([]byte("2025-08-25T09:03:10Z|-1")),
+	} {
+		if _, err := decodeCursor(bad); err != ErrInvalidCursor {
+			t.Errorf("%q: ожидалась ошибка ErrInvalidCursor, получена %v", bad, err)
+		}
+	}
+}
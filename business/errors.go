@@ -0,0 +1,111 @@
+package business
+
+// Error описывает бизнес-ошибку платёжной системы.
+//
+// Code — стабильный машиночитаемый код, на который могут опираться клиенты API,
+// Message — человекочитаемое описание ошибки.
+type Error struct {
+	Code    string // стабильный код ошибки, например "INSUFFICIENT_FUNDS"
+	Message string // описание ошибки для человека
+}
+
+// Error возвращает человекочитаемое описание ошибки.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Каталог бизнес-ошибок. Значения сравниваются через errors.Is,
+// код ошибки не меняется между версиями API.
+var (
+	// ErrSenderNotFound — кошелек отправителя не найден.
+	ErrSenderNotFound = &Error{Code: "SENDER_NOT_FOUND", Message: "кошелек отправителя не найден"}
+	// ErrRecipientNotFound — кошелек получателя не найден.
+	ErrRecipientNotFound = &Error{Code: "RECIPIENT_NOT_FOUND", Message: "кошелек получателя не найден"}
+	// ErrWalletNotFound — кошелек с указанным адресом не найден.
+	ErrWalletNotFound = &Error{Code: "WALLET_NOT_FOUND", Message: "кошелек не найден"}
+	// ErrWalletNotEmpty — кошелёк нельзя закрыть, пока на нём есть средства.
+	ErrWalletNotEmpty = &Error{Code: "WALLET_NOT_EMPTY", Message: "нельзя закрыть кошелек с ненулевым балансом"}
+	// ErrInsufficientFunds — на кошельке отправителя недостаточно средств.
+	ErrInsufficientFunds = &Error{Code: "INSUFFICIENT_FUNDS", Message: "недостаточно средств"}
+	// ErrInvalidAmount — сумма перевода не положительная.
+	ErrInvalidAmount = &Error{Code: "INVALID_AMOUNT", Message: "сумма должна быть положительной"}
+	// ErrAmountPrecision — в сумме больше знаков после запятой, чем допускает валюта кошелька.
+	ErrAmountPrecision = &Error{Code: "AMOUNT_PRECISION", Message: "слишком много знаков после запятой для валюты кошелька"}
+	// ErrCurrencyMismatch — валюты кошельков или перевода не совпадают.
+	ErrCurrencyMismatch = &Error{Code: "CURRENCY_MISMATCH", Message: "валюты кошельков не совпадают"}
+	// ErrFXUnavailable — переводы с конвертацией валют не настроены.
+	ErrFXUnavailable = &Error{Code: "FX_UNAVAILABLE", Message: "переводы с конвертацией валют недоступны"}
+	// ErrRateNotFound — курс для пары валют неизвестен.
+	ErrRateNotFound = &Error{Code: "FX_RATE_NOT_FOUND", Message: "курс для пары валют не найден"}
+	// ErrQuoteExpired — котировка курса устарела.
+	ErrQuoteExpired = &Error{Code: "FX_QUOTE_EXPIRED", Message: "котировка курса устарела"}
+	// ErrSameAddress — адреса отправителя и получателя совпадают.
+	ErrSameAddress = &Error{Code: "SAME_ADDRESS", Message: "нельзя отправлять деньги на тот же адрес"}
+	// ErrInvalidCursor — курсор страницы или идентификатор события потока повреждён или получен не от API.
+	ErrInvalidCursor = &Error{Code: "INVALID_CURSOR", Message: "неверный курсор страницы"}
+	// ErrConcurrentUpdate — транзакция не выполнена из-за конкурентных изменений
+	// после всех повторных попыток; запрос можно повторить.
+	ErrConcurrentUpdate = &Error{Code: "CONCURRENT_UPDATE", Message: "операция прервана конкурентными изменениями, повторите запрос"}
+	// ErrHoldNotFound — резервирование с указанным идентификатором не найдено.
+	ErrHoldNotFound = &Error{Code: "HOLD_NOT_FOUND", Message: "резервирование не найдено"}
+	// ErrHoldNotActive — резервирование уже списано, отменено или истекло.
+	ErrHoldNotActive = &Error{Code: "HOLD_NOT_ACTIVE", Message: "резервирование уже списано, отменено или истекло"}
+	// ErrHoldExpired — срок резервирования истёк, остаток освобождён.
+	ErrHoldExpired = &Error{Code: "HOLD_EXPIRED", Message: "срок резервирования истёк"}
+	// ErrCaptureExceedsHold — сумма списания больше несписанного остатка резервирования.
+	ErrCaptureExceedsHold = &Error{Code: "CAPTURE_EXCEEDS_HOLD", Message: "сумма списания больше остатка резервирования"}
+	// ErrInvalidHoldTTL — срок резервирования не положителен или слишком велик.
+	ErrInvalidHoldTTL = &Error{Code: "INVALID_HOLD_TTL", Message: "срок резервирования должен быть от 1 секунды до 30 дней"}
+	// ErrInvalidBatch — пакет переводов пуст или слишком велик.
+	ErrInvalidBatch = &Error{Code: "INVALID_BATCH", Message: "пакет должен содержать от 1 до 1000 переводов"}
+	// ErrBatchFailed — часть переводов пакета неуспешна, и пакет не выполнен целиком.
+	ErrBatchFailed = &Error{Code: "BATCH_FAILED", Message: "пакет переводов не выполнен: часть переводов неуспешна"}
+	// ErrInvalidExecuteAt — момент выполнения запланированного перевода не в будущем или слишком далёк.
+	ErrInvalidExecuteAt = &Error{Code: "INVALID_EXECUTE_AT", Message: "время выполнения должно быть в будущем, но не дальше чем через год"}
+	// ErrScheduledTransferNotFound — запланированный перевод с указанным идентификатором не найден.
+	ErrScheduledTransferNotFound = &Error{Code: "SCHEDULED_TRANSFER_NOT_FOUND", Message: "запланированный перевод не найден"}
+	// ErrScheduledTransferNotPending — запланированный перевод уже выполнен, неуспешен или отменён.
+	ErrScheduledTransferNotPending = &Error{Code: "SCHEDULED_TRANSFER_NOT_PENDING", Message: "запланированный перевод уже выполнен, неуспешен или отменён"}
+	// ErrScheduledTransferAborted — запланированный перевод не выполнен: все попытки прерваны внутренними ошибками.
+	ErrScheduledTransferAborted = &Error{Code: "SCHEDULED_TRANSFER_ABORTED", Message: "перевод не выполнен: попытки выполнения прерваны внутренними ошибками"}
+	// ErrSenderFrozen — кошелек отправителя заморожен.
+	ErrSenderFrozen = &Error{Code: "SENDER_FROZEN", Message: "кошелек отправителя заморожен"}
+	// ErrRecipientFrozen — кошелек получателя заморожен с блокировкой входящих переводов.
+	ErrRecipientFrozen = &Error{Code: "RECIPIENT_FROZEN", Message: "кошелек получателя заморожен и не принимает переводы"}
+	// ErrWalletNotFrozen — разморозить можно только замороженный кошелёк.
+	ErrWalletNotFrozen = &Error{Code: "WALLET_NOT_FROZEN", Message: "кошелек не заморожен"}
+	// ErrInvalidStatusChange — не указаны причина или исполнитель изменения состояния кошелька.
+	ErrInvalidStatusChange = &Error{Code: "INVALID_STATUS_CHANGE", Message: "необходимо указать причину и исполнителя изменения состояния кошелька"}
+	// ErrInvalidLimit — значение лимита расходов не положительно.
+	ErrInvalidLimit = &Error{Code: "INVALID_LIMIT", Message: "лимит должен быть больше нуля"}
+	// ErrTransferLimitExceeded — сумма перевода больше лимита одного перевода кошелька.
+	ErrTransferLimitExceeded = &Error{Code: "LIMIT_EXCEEDED", Message: "сумма перевода превышает лимит одного перевода"}
+	// ErrDailyLimitExceeded — перевод превысил бы лимит расходов кошелька за сутки.
+	ErrDailyLimitExceeded = &Error{Code: "LIMIT_EXCEEDED", Message: "перевод превышает суточный лимит расходов"}
+	// ErrMonthlyLimitExceeded — перевод превысил бы лимит расходов кошелька за месяц.
+	ErrMonthlyLimitExceeded = &Error{Code: "LIMIT_EXCEEDED", Message: "перевод превышает месячный лимит расходов"}
+	// ErrHourlyCountExceeded — перевод превысил бы лимит числа переводов кошелька за час.
+	ErrHourlyCountExceeded = &Error{Code: "LIMIT_EXCEEDED", Message: "превышено число переводов за час"}
+	// ErrInvalidSchedule — расписание регулярного платежа некорректно.
+	ErrInvalidSchedule = &Error{Code: "INVALID_SCHEDULE", Message: "некорректное расписание регулярного платежа"}
+	// ErrStandingOrderNotFound — регулярный платёж с указанным идентификатором не найден.
+	ErrStandingOrderNotFound = &Error{Code: "STANDING_ORDER_NOT_FOUND", Message: "регулярный платёж не найден"}
+	// ErrStandingOrderNotActive — регулярный платёж уже завершён или отменён.
+	ErrStandingOrderNotActive = &Error{Code: "STANDING_ORDER_NOT_ACTIVE", Message: "регулярный платёж уже завершён или отменён"}
+	// ErrTransactionNotFound — транзакция с указанным UUID не найдена.
+	ErrTransactionNotFound = &Error{Code: "TRANSACTION_NOT_FOUND", Message: "транзакция не найдена"}
+	// ErrNotRefundable — транзакция сама является возвратом и не может быть возвращена.
+	ErrNotRefundable = &Error{Code: "TRANSACTION_NOT_REFUNDABLE", Message: "возврат нельзя вернуть"}
+	// ErrRefundExceedsAmount — сумма возврата больше невозвращённого остатка транзакции.
+	ErrRefundExceedsAmount = &Error{Code: "REFUND_EXCEEDS_AMOUNT", Message: "сумма возврата больше невозвращённого остатка транзакции"}
+	// ErrRefundInsufficientFunds — у получателя исходного перевода недостаточно средств для возврата.
+	ErrRefundInsufficientFunds = &Error{Code: "REFUND_INSUFFICIENT_FUNDS", Message: "у получателя недостаточно средств для возврата"}
+	// ErrWebhookNotFound — вебхук с указанным идентификатором не найден.
+	ErrWebhookNotFound = &Error{Code: "WEBHOOK_NOT_FOUND", Message: "вебхук не найден"}
+	// ErrDeliveryNotFound — доставка вебхука с указанным идентификатором не найдена.
+	ErrDeliveryNotFound = &Error{Code: "WEBHOOK_DELIVERY_NOT_FOUND", Message: "доставка вебхука не найдена"}
+	// ErrInvalidWebhookURL — адрес вебхука не является абсолютным URL со схемой http или https.
+	ErrInvalidWebhookURL = &Error{Code: "INVALID_WEBHOOK_URL", Message: "адрес вебхука должен быть URL со схемой http или https"}
+	// ErrIdempotencyKeyConflict — ключ идемпотентности уже использован с другим телом запроса.
+	ErrIdempotencyKeyConflict = &Error{Code: "IDEMPOTENCY_KEY_CONFLICT", Message: "ключ идемпотентности уже использован с другим запросом"}
+)
@@ -0,0 +1,76 @@
+package business
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/events"
+)
+
+// recordTransferCompleted записывает в исходящую очередь событие
+// events.TypeTransferCompleted о переводе transaction в рамках транзакции tx.
+//
+// Событие сохраняется атомарно с переводом: оно появляется в очереди тогда
+// и только тогда, когда перевод зафиксирован.
+func recordTransferCompleted(tx database.Store, transaction database.Transaction) error {
+	payload, err := json.Marshal(newTransactionResponse(transaction))
+	if err != nil {
+		return err
+	}
+	return tx.Outbox().Create(&database.OutboxEvent{Type: events.TypeTransferCompleted, Payload: string(payload)})
+}
+
+// PublishEvents публикует через publisher не более limit неопубликованных событий
+// исходящей очереди в порядке их создания и возвращает число опубликованных.
+//
+// Публикация выполняется в транзакции хранилища под блокировкой очереди, поэтому
+// события публикует только один экземпляр сервиса одновременно; если блокировку
+// удерживает другой экземпляр, PublishEvents ничего не делает.
+// Событие отмечается опубликованным только после успешной публикации, поэтому
+// после сбоя оно публикуется повторно. Если публикация события не удалась,
+// оставшиеся события ждут следующего вызова, чтобы не нарушить порядок;
+// ошибка публикации возвращается вместе с числом опубликованных до неё событий.
+//
+// Переводы одного кошелька выполняются по очереди под блокировкой кошелька,
+// поэтому их события получают идентификаторы в порядке фиксации
+// и публикуются в порядке возникновения.
+func (s *Service) PublishEvents(publisher events.Publisher, limit int) (int, error) {
+	var (
+		published  int
+		publishErr error
+	)
+	err := s.runInTransaction(func(tx database.Store) error {
+		published, publishErr = 0, nil
+		locked, err := tx.Outbox().TryLock()
+		if err != nil || !locked {
+			return err
+		}
+		pending, err := tx.Outbox().Pending(limit)
+		if err != nil {
+			return err
+		}
+		for _, event := range pending {
+			err := publisher.Publish(events.Event{
+				ID:        event.ID,
+				Type:      event.Type,
+				Payload:   json.RawMessage(event.Payload),
+				CreatedAt: event.CreatedAt,
+			})
+			if err != nil {
+				publishErr = fmt.Errorf("событие %d: %w", event.ID, err)
+				return tx.Outbox().MarkFailed(event.ID, err.Error())
+			}
+			if err := tx.Outbox().MarkPublished(event.ID, time.Now()); err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, publishErr
+}
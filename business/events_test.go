@@ -0,0 +1,94 @@
+package business
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"payment_system_api/events"
+	"payment_system_api/money"
+)
+
+// recordingPublisher запоминает опубликованные события
+// и отказывает в публикации событий из failures по одному разу.
+type recordingPublisher struct {
+	failures  map[uint]bool
+	published []events.Event
+}
+
+// Publish запоминает событие или возвращает ошибку, если его публикация должна не удаться.
+func (p *recordingPublisher) Publish(event events.Event) error {
+	if p.failures[event.ID] {
+		delete(p.failures, event.ID)
+		return errors.New("брокер недоступен")
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+// TestPublishEvents проверяет публикацию событий о переводах
+// в хранилище в памяти и в базах данных PostgreSQL и SQLite.
+func TestPublishEvents(t *testing.T) {
+	forEachStore(t, testPublishEvents)
+}
+
+// testPublishEvents выполняет проверки TestPublishEvents над сервисом s:
+//   - каждый успешный перевод порождает ровно одно событие, неуспешный и повтор
+//     идемпотентного запроса — ни одного;
+//   - события публикуются в порядке переводов;
+//   - после неудачной публикации оставшиеся события ждут следующего вызова
+//     и публикуются без пропусков и повторов.
+func testPublishEvents(t *testing.T, s *Service) {
+	createTestWallets(t, s, 10000, "events-a", "events-b")
+
+	var uuids []string
+	for _, amount := range []string{"1", "2", "3"} {
+		response, _, err := s.SendMoneyIdempotent("events-"+amount, TransferRequest{
+			FromAddress: "events-a", ToAddress: "events-b", Amount: money.Decimal(amount),
+		})
+		if err != nil {
+			t.Fatalf("Не удалось выполнить перевод: %v", err)
+		}
+		uuids = append(uuids, response.UUID)
+	}
+	if _, _, err := s.SendMoneyIdempotent("events-1", TransferRequest{
+		FromAddress: "events-a", ToAddress: "events-b", Amount: "1",
+	}); err != nil {
+		t.Fatalf("Не удалось повторить перевод: %v", err)
+	}
+	if _, err := s.SendMoney(TransferRequest{FromAddress: "events-a", ToAddress: "events-b", Amount: "1000000"}); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("Ожидалась ошибка ErrInsufficientFunds, получена %v", err)
+	}
+
+	publisher := &recordingPublisher{failures: make(map[uint]bool)}
+	pending, err := s.store.Outbox().Pending(10)
+	if err != nil || len(pending) != 3 {
+		t.Fatalf("Ожидалось 3 события в очереди, получено %d, %v", len(pending), err)
+	}
+	publisher.failures[pending[1].ID] = true
+
+	published, err := s.PublishEvents(publisher, 10)
+	if err == nil || published != 1 {
+		t.Fatalf("Ожидалась ошибка после первого события, опубликовано %d, ошибка %v", published, err)
+	}
+	published, err = s.PublishEvents(publisher, 10)
+	if err != nil || published != 2 {
+		t.Fatalf("Ожидалось 2 события, опубликовано %d, ошибка %v", published, err)
+	}
+	if published, err := s.PublishEvents(publisher, 10); err != nil || published != 0 {
+		t.Errorf("Повторная публикация: опубликовано %d, ошибка %v", published, err)
+	}
+
+	if len(publisher.published) != len(uuids) {
+		t.Fatalf("Ожидалось %d событий, опубликовано %d", len(uuids), len(publisher.published))
+	}
+	for i, event := range publisher.published {
+		var transfer TransactionResponse
+		if err := json.Unmarshal(event.Payload, &transfer); err != nil {
+			t.Fatalf("Данные события не являются переводом: %v", err)
+		}
+		if event.Type != events.TypeTransferCompleted || transfer.UUID != uuids[i] {
+			t.Errorf("Событие %d: ожидался перевод %s, получено %s %s", i, uuids[i], event.Type, transfer.UUID)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package business
+
+import (
+	"errors"
+	"time"
+
+	"payment_system_api/fx"
+	"payment_system_api/money"
+)
+
+// QuoteResponse представляет котировку курса обмена, возвращаемую в API.
+type QuoteResponse struct {
+	From      money.Currency `json:"from"`       // валюта списания
+	To        money.Currency `json:"to"`         // валюта зачисления
+	Rate      string         `json:"rate"`       // количество единиц To за одну единицу From
+	QuotedAt  time.Time      `json:"quoted_at"`  // момент, на который получен курс
+	ExpiresAt time.Time      `json:"expires_at"` // момент, после которого котировка не используется
+}
+
+// GetQuote возвращает актуальную котировку обмена валюты from на to.
+//
+// Возможные ошибки:
+// - ErrFXUnavailable
+// - ErrRateNotFound
+// - ErrQuoteExpired
+func (s *Service) GetQuote(from, to money.Currency) (*QuoteResponse, error) {
+	quote, err := s.currentQuote(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &QuoteResponse{
+		From:      quote.From,
+		To:        quote.To,
+		Rate:      quote.RateString(),
+		QuotedAt:  quote.QuotedAt,
+		ExpiresAt: quote.QuotedAt.Add(s.options.FXQuoteTTL),
+	}, nil
+}
+
+// currentQuote запрашивает котировку у источника курсов и проверяет, что она не истекла.
+func (s *Service) currentQuote(from, to money.Currency) (fx.Quote, error) {
+	if s.options.RateProvider == nil {
+		return fx.Quote{}, ErrFXUnavailable
+	}
+	quote, err := s.options.RateProvider.Quote(from, to)
+	if errors.Is(err, fx.ErrRateNotFound) {
+		return fx.Quote{}, ErrRateNotFound
+	}
+	if err != nil {
+		return fx.Quote{}, err
+	}
+	if quote.Expired(time.Now(), s.options.FXQuoteTTL) {
+		return fx.Quote{}, ErrQuoteExpired
+	}
+	return quote, nil
+}
@@ -0,0 +1,55 @@
+package business
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"payment_system_api/fx"
+	"payment_system_api/memory"
+	"payment_system_api/money"
+)
+
+// stubRateProvider возвращает заранее заданную котировку для любой пары валют.
+type stubRateProvider struct {
+	quote fx.Quote
+	err   error
+}
+
+// Quote возвращает заданную котировку с подставленной парой валют.
+func (p stubRateProvider) Quote(from, to money.Currency) (fx.Quote, error) {
+	quote := p.quote
+	quote.From, quote.To = from, to
+	return quote, p.err
+}
+
+// TestGetQuote проверяет получение котировки и ошибки при отсутствии
+// источника курсов, неизвестной паре и устаревшей котировке.
+func TestGetQuote(t *testing.T) {
+	service := func(provider fx.RateProvider) *Service {
+		return NewService(memory.New(), Options{RateProvider: provider, FXQuoteTTL: time.Minute})
+	}
+
+	if _, err := service(nil).GetQuote("USD", "RUB"); !errors.Is(err, ErrFXUnavailable) {
+		t.Errorf("Ожидалась ошибка ErrFXUnavailable, получена %v", err)
+	}
+
+	if _, err := service(stubRateProvider{err: fx.ErrRateNotFound}).GetQuote("USD", "RUB"); !errors.Is(err, ErrRateNotFound) {
+		t.Errorf("Ожидалась ошибка ErrRateNotFound, получена %v", err)
+	}
+
+	expired := stubRateProvider{quote: fx.Quote{Rate: big.NewRat(185, 2), QuotedAt: time.Now().Add(-2 * time.Minute)}}
+	if _, err := service(expired).GetQuote("USD", "RUB"); !errors.Is(err, ErrQuoteExpired) {
+		t.Errorf("Ожидалась ошибка ErrQuoteExpired, получена %v", err)
+	}
+
+	quotedAt := time.Now()
+	quote, err := service(stubRateProvider{quote: fx.Quote{Rate: big.NewRat(185, 2), QuotedAt: quotedAt}}).GetQuote("USD", "RUB")
+	if err != nil {
+		t.Fatalf("Не удалось получить котировку: %v", err)
+	}
+	if quote.Rate != "92.5000000000" || !quote.ExpiresAt.Equal(quotedAt.Add(time.Minute)) {
+		t.Errorf("Неверная котировка: %+v", quote)
+	}
+}
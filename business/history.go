@@ -0,0 +1,103 @@
+package business
+
+import (
+	"errors"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// Направления перевода относительно кошелька.
+const (
+	DirectionIn  = "in"  // входящий перевод
+	DirectionOut = "out" // исходящий перевод
+)
+
+// TransactionFilter задаёт фильтры истории транзакций кошелька.
+// Нулевые значения полей означают отсутствие соответствующего фильтра.
+type TransactionFilter struct {
+	Direction string        // DirectionIn, DirectionOut или пустая строка для обоих направлений
+	Since     *time.Time    // транзакции не раньше этого момента
+	Until     *time.Time    // транзакции раньше этого момента
+	MinAmount money.Decimal // минимальная сумма в валюте кошелька
+	MaxAmount money.Decimal // максимальная сумма в валюте кошелька
+	Limit     int           // размер страницы, не больше MaxTransactionPageSize
+	Cursor    string        // курсор страницы, пустая строка — первая страница
+}
+
+// WalletTransactionResponse представляет транзакцию в истории кошелька.
+type WalletTransactionResponse struct {
+	TransactionResponse
+	Direction string `json:"direction"` // направление относительно кошелька: in или out
+}
+
+// WalletTransactionPage — страница истории транзакций кошелька.
+type WalletTransactionPage struct {
+	Transactions []WalletTransactionResponse `json:"transactions"`          // транзакции страницы
+	NextCursor   string                      `json:"next_cursor,omitempty"` // курсор следующей страницы, пустой на последней
+}
+
+// GetWalletTransactions возвращает историю входящих и исходящих транзакций кошелька
+// по адресу, отфильтрованную по filter и отсортированную по времени в порядке убывания.
+// Возвращает одну страницу, начиная с filter.Cursor.
+//
+// Суммы фильтра сравниваются с суммой, изменившей баланс кошелька:
+// для исходящих переводов — суммой списания, для входящих — суммой зачисления.
+// История закрытых кошельков остаётся доступной.
+// Возможные ошибки:
+// - ErrWalletNotFound
+// - ErrInvalidAmount, ErrAmountPrecision — некорректные границы суммы
+// - ErrInvalidCursor
+func (s *Service) GetWalletTransactions(address string, filter TransactionFilter) (*WalletTransactionPage, error) {
+	wallet, err := s.store.Wallets().FindByAddressUnscoped(address)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query := database.TransactionQuery{
+		Address:  address,
+		Outgoing: filter.Direction != DirectionIn,
+		Incoming: filter.Direction != DirectionOut,
+		Since:    filter.Since,
+		Until:    filter.Until,
+	}
+	if filter.MinAmount != "" {
+		minAmount, err := toAmount(filter.MinAmount, wallet.Currency)
+		if err != nil {
+			return nil, err
+		}
+		query.MinAmount = &minAmount
+	}
+	if filter.MaxAmount != "" {
+		maxAmount, err := toAmount(filter.MaxAmount, wallet.Currency)
+		if err != nil {
+			return nil, err
+		}
+		query.MaxAmount = &maxAmount
+	}
+
+	transactions, next, err := s.findTransactionPage(query, filter.Cursor, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err := s.findRefunds(transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &WalletTransactionPage{Transactions: make([]WalletTransactionResponse, 0, len(transactions)), NextCursor: next}
+	for _, t := range transactions {
+		item := WalletTransactionResponse{TransactionResponse: newTransactionResponse(t), Direction: DirectionOut}
+		item.Refunds = refunds[t.UUID]
+		if t.ToAddress == address {
+			item.Direction = DirectionIn
+		}
+		page.Transactions = append(page.Transactions, item)
+	}
+	return page, nil
+}
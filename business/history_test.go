@@ -0,0 +1,95 @@
+package business
+
+import (
+	"testing"
+	"time"
+
+	"payment_system_api/money"
+)
+
+// TestGetWalletTransactions проверяет фильтры истории транзакций кошелька
+// по направлению, сумме и периоду и постраничный обход истории.
+func TestGetWalletTransactions(t *testing.T) {
+	s := newTestService()
+	createTestWallets(t, s, 100000, "history-a", "history-b", "history-c")
+	transfers := []TransferRequest{
+		{FromAddress: "history-a", ToAddress: "history-b", Amount: "10"},
+		{FromAddress: "history-b", ToAddress: "history-a", Amount: "20"},
+		{FromAddress: "history-a", ToAddress: "history-c", Amount: "30"},
+		{FromAddress: "history-b", ToAddress: "history-c", Amount: "40"},
+	}
+	for _, req := range transfers {
+		if _, err := s.SendMoney(req); err != nil {
+			t.Fatalf("Не удалось выполнить перевод: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		filter TransactionFilter
+		want   []money.Decimal
+	}{
+		{"все направления", TransactionFilter{}, []money.Decimal{"30.00", "20.00", "10.00"}},
+		{"исходящие", TransactionFilter{Direction: DirectionOut}, []money.Decimal{"30.00", "10.00"}},
+		{"входящие", TransactionFilter{Direction: DirectionIn}, []money.Decimal{"20.00"}},
+		{"диапазон сумм", TransactionFilter{MinAmount: "15", MaxAmount: "25"}, []money.Decimal{"20.00"}},
+		{"лимит", TransactionFilter{Limit: 1}, []money.Decimal{"30.00"}},
+	}
+	for _, tc := range cases {
+		if tc.filter.Limit == 0 {
+			tc.filter.Limit = 10
+		}
+		history, err := s.GetWalletTransactions("history-a", tc.filter)
+		if err != nil {
+			t.Fatalf("%s: неожиданная ошибка %v", tc.name, err)
+		}
+		var got []money.Decimal
+		for _, item := range history.Transactions {
+			got = append(got, item.Amount)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: ожидалось %v, получено %v", tc.name, tc.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: ожидалось %v, получено %v", tc.name, tc.want, got)
+				break
+			}
+		}
+	}
+
+	future := time.Now().Add(time.Hour)
+	history, err := s.GetWalletTransactions("history-a", TransactionFilter{Since: &future, Limit: 10})
+	if err != nil || len(history.Transactions) != 0 {
+		t.Errorf("Ожидалась пустая история за будущий период, получено %v, %v", history, err)
+	}
+
+	// Обход по одной транзакции на страницу проходит всю историю без пропусков и повторов
+	var pages []money.Decimal
+	filter := TransactionFilter{Limit: 1}
+	for {
+		page, err := s.GetWalletTransactions("history-a", filter)
+		if err != nil {
+			t.Fatalf("Неожиданная ошибка при обходе страниц: %v", err)
+		}
+		for _, item := range page.Transactions {
+			pages = append(pages, item.Amount)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+	if len(pages) != 3 || pages[0] != "30.00" || pages[1] != "20.00" || pages[2] != "10.00" {
+		t.Errorf("Ожидались страницы [30.00 20.00 10.00], получено %v", pages)
+	}
+
+	if _, err := s.GetWalletTransactions("history-a", TransactionFilter{Limit: 10, Cursor: "bad"}); err != ErrInvalidCursor {
+		t.Errorf("Ожидалась ошибка ErrInvalidCursor, получена %v", err)
+	}
+
+	if _, err := s.GetWalletTransactions("unknown", TransactionFilter{Limit: 10}); err != ErrWalletNotFound {
+		t.Errorf("Ожидалась ошибка ErrWalletNotFound, получена %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+package business
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// MaxHoldTTL — максимальный срок резервирования средств.
+const MaxHoldTTL = 30 * 24 * time.Hour
+
+// HoldRequest содержит параметры резервирования средств.
+type HoldRequest struct {
+	WalletAddress string         // кошелёк, на котором резервируются средства
+	ToAddress     string         // кошелёк, на который будут зачислены списания
+	Amount        money.Decimal  // резервируемая сумма
+	Currency      money.Currency // ожидаемая валюта кошелька; пустая — не проверяется
+	TTL           time.Duration  // срок резервирования; 0 — Options.HoldTTL
+}
+
+// HoldResponse представляет резервирование средств, возвращаемое в API.
+type HoldResponse struct {
+	ID        string         `json:"id"`         // идентификатор резервирования
+	Wallet    string         `json:"wallet"`     // кошелёк, на котором зарезервированы средства
+	To        string         `json:"to"`         // кошелёк, на который зачисляются списания
+	Amount    money.Decimal  `json:"amount"`     // зарезервированная сумма
+	Captured  money.Decimal  `json:"captured"`   // уже списанная сумма
+	Remaining money.Decimal  `json:"remaining"`  // несписанный остаток
+	Currency  money.Currency `json:"currency"`   // валюта резервирования
+	Status    string         `json:"status"`     // active, captured, voided или expired
+	ExpiresAt time.Time      `json:"expires_at"` // момент автоматического освобождения остатка
+	CreatedAt time.Time      `json:"created_at"` // время резервирования
+	UpdatedAt time.Time      `json:"updated_at"` // время последнего изменения
+}
+
+// CaptureResponse — результат списания зарезервированных средств.
+type CaptureResponse struct {
+	Hold        HoldResponse        `json:"hold"`        // резервирование после списания
+	Transaction TransactionResponse `json:"transaction"` // перевод списанной суммы получателю
+}
+
+// CreateHold резервирует сумму req.Amount на кошельке req.WalletAddress
+// для последующего списания в пользу кошелька req.ToAddress.
+//
+// Зарезервированная сумма уменьшает доступный баланс кошелька, но не учётный:
+// главная книга не меняется до списания. Несписанный остаток освобождается
+// отменой или автоматически по истечении срока (см. ExpireHolds).
+// Резервирование проверяется по лимитам расходов кошелька как перевод
+// суммы req.Amount (см. SetWalletLimits).
+// Возможные ошибки:
+// - ErrSenderNotFound, ErrRecipientNotFound
+// - ErrSenderFrozen, ErrRecipientFrozen
+// - ErrInsufficientFunds — доступного баланса недостаточно
+// - ErrInvalidAmount, ErrAmountPrecision
+// - ErrSameAddress
+// - ErrCurrencyMismatch — валюты кошельков или req.Currency не совпадают
+// - ErrInvalidHoldTTL
+// - ErrTransferLimitExceeded, ErrDailyLimitExceeded, ErrMonthlyLimitExceeded,
+// ErrHourlyCountExceeded — резервирование нарушает лимит расходов
+func (s *Service) CreateHold(req HoldRequest) (*HoldResponse, error) {
+	ttl := req.TTL
+	if ttl == 0 {
+		ttl = s.options.HoldTTL
+	}
+	if ttl < time.Second || ttl > MaxHoldTTL {
+		return nil, ErrInvalidHoldTTL
+	}
+	if req.Amount.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if req.WalletAddress == req.ToAddress {
+		return nil, ErrSameAddress
+	}
+
+	var hold database.Hold
+	err := s.runInTransaction(func(tx database.Store) error {
+		wallet, toWallet, err := lockWallets(tx, req.WalletAddress, req.ToAddress)
+		if err != nil {
+			return err
+		}
+		if err := checkWalletStatus(wallet, toWallet); err != nil {
+			return err
+		}
+		// Списания выполняются без конвертации
+		if (req.Currency != "" && req.Currency != wallet.Currency) || toWallet.Currency != wallet.Currency {
+			return ErrCurrencyMismatch
+		}
+		amount, err := toAmount(req.Amount, wallet.Currency)
+		if err != nil {
+			return err
+		}
+		if wallet.Balance-wallet.Held < amount {
+			return ErrInsufficientFunds
+		}
+		if err := checkLimits(tx, wallet, amount); err != nil {
+			return err
+		}
+
+		if err := tx.Wallets().AddHeld(wallet.ID, amount); err != nil {
+			return err
+		}
+		hold = database.Hold{
+			WalletAddress: wallet.Address,
+			ToAddress:     toWallet.Address,
+			Amount:        amount,
+			Currency:      wallet.Currency,
+			Status:        database.HoldActive,
+			ExpiresAt:     time.Now().Add(ttl),
+		}
+		return tx.Holds().Create(&hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := newHoldResponse(hold)
+	return &response, nil
+}
+
+// GetHold возвращает резервирование по идентификатору.
+//
+// Возвращает ErrHoldNotFound, если резервирование не найдено.
+func (s *Service) GetHold(id string) (*HoldResponse, error) {
+	hold, err := s.store.Holds().FindByUUID(id)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	response := newHoldResponse(*hold)
+	return &response, nil
+}
+
+// CaptureHold списывает сумму amount из резервирования id переводом
+// на кошелёк получателя резервирования; nil — весь несписанный остаток.
+//
+// Резервирование можно списывать частями, пока остаток не исчерпан.
+// Перевод выполняется так же, как SendMoney: с записью в главную книгу,
+// событием TransferCompleted и вебхуками, но без проверки лимитов расходов —
+// они проверены при создании резервирования.
+// Возможные ошибки:
+// - ErrHoldNotFound
+// - ErrHoldNotActive — резервирование уже списано, отменено или истекло
+// - ErrHoldExpired — срок истёк к моменту списания, остаток освобождён
+// - ErrCaptureExceedsHold
+// - ErrInvalidAmount, ErrAmountPrecision
+// - ErrRecipientNotFound — кошелёк получателя закрыт
+func (s *Service) CaptureHold(id string, amount *money.Decimal) (*CaptureResponse, error) {
+	var (
+		hold        *database.Hold
+		transaction database.Transaction
+		expired     bool
+	)
+	err := s.runInTransaction(func(tx database.Store) error {
+		var err error
+		if hold, err = lockActiveHold(tx, id); err != nil {
+			return err
+		}
+		if !hold.ExpiresAt.After(time.Now()) {
+			// Изменение фиксируется, а ошибка возвращается после фиксации
+			expired = true
+			return releaseHold(tx, hold, database.HoldExpired)
+		}
+
+		captured := hold.Remaining()
+		if amount != nil {
+			if amount.Sign() <= 0 {
+				return ErrInvalidAmount
+			}
+			if captured, err = toAmount(*amount, hold.Currency); err != nil {
+				return err
+			}
+			if captured > hold.Remaining() {
+				return ErrCaptureExceedsHold
+			}
+		}
+
+		// Резерв снимается до перевода, чтобы перевод мог использовать эти средства.
+		// Кошельки блокируются в том же порядке, что и в transfer
+		wallet, _, err := lockWallets(tx, hold.WalletAddress, hold.ToAddress)
+		if err != nil {
+			return err
+		}
+		if err := tx.Wallets().AddHeld(wallet.ID, -captured); err != nil {
+			return err
+		}
+		transaction, err = s.transfer(tx, TransferRequest{
+			FromAddress: hold.WalletAddress,
+			ToAddress:   hold.ToAddress,
+			Amount:      captured.Decimal(hold.Currency),
+			Currency:    hold.Currency,
+			capture:     true,
+		}, nil, "")
+		if err != nil {
+			return err
+		}
+
+		hold.Captured += captured
+		if hold.Remaining() == 0 {
+			hold.Status = database.HoldCaptured
+		}
+		return tx.Holds().Update(hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, ErrHoldExpired
+	}
+	s.streams.publish(transaction)
+	return &CaptureResponse{Hold: newHoldResponse(*hold), Transaction: newTransactionResponse(transaction)}, nil
+}
+
+// VoidHold отменяет резервирование id, освобождая несписанный остаток.
+//
+// Возможные ошибки:
+// - ErrHoldNotFound
+// - ErrHoldNotActive — резервирование уже списано, отменено или истекло
+func (s *Service) VoidHold(id string) (*HoldResponse, error) {
+	var hold *database.Hold
+	err := s.runInTransaction(func(tx database.Store) error {
+		var err error
+		if hold, err = lockActiveHold(tx, id); err != nil {
+			return err
+		}
+		return releaseHold(tx, hold, database.HoldVoided)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := newHoldResponse(*hold)
+	return &response, nil
+}
+
+// ExpireHolds освобождает остатки не более limit резервирований, срок которых истёк,
+// и возвращает их число.
+//
+// Несколько экземпляров сервиса могут вызывать ExpireHolds одновременно:
+// каждое резервирование освобождает только один из них.
+func (s *Service) ExpireHolds(limit int) (int, error) {
+	expired := 0
+	err := s.runInTransaction(func(tx database.Store) error {
+		holds, err := tx.Holds().LockExpired(time.Now(), limit)
+		if err != nil {
+			return err
+		}
+		// Кошельки блокируются в порядке возрастания адресов, как в lockWallets
+		sort.Slice(holds, func(i, j int) bool { return holds[i].WalletAddress < holds[j].WalletAddress })
+		for i := range holds {
+			if err := releaseHold(tx, &holds[i], database.HoldExpired); err != nil {
+				return err
+			}
+		}
+		expired = len(holds)
+		return nil
+	})
+	return expired, err
+}
+
+// lockActiveHold загружает активное резервирование id с блокировкой
+// до конца транзакции tx.
+func lockActiveHold(tx database.Store, id string) (*database.Hold, error) {
+	hold, err := tx.Holds().LockByUUID(id)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status != database.HoldActive {
+		return nil, ErrHoldNotActive
+	}
+	return hold, nil
+}
+
+// releaseHold освобождает несписанный остаток заблокированного резервирования hold
+// и переводит его в состояние status.
+func releaseHold(tx database.Store, hold *database.Hold, status string) error {
+	// Кошелёк с активным резервированием открыт: закрыть можно только
+	// кошелёк с нулевым балансом, а баланс не меньше зарезервированной суммы
+	wallet, err := tx.Wallets().LockByAddress(hold.WalletAddress)
+	if err != nil {
+		return err
+	}
+	if err := tx.Wallets().AddHeld(wallet.ID, -hold.Remaining()); err != nil {
+		return err
+	}
+	hold.Status = status
+	return tx.Holds().Update(hold)
+}
+
+// newHoldResponse преобразует модель резервирования в ответ API.
+func newHoldResponse(h database.Hold) HoldResponse {
+	return HoldResponse{
+		ID:        h.UUID,
+		Wallet:    h.WalletAddress,
+		To:        h.ToAddress,
+		Amount:    h.Amount.Decimal(h.Currency),
+		Captured:  h.Captured.Decimal(h.Currency),
+		Remaining: h.Remaining().Decimal(h.Currency),
+		Currency:  h.Currency,
+		Status:    h.Status,
+		ExpiresAt: h.ExpiresAt,
+		CreatedAt: h.CreatedAt,
+		UpdatedAt: h.UpdatedAt,
+	}
+}
@@ -0,0 +1,132 @@
+package business
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// TestHolds проверяет резервирование, списание, отмену и истечение резервирований
+// в хранилище в памяти и в базах данных PostgreSQL и SQLite.
+func TestHolds(t *testing.T) {
+	forEachStore(t, testHolds)
+}
+
+// testHolds выполняет проверки TestHolds над сервисом s:
+//   - резервирование уменьшает доступный баланс, но не учётный,
+//     и зарезервированные средства недоступны для переводов;
+//   - списание частями переводит средства получателю, пока остаток не исчерпан;
+//   - отмена и истечение срока освобождают несписанный остаток;
+//   - балансы кошельков остаются согласованы с главной книгой.
+func testHolds(t *testing.T, s *Service) {
+	createTestWallets(t, s, 10000, "hold-a")
+	createTestWallets(t, s, 0, "hold-b")
+
+	checkBalance := func(address string, ledger, available money.Decimal) {
+		t.Helper()
+		balance, err := s.GetWalletBalance(address)
+		if err != nil {
+			t.Fatalf("Не удалось получить баланс: %v", err)
+		}
+		if balance.Balance != ledger || balance.Available != available {
+			t.Errorf("Кошелек %s: баланс %s, доступно %s, ожидалось %s и %s",
+				address, balance.Balance, balance.Available, ledger, available)
+		}
+	}
+
+	if _, err := s.CreateHold(HoldRequest{WalletAddress: "hold-a", ToAddress: "hold-b", Amount: "1", TTL: MaxHoldTTL + time.Hour}); !errors.Is(err, ErrInvalidHoldTTL) {
+		t.Errorf("Ожидалась ошибка ErrInvalidHoldTTL, получена %v", err)
+	}
+	hold, err := s.CreateHold(HoldRequest{WalletAddress: "hold-a", ToAddress: "hold-b", Amount: "60"})
+	if err != nil {
+		t.Fatalf("Не удалось зарезервировать средства: %v", err)
+	}
+	if hold.Status != database.HoldActive || hold.Remaining != "60.00" {
+		t.Errorf("Неверное резервирование: %+v", hold)
+	}
+	checkBalance("hold-a", "100.00", "40.00")
+
+	if _, err := s.SendMoney(TransferRequest{FromAddress: "hold-a", ToAddress: "hold-b", Amount: "50"}); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("Перевод зарезервированных средств: ожидалась ошибка ErrInsufficientFunds, получена %v", err)
+	}
+	if _, err := s.CreateHold(HoldRequest{WalletAddress: "hold-a", ToAddress: "hold-b", Amount: "50"}); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("Повторное резервирование: ожидалась ошибка ErrInsufficientFunds, получена %v", err)
+	}
+
+	// Частичное списание
+	partial := money.Decimal("20")
+	captured, err := s.CaptureHold(hold.ID, &partial)
+	if err != nil {
+		t.Fatalf("Не удалось списать средства: %v", err)
+	}
+	if captured.Hold.Status != database.HoldActive || captured.Hold.Remaining != "40.00" || captured.Transaction.Amount != "20.00" {
+		t.Errorf("Неверный результат списания: %+v", captured)
+	}
+	checkBalance("hold-a", "80.00", "40.00")
+	checkBalance("hold-b", "20.00", "20.00")
+
+	tooMuch := money.Decimal("40.01")
+	if _, err := s.CaptureHold(hold.ID, &tooMuch); !errors.Is(err, ErrCaptureExceedsHold) {
+		t.Errorf("Ожидалась ошибка ErrCaptureExceedsHold, получена %v", err)
+	}
+
+	voided, err := s.VoidHold(hold.ID)
+	if err != nil || voided.Status != database.HoldVoided || voided.Captured != "20.00" {
+		t.Fatalf("Неверный результат отмены: %+v, %v", voided, err)
+	}
+	checkBalance("hold-a", "80.00", "80.00")
+	if _, err := s.VoidHold(hold.ID); !errors.Is(err, ErrHoldNotActive) {
+		t.Errorf("Ожидалась ошибка ErrHoldNotActive, получена %v", err)
+	}
+	if _, err := s.CaptureHold(hold.ID, nil); !errors.Is(err, ErrHoldNotActive) {
+		t.Errorf("Ожидалась ошибка ErrHoldNotActive, получена %v", err)
+	}
+	if _, err := s.GetHold("unknown"); !errors.Is(err, ErrHoldNotFound) {
+		t.Errorf("Ожидалась ошибка ErrHoldNotFound, получена %v", err)
+	}
+
+	// Полное списание
+	full, err := s.CreateHold(HoldRequest{WalletAddress: "hold-a", ToAddress: "hold-b", Amount: "30"})
+	if err != nil {
+		t.Fatalf("Не удалось зарезервировать средства: %v", err)
+	}
+	if captured, err := s.CaptureHold(full.ID, nil); err != nil || captured.Hold.Status != database.HoldCaptured {
+		t.Fatalf("Неверный результат полного списания: %+v, %v", captured, err)
+	}
+	checkBalance("hold-a", "50.00", "50.00")
+
+	// Истечение срока: при списании и фоновой задачей
+	first, err := s.CreateHold(HoldRequest{WalletAddress: "hold-a", ToAddress: "hold-b", Amount: "10", TTL: time.Second})
+	if err != nil {
+		t.Fatalf("Не удалось зарезервировать средства: %v", err)
+	}
+	second, err := s.CreateHold(HoldRequest{WalletAddress: "hold-a", ToAddress: "hold-b", Amount: "15", TTL: time.Second})
+	if err != nil {
+		t.Fatalf("Не удалось зарезервировать средства: %v", err)
+	}
+	checkBalance("hold-a", "50.00", "25.00")
+	if expired, err := s.ExpireHolds(10); err != nil || expired != 0 {
+		t.Errorf("До истечения срока: освобождено %d, ошибка %v", expired, err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if _, err := s.CaptureHold(first.ID, nil); !errors.Is(err, ErrHoldExpired) {
+		t.Errorf("Ожидалась ошибка ErrHoldExpired, получена %v", err)
+	}
+	if expired, err := s.ExpireHolds(10); err != nil || expired != 1 {
+		t.Errorf("После истечения срока: освобождено %d, ошибка %v, ожидалось 1", expired, err)
+	}
+	for _, id := range []string{first.ID, second.ID} {
+		if got, err := s.GetHold(id); err != nil || got.Status != database.HoldExpired {
+			t.Errorf("Резервирование %s: %+v, %v, ожидалось состояние expired", id, got, err)
+		}
+	}
+	checkBalance("hold-a", "50.00", "50.00")
+
+	report, err := s.VerifyLedger()
+	if err != nil || !report.Balanced {
+		t.Errorf("Главная книга не сбалансирована: %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,82 @@
+package business
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"payment_system_api/database"
+)
+
+// SendMoneyIdempotent выполняет перевод средств с ключом идемпотентности key.
+//
+// Если транзакция с таким ключом уже была выполнена с тем же телом запроса,
+// перевод повторно не выполняется и возвращается исходная транзакция,
+// при этом replayed равен true.
+// Если ключ уже использован с другим телом запроса — ErrIdempotencyKeyConflict.
+// Остальные ошибки совпадают с SendMoney. Неуспешные переводы не сохраняются,
+// поэтому повтор такого запроса выполняется заново.
+func (s *Service) SendMoneyIdempotent(key string, req TransferRequest) (response *TransactionResponse, replayed bool, err error) {
+	hash := requestHash(req)
+
+	response, replayed, err = s.sendMoneyIdempotent(key, hash, req)
+	if errors.Is(err, database.ErrDuplicate) {
+		// Параллельный запрос с тем же ключом успел записать транзакцию первым,
+		// повторная попытка вернёт его результат.
+		response, replayed, err = s.sendMoneyIdempotent(key, hash, req)
+	}
+	return response, replayed, err
+}
+
+// sendMoneyIdempotent выполняет одну попытку идемпотентного перевода.
+func (s *Service) sendMoneyIdempotent(key, hash string, req TransferRequest) (*TransactionResponse, bool, error) {
+	var transaction database.Transaction
+	replayed := false
+	err := s.runInTransaction(func(tx database.Store) error {
+		existing, err := tx.Transactions().FindByIdempotencyKey(key)
+		switch {
+		case err == nil && existing.Timestamp.After(time.Now().Add(-s.options.IdempotencyKeyRetention)):
+			if existing.RequestHash != hash {
+				return ErrIdempotencyKeyConflict
+			}
+			transaction = *existing
+			replayed = true
+			return nil
+		case err == nil:
+			// Ключ истёк, но ещё не был очищен — освобождаем его.
+			if err := tx.Transactions().ReleaseIdempotencyKey(existing.ID); err != nil {
+				return err
+			}
+		case !errors.Is(err, database.ErrNotFound):
+			return err
+		}
+
+		transaction, err = s.transfer(tx, req, &key, hash)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if !replayed {
+		s.streams.publish(transaction)
+	}
+	response := newTransactionResponse(transaction)
+	return &response, replayed, nil
+}
+
+// PurgeExpiredIdempotencyKeys освобождает ключи идемпотентности,
+// срок хранения которых истёк. Сами транзакции не удаляются.
+//
+// Возвращает количество освобождённых ключей.
+func (s *Service) PurgeExpiredIdempotencyKeys() (int64, error) {
+	return s.store.Transactions().ReleaseIdempotencyKeys(time.Now().Add(-s.options.IdempotencyKeyRetention))
+}
+
+// requestHash вычисляет хеш параметров перевода для сравнения повторных запросов.
+func requestHash(req TransferRequest) string {
+	fields := []string{req.FromAddress, req.ToAddress, req.Amount.Canonical(), string(req.Currency), string(req.ToCurrency)}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,53 @@
+package business
+
+import "testing"
+
+// TestRequestHash проверяет, что хеш запроса детерминирован
+// и меняется при изменении любого из параметров перевода.
+func TestRequestHash(t *testing.T) {
+	base := requestHash(TransferRequest{FromAddress: "from", ToAddress: "to", Amount: "12.5"})
+	same := requestHash(TransferRequest{FromAddress: "from", ToAddress: "to", Amount: "012.50"})
+	if base != same {
+		t.Errorf("Хеш одинаковых запросов не совпадает")
+	}
+
+	variants := map[string]string{
+		"другой отправитель": requestHash(TransferRequest{FromAddress: "from2", ToAddress: "to", Amount: "12.5"}),
+		"другой получатель":  requestHash(TransferRequest{FromAddress: "from", ToAddress: "to2", Amount: "12.5"}),
+		"другая сумма":       requestHash(TransferRequest{FromAddress: "from", ToAddress: "to", Amount: "12.51"}),
+		"другая валюта":      requestHash(TransferRequest{FromAddress: "from", ToAddress: "to", Amount: "12.5", Currency: "USD"}),
+		"сдвиг границы":      requestHash(TransferRequest{FromAddress: "fromt", ToAddress: "o", Amount: "12.5"}),
+	}
+	for name, hash := range variants {
+		if hash == base {
+			t.Errorf("%s: хеш не изменился", name)
+		}
+	}
+}
+
+// TestSendMoneyIdempotent проверяет повтор перевода с тем же ключом идемпотентности
+// и конфликт при повторе ключа с другим телом запроса.
+func TestSendMoneyIdempotent(t *testing.T) {
+	s := newTestService()
+	createTestWallets(t, s, 10000, "idempotent-a", "idempotent-b")
+	req := TransferRequest{FromAddress: "idempotent-a", ToAddress: "idempotent-b", Amount: "10"}
+
+	first, replayed, err := s.SendMoneyIdempotent("key-1", req)
+	if err != nil || replayed {
+		t.Fatalf("Первый запрос: ожидался новый перевод, получено replayed=%v, %v", replayed, err)
+	}
+	second, replayed, err := s.SendMoneyIdempotent("key-1", req)
+	if err != nil || !replayed || second.UUID != first.UUID {
+		t.Errorf("Повтор: ожидалась исходная транзакция %s, получено %+v, replayed=%v, %v", first.UUID, second, replayed, err)
+	}
+
+	req.Amount = "11"
+	if _, _, err := s.SendMoneyIdempotent("key-1", req); err != ErrIdempotencyKeyConflict {
+		t.Errorf("Ожидалась ошибка ErrIdempotencyKeyConflict, получена %v", err)
+	}
+
+	balance, err := s.GetWalletBalance("idempotent-a")
+	if err != nil || balance.Balance != "90.00" {
+		t.Errorf("Перевод должен быть выполнен один раз, баланс %+v, %v", balance, err)
+	}
+}
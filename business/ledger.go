@@ -0,0 +1,182 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// errUnbalancedEntry — сумма проводок записи журнала в какой-либо валюте не равна нулю.
+// Означает ошибку в коде, формирующем проводки.
+var errUnbalancedEntry = errors.New("запись журнала не сбалансирована")
+
+// ledgerLine — строка будущей проводки: счёт и изменение его баланса.
+type ledgerLine struct {
+	account *database.Account
+	amount  money.Amount
+}
+
+// LedgerMismatch описывает расхождение баланса кошелька с суммой проводок его счёта.
+type LedgerMismatch struct {
+	Address  string         `json:"address"`  // адрес кошелька
+	Currency money.Currency `json:"currency"` // валюта кошелька
+	Balance  money.Decimal  `json:"balance"`  // баланс, сохранённый в кошельке
+	Ledger   money.Decimal  `json:"ledger"`   // баланс по проводкам главной книги
+}
+
+// LedgerReport — результат сверки главной книги.
+type LedgerReport struct {
+	Balanced           bool             `json:"balanced"`             // расхождений не найдено
+	Mismatches         []LedgerMismatch `json:"mismatches"`           // кошельки, баланс которых не совпадает с проводками
+	UnbalancedEntryIDs []uint           `json:"unbalanced_entry_ids"` // записи журнала с ненулевой суммой проводок
+}
+
+// postEntry записывает запись журнала с проводками lines и применяет их
+// к балансам кошельков. Строки кошельков должны быть заблокированы вызывающим.
+func postEntry(tx database.Store, entry *database.JournalEntry, lines []ledgerLine) error {
+	if err := recordEntry(tx, entry, lines); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if line.account.WalletID == nil {
+			continue
+		}
+		if err := tx.Wallets().AddBalance(*line.account.WalletID, line.amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordEntry проверяет, что проводки lines сбалансированы в каждой валюте,
+// и записывает их вместе с записью журнала entry, не изменяя балансы кошельков.
+func recordEntry(tx database.Store, entry *database.JournalEntry, lines []ledgerLine) error {
+	if !balanced(lines) {
+		return errUnbalancedEntry
+	}
+	postings := make([]database.Posting, 0, len(lines))
+	for _, line := range lines {
+		postings = append(postings, database.Posting{
+			AccountID: line.account.ID,
+			Amount:    line.amount,
+			Currency:  line.account.Currency,
+		})
+	}
+	return tx.Ledger().CreateEntry(entry, postings)
+}
+
+// balanced сообщает, равна ли нулю сумма проводок в каждой валюте.
+func balanced(lines []ledgerLine) bool {
+	sums := make(map[money.Currency]money.Amount)
+	for _, line := range lines {
+		sums[line.account.Currency] += line.amount
+	}
+	for _, sum := range sums {
+		if sum != 0 {
+			return false
+		}
+	}
+	return len(lines) > 0
+}
+
+// walletAccount возвращает счёт кошелька wallet, открывая его при необходимости.
+//
+// При открытии счёта текущий баланс кошелька записывается начальной проводкой
+// со счёта эмиссии, поэтому баланс по проводкам сразу совпадает с балансом кошелька.
+// Строка кошелька должна быть заблокирована вызывающим.
+func walletAccount(tx database.Store, wallet *database.Wallet) (*database.Account, error) {
+	existing, err := tx.Ledger().FindWalletAccount(wallet.ID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+
+	account := database.Account{
+		Code:     "wallet:" + wallet.Address,
+		Kind:     database.AccountKindWallet,
+		Currency: wallet.Currency,
+		WalletID: &wallet.ID,
+	}
+	if err := tx.Ledger().CreateAccount(&account); err != nil {
+		return nil, err
+	}
+	if wallet.Balance == 0 {
+		return &account, nil
+	}
+
+	issuance, err := tx.Ledger().SystemAccount(database.AccountKindIssuance, wallet.Currency)
+	if err != nil {
+		return nil, err
+	}
+	entry := database.JournalEntry{Description: "начальный баланс кошелька " + wallet.Address}
+	lines := []ledgerLine{
+		{account: issuance, amount: -wallet.Balance},
+		{account: &account, amount: wallet.Balance},
+	}
+	if err := recordEntry(tx, &entry, lines); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// BackfillLedger открывает счета главной книги для кошельков, у которых их ещё нет,
+// записывая их текущие балансы начальными проводками.
+//
+// Используется при запуске, чтобы перенести в главную книгу кошельки,
+// созданные до её появления.
+func (s *Service) BackfillLedger() error {
+	wallets, err := s.store.Ledger().WalletsWithoutAccount()
+	if err != nil {
+		return err
+	}
+	for _, wallet := range wallets {
+		err := s.runInTransaction(func(tx database.Store) error {
+			locked, err := lockWallet(tx, wallet.Address, ErrWalletNotFound)
+			if err != nil {
+				return err
+			}
+			_, err = walletAccount(tx, locked)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("не удалось открыть счёт кошелька %s: %w", wallet.Address, err)
+		}
+	}
+	return nil
+}
+
+// VerifyLedger сверяет главную книгу.
+//
+// Проверяет, что баланс каждого кошелька совпадает с суммой проводок его счёта
+// и что сумма проводок каждой записи журнала в каждой валюте равна нулю.
+func (s *Service) VerifyLedger() (*LedgerReport, error) {
+	report := &LedgerReport{Mismatches: []LedgerMismatch{}}
+
+	rows, err := s.store.Ledger().BalanceMismatches()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		report.Mismatches = append(report.Mismatches, LedgerMismatch{
+			Address:  row.Address,
+			Currency: row.Currency,
+			Balance:  row.Balance.Decimal(row.Currency),
+			Ledger:   row.Ledger.Decimal(row.Currency),
+		})
+	}
+
+	report.UnbalancedEntryIDs, err = s.store.Ledger().UnbalancedEntryIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	if report.UnbalancedEntryIDs == nil {
+		report.UnbalancedEntryIDs = []uint{}
+	}
+	report.Balanced = len(report.Mismatches) == 0 && len(report.UnbalancedEntryIDs) == 0
+	return report, nil
+}
@@ -0,0 +1,33 @@
+package business
+
+import (
+	"testing"
+
+	"payment_system_api/database"
+)
+
+// TestBalanced проверяет, что запись журнала считается сбалансированной,
+// только если сумма проводок равна нулю в каждой валюте по отдельности.
+func TestBalanced(t *testing.T) {
+	rub1 := &database.Account{ID: 1, Currency: "RUB"}
+	rub2 := &database.Account{ID: 2, Currency: "RUB"}
+	usd1 := &database.Account{ID: 3, Currency: "USD"}
+	usd2 := &database.Account{ID: 4, Currency: "USD"}
+
+	cases := []struct {
+		name  string
+		lines []ledgerLine
+		want  bool
+	}{
+		{"перевод в одной валюте", []ledgerLine{{rub1, -500}, {rub2, 500}}, true},
+		{"перевод с конвертацией", []ledgerLine{{usd1, -100}, {rub1, 9250}, {usd2, 100}, {rub2, -9250}}, true},
+		{"несбалансированный перевод", []ledgerLine{{rub1, -500}, {rub2, 499}}, false},
+		{"баланс только в сумме валют", []ledgerLine{{usd1, -100}, {rub1, 100}}, false},
+		{"пустая запись", nil, false},
+	}
+	for _, tc := range cases {
+		if got := balanced(tc.lines); got != tc.want {
+			t.Errorf("%s: ожидалось %v, получено %v", tc.name, tc.want, got)
+		}
+	}
+}
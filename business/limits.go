@@ -0,0 +1,249 @@
+package business
+
+import (
+	"errors"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// WalletLimitsRequest содержит лимиты расходов кошелька; nil — лимит не установлен.
+//
+// Суммы задаются в валюте кошелька.
+type WalletLimitsRequest struct {
+	MaxTransfer  *money.Decimal // максимальная сумма одного перевода
+	DailyTotal   *money.Decimal // максимальная сумма переводов за календарные сутки UTC
+	MonthlyTotal *money.Decimal // максимальная сумма переводов за календарный месяц UTC
+	HourlyCount  *int           // максимальное число переводов за последний час
+}
+
+// WalletLimitsResponse представляет лимиты расходов кошелька и их текущее использование.
+//
+// Перевод нарушает суточный лимит, если DailySpent + DailyHeld + сумма перевода
+// больше DailyTotal; месячный — так же по MonthlySpent и MonthlyHeld.
+type WalletLimitsResponse struct {
+	Address      string         `json:"address"`       // адрес кошелька
+	Currency     money.Currency `json:"currency"`      // валюта кошелька и лимитов
+	MaxTransfer  *money.Decimal `json:"max_transfer"`  // максимальная сумма одного перевода, null — без лимита
+	DailyTotal   *money.Decimal `json:"daily_total"`   // лимит расходов за сутки UTC, null — без лимита
+	MonthlyTotal *money.Decimal `json:"monthly_total"` // лимит расходов за месяц UTC, null — без лимита
+	HourlyCount  *int           `json:"hourly_count"`  // лимит числа переводов за час, null — без лимита
+
+	DailySpent      money.Decimal `json:"daily_spent"`      // сумма переводов за текущие сутки UTC
+	DailyHeld       money.Decimal `json:"daily_held"`       // несписанный остаток резервирований, созданных за текущие сутки UTC
+	MonthlySpent    money.Decimal `json:"monthly_spent"`    // сумма переводов за текущий месяц UTC
+	MonthlyHeld     money.Decimal `json:"monthly_held"`     // несписанный остаток резервирований, созданных за текущий месяц UTC
+	HourlyTransfers int           `json:"hourly_transfers"` // число переводов за последний час
+}
+
+// GetWalletLimits возвращает лимиты расходов кошелька address и их использование.
+//
+// Возвращает ErrWalletNotFound, если кошелёк не найден.
+func (s *Service) GetWalletLimits(address string) (*WalletLimitsResponse, error) {
+	wallet, err := s.store.Wallets().FindByAddress(address)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	limit, err := s.store.Limits().Find(address)
+	if errors.Is(err, database.ErrNotFound) {
+		limit = &database.WalletLimit{WalletAddress: address}
+	} else if err != nil {
+		return nil, err
+	}
+	return newWalletLimitsResponse(s.store, wallet, limit)
+}
+
+// SetWalletLimits заменяет лимиты расходов кошелька address на req.
+//
+// Лимиты проверяются при каждом переводе с кошелька в той же транзакции,
+// что и сам перевод, в том числе для пакетов, запланированных и регулярных
+// переводов. Резервирование проверяется как перевод при создании (CreateHold),
+// а его списания (CaptureHold) лимитами не ограничиваются: несписанный остаток
+// активного резервирования входит в суточную и месячную суммы расходов того
+// периода, в котором резервирование создано, а списанная часть — как перевод.
+// Возвраты не ограничиваются лимитами и не учитываются в суммах расходов.
+// Возможные ошибки:
+// - ErrWalletNotFound
+// - ErrInvalidLimit — значение лимита не положительно
+// - ErrAmountPrecision — у суммы больше знаков, чем допускает валюта кошелька
+func (s *Service) SetWalletLimits(address string, req WalletLimitsRequest) (*WalletLimitsResponse, error) {
+	var response *WalletLimitsResponse
+	err := s.runInTransaction(func(tx database.Store) error {
+		wallet, err := tx.Wallets().LockByAddress(address)
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrWalletNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		limit := database.WalletLimit{WalletAddress: address, HourlyCount: req.HourlyCount}
+		if req.HourlyCount != nil && *req.HourlyCount <= 0 {
+			return ErrInvalidLimit
+		}
+		for _, field := range []struct {
+			value  *money.Decimal
+			target **money.Amount
+		}{
+			{req.MaxTransfer, &limit.MaxTransfer},
+			{req.DailyTotal, &limit.DailyTotal},
+			{req.MonthlyTotal, &limit.MonthlyTotal},
+		} {
+			if field.value == nil {
+				continue
+			}
+			if field.value.Sign() <= 0 {
+				return ErrInvalidLimit
+			}
+			amount, err := toAmount(*field.value, wallet.Currency)
+			if err != nil {
+				return err
+			}
+			*field.target = &amount
+		}
+
+		if err := tx.Limits().Save(&limit); err != nil {
+			return err
+		}
+		response, err = newWalletLimitsResponse(tx, wallet, &limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// DeleteWalletLimits снимает все лимиты расходов кошелька address.
+//
+// Возвращает ErrWalletNotFound, если кошелёк не найден.
+func (s *Service) DeleteWalletLimits(address string) error {
+	return s.runInTransaction(func(tx database.Store) error {
+		if _, err := tx.Wallets().LockByAddress(address); errors.Is(err, database.ErrNotFound) {
+			return ErrWalletNotFound
+		} else if err != nil {
+			return err
+		}
+		return tx.Limits().Delete(address)
+	})
+}
+
+// checkLimits проверяет, что перевод суммы amount с заблокированного кошелька
+// wallet не нарушает его лимиты расходов.
+//
+// Кошелёк заблокирован до конца транзакции tx, поэтому одновременные переводы
+// с него проверяются по очереди, а переводы, ранее созданные в tx (например,
+// другие переводы пакета), учитываются в суммах. Несписанный остаток активных
+// резервирований, созданных в том же периоде, считается уже потраченным:
+// их списание лимиты не проверяют.
+func checkLimits(tx database.Store, wallet *database.Wallet, amount money.Amount) error {
+	limit, err := tx.Limits().Find(wallet.Address)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if limit.MaxTransfer != nil && amount > *limit.MaxTransfer {
+		return ErrTransferLimitExceeded
+	}
+	now := time.Now().UTC()
+	if limit.DailyTotal != nil {
+		spent, held, err := periodSpending(tx, wallet.Address, startOfDay(now))
+		if err != nil {
+			return err
+		}
+		if spent+held+amount > *limit.DailyTotal {
+			return ErrDailyLimitExceeded
+		}
+	}
+	if limit.MonthlyTotal != nil {
+		spent, held, err := periodSpending(tx, wallet.Address, startOfMonth(now))
+		if err != nil {
+			return err
+		}
+		if spent+held+amount > *limit.MonthlyTotal {
+			return ErrMonthlyLimitExceeded
+		}
+	}
+	if limit.HourlyCount != nil {
+		_, count, err := tx.Transactions().SumOutgoing(wallet.Address, now.Add(-time.Hour))
+		if err != nil {
+			return err
+		}
+		if count+1 > *limit.HourlyCount {
+			return ErrHourlyCountExceeded
+		}
+	}
+	return nil
+}
+
+// periodSpending возвращает сумму переводов с кошелька address, созданных
+// не раньше since, и несписанный остаток его активных резервирований,
+// созданных за то же время.
+func periodSpending(store database.Store, address string, since time.Time) (spent, held money.Amount, err error) {
+	if spent, _, err = store.Transactions().SumOutgoing(address, since); err != nil {
+		return 0, 0, err
+	}
+	if held, err = store.Holds().SumActive(address, since); err != nil {
+		return 0, 0, err
+	}
+	return spent, held, nil
+}
+
+// newWalletLimitsResponse преобразует лимиты limit кошелька wallet в ответ API,
+// дополняя их текущим использованием из хранилища store.
+func newWalletLimitsResponse(store database.Store, wallet *database.Wallet, limit *database.WalletLimit) (*WalletLimitsResponse, error) {
+	now := time.Now().UTC()
+	daily, dailyHeld, err := periodSpending(store, wallet.Address, startOfDay(now))
+	if err != nil {
+		return nil, err
+	}
+	monthly, monthlyHeld, err := periodSpending(store, wallet.Address, startOfMonth(now))
+	if err != nil {
+		return nil, err
+	}
+	_, hourly, err := store.Transactions().SumOutgoing(wallet.Address, now.Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &WalletLimitsResponse{
+		Address:         wallet.Address,
+		Currency:        wallet.Currency,
+		HourlyCount:     limit.HourlyCount,
+		DailySpent:      daily.Decimal(wallet.Currency),
+		DailyHeld:       dailyHeld.Decimal(wallet.Currency),
+		MonthlySpent:    monthly.Decimal(wallet.Currency),
+		MonthlyHeld:     monthlyHeld.Decimal(wallet.Currency),
+		HourlyTransfers: hourly,
+	}
+	decimal := func(amount *money.Amount) *money.Decimal {
+		if amount == nil {
+			return nil
+		}
+		d := amount.Decimal(wallet.Currency)
+		return &d
+	}
+	response.MaxTransfer = decimal(limit.MaxTransfer)
+	response.DailyTotal = decimal(limit.DailyTotal)
+	response.MonthlyTotal = decimal(limit.MonthlyTotal)
+	return response, nil
+}
+
+// startOfDay возвращает начало календарных суток UTC, в которые попадает t.
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// startOfMonth возвращает начало календарного месяца UTC, в который попадает t.
+func startOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,223 @@
+package business
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// TestWalletLimits проверяет лимиты расходов кошельков в хранилище в памяти
+// и в базах данных PostgreSQL и SQLite.
+func TestWalletLimits(t *testing.T) {
+	forEachStore(t, testWalletLimits)
+}
+
+// testWalletLimits выполняет проверки TestWalletLimits над сервисом s:
+//   - некорректные лимиты отклоняются;
+//   - каждый лимит отклоняет перевод, который его нарушает, с кодом LIMIT_EXCEEDED;
+//   - переводы пакета учитываются в суммах вместе;
+//   - возвраты не ограничиваются лимитами и не учитываются в суммах;
+//   - после снятия лимитов переводы выполняются.
+func testWalletLimits(t *testing.T, s *Service) {
+	createTestWallets(t, s, 100000, "limits-a", "limits-b")
+
+	decimal := func(value string) *money.Decimal {
+		d := money.Decimal(value)
+		return &d
+	}
+	count := func(value int) *int { return &value }
+	send := func(amount money.Decimal) (*TransactionResponse, error) {
+		return s.SendMoney(TransferRequest{FromAddress: "limits-a", ToAddress: "limits-b", Amount: amount})
+	}
+
+	if _, err := s.SetWalletLimits("limits-a", WalletLimitsRequest{DailyTotal: decimal("0")}); !errors.Is(err, ErrInvalidLimit) {
+		t.Errorf("Ожидалась ошибка ErrInvalidLimit, получена %v", err)
+	}
+	if _, err := s.SetWalletLimits("limits-a", WalletLimitsRequest{HourlyCount: count(-1)}); !errors.Is(err, ErrInvalidLimit) {
+		t.Errorf("Ожидалась ошибка ErrInvalidLimit, получена %v", err)
+	}
+	if _, err := s.SetWalletLimits("limits-unknown", WalletLimitsRequest{}); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("Ожидалась ошибка ErrWalletNotFound, получена %v", err)
+	}
+
+	// Перевод до установки лимитов учитывается в суммах
+	payment, err := send("100")
+	if err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	limits, err := s.SetWalletLimits("limits-a", WalletLimitsRequest{
+		MaxTransfer:  decimal("200"),
+		DailyTotal:   decimal("400"),
+		MonthlyTotal: decimal("1000"),
+		HourlyCount:  count(4),
+	})
+	if err != nil {
+		t.Fatalf("Не удалось установить лимиты: %v", err)
+	}
+	if limits.MaxTransfer == nil || *limits.MaxTransfer != "200.00" || limits.DailySpent != "100.00" || limits.HourlyTransfers != 1 {
+		t.Errorf("Неверные лимиты: %+v", limits)
+	}
+
+	if _, err := send("200.01"); !errors.Is(err, ErrTransferLimitExceeded) {
+		t.Errorf("Ожидалась ошибка ErrTransferLimitExceeded, получена %v", err)
+	}
+	if _, err := send("200"); err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	if _, err := send("100.01"); !errors.Is(err, ErrDailyLimitExceeded) {
+		t.Errorf("Ожидалась ошибка ErrDailyLimitExceeded, получена %v", err)
+	}
+
+	// Переводы пакета проверяются нарастающим итогом
+	batch, err := s.SendBatch(BatchRequest{FromAddress: "limits-a", Transfers: []BatchTransfer{
+		{ToAddress: "limits-b", Amount: "60"},
+		{ToAddress: "limits-b", Amount: "60"},
+	}})
+	if !errors.Is(err, ErrBatchFailed) || batch.Lines[1].ErrorCode != "LIMIT_EXCEEDED" {
+		t.Errorf("Ожидался отказ второго перевода пакета по лимиту: %+v, %v", batch, err)
+	}
+
+	// Возврат не ограничен лимитами получателя и не уменьшает расходы отправителя
+	if _, err := s.SetWalletLimits("limits-b", WalletLimitsRequest{MaxTransfer: decimal("1")}); err != nil {
+		t.Fatalf("Не удалось установить лимиты: %v", err)
+	}
+	if _, err := s.RefundTransaction(payment.UUID, nil); err != nil {
+		t.Errorf("Не удалось выполнить возврат: %v", err)
+	}
+	if limits, err := s.GetWalletLimits("limits-b"); err != nil || limits.DailySpent != "0.00" {
+		t.Errorf("Возврат учтён в расходах: %+v, %v", limits, err)
+	}
+
+	if _, err := send("100"); err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	if _, err := s.SetWalletLimits("limits-a", WalletLimitsRequest{HourlyCount: count(3)}); err != nil {
+		t.Fatalf("Не удалось установить лимиты: %v", err)
+	}
+	if _, err := send("1"); !errors.Is(err, ErrHourlyCountExceeded) {
+		t.Errorf("Ожидалась ошибка ErrHourlyCountExceeded, получена %v", err)
+	}
+	if _, err := s.SetWalletLimits("limits-a", WalletLimitsRequest{MonthlyTotal: decimal("450")}); err != nil {
+		t.Fatalf("Не удалось установить лимиты: %v", err)
+	}
+	if _, err := send("50.01"); !errors.Is(err, ErrMonthlyLimitExceeded) {
+		t.Errorf("Ожидалась ошибка ErrMonthlyLimitExceeded, получена %v", err)
+	}
+
+	if err := s.DeleteWalletLimits("limits-a"); err != nil {
+		t.Fatalf("Не удалось снять лимиты: %v", err)
+	}
+	if _, err := send("500"); err != nil {
+		t.Errorf("Перевод после снятия лимитов отклонён: %v", err)
+	}
+	limits, err = s.GetWalletLimits("limits-a")
+	if err != nil || limits.MaxTransfer != nil || limits.HourlyCount != nil || limits.DailySpent != "900.00" {
+		t.Errorf("Неверные лимиты после снятия: %+v, %v", limits, err)
+	}
+
+	report, err := s.VerifyLedger()
+	if err != nil || !report.Balanced {
+		t.Errorf("Главная книга не сбалансирована: %+v, %v", report, err)
+	}
+}
+
+// TestHoldLimits проверяет лимиты расходов для резервирований в хранилище
+// в памяти и в базах данных PostgreSQL и SQLite.
+func TestHoldLimits(t *testing.T) {
+	forEachStore(t, testHoldLimits)
+}
+
+// testHoldLimits выполняет проверки TestHoldLimits над сервисом s:
+//   - резервирование проверяется по лимитам при создании;
+//   - несписанный остаток резервирования учитывается в суммах расходов периода,
+//     в котором резервирование создано, и возвращается в использовании лимитов;
+//   - списание резервирования не ограничивается лимитами;
+//   - отмена резервирования освобождает учтённую сумму.
+func testHoldLimits(t *testing.T, s *Service) {
+	createTestWallets(t, s, 100000, "hold-limits-a", "hold-limits-b")
+
+	decimal := func(value string) *money.Decimal {
+		d := money.Decimal(value)
+		return &d
+	}
+	hold := func(amount money.Decimal) (*HoldResponse, error) {
+		return s.CreateHold(HoldRequest{WalletAddress: "hold-limits-a", ToAddress: "hold-limits-b", Amount: amount})
+	}
+	send := func(amount money.Decimal) (*TransactionResponse, error) {
+		return s.SendMoney(TransferRequest{FromAddress: "hold-limits-a", ToAddress: "hold-limits-b", Amount: amount})
+	}
+
+	// Резервирование, созданное до текущего месяца, не занимает лимиты текущих суток и месяца
+	wallet, err := s.store.Wallets().FindByAddress("hold-limits-a")
+	if err != nil {
+		t.Fatalf("Не удалось получить кошелек: %v", err)
+	}
+	previous := database.Hold{
+		WalletAddress: "hold-limits-a",
+		ToAddress:     "hold-limits-b",
+		Amount:        50000,
+		Currency:      wallet.Currency,
+		Status:        database.HoldActive,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		CreatedAt:     startOfMonth(time.Now()).Add(-time.Hour),
+	}
+	if err := s.store.Holds().Create(&previous); err != nil {
+		t.Fatalf("Не удалось создать резервирование: %v", err)
+	}
+	if err := s.store.Wallets().AddHeld(wallet.ID, previous.Amount); err != nil {
+		t.Fatalf("Не удалось зарезервировать средства: %v", err)
+	}
+
+	if _, err := s.SetWalletLimits("hold-limits-a", WalletLimitsRequest{MaxTransfer: decimal("300"), DailyTotal: decimal("400"), MonthlyTotal: decimal("400")}); err != nil {
+		t.Fatalf("Не удалось установить лимиты: %v", err)
+	}
+	if _, err := hold("300.01"); !errors.Is(err, ErrTransferLimitExceeded) {
+		t.Errorf("Ожидалась ошибка ErrTransferLimitExceeded, получена %v", err)
+	}
+	held, err := hold("300")
+	if err != nil {
+		t.Fatalf("Не удалось зарезервировать средства: %v", err)
+	}
+
+	// Зарезервированная сумма уже занимает суточный лимит и видна в использовании
+	limits, err := s.GetWalletLimits("hold-limits-a")
+	if err != nil || limits.DailySpent != "0.00" || limits.DailyHeld != "300.00" || limits.MonthlyHeld != "300.00" {
+		t.Errorf("Неверное использование лимитов: %+v, %v", limits, err)
+	}
+	if _, err := send("100.01"); !errors.Is(err, ErrDailyLimitExceeded) {
+		t.Errorf("Перевод: ожидалась ошибка ErrDailyLimitExceeded, получена %v", err)
+	}
+	if _, err := hold("100.01"); !errors.Is(err, ErrDailyLimitExceeded) {
+		t.Errorf("Резервирование: ожидалась ошибка ErrDailyLimitExceeded, получена %v", err)
+	}
+	second, err := hold("100")
+	if err != nil {
+		t.Fatalf("Не удалось зарезервировать средства: %v", err)
+	}
+	if _, err := s.VoidHold(second.ID); err != nil {
+		t.Fatalf("Не удалось отменить резервирование: %v", err)
+	}
+
+	// Списание проходит, даже если лимиты снижены после резервирования
+	if _, err := s.SetWalletLimits("hold-limits-a", WalletLimitsRequest{MaxTransfer: decimal("10"), DailyTotal: decimal("10"), MonthlyTotal: decimal("10")}); err != nil {
+		t.Fatalf("Не удалось установить лимиты: %v", err)
+	}
+	if _, err := s.CaptureHold(held.ID, nil); err != nil {
+		t.Fatalf("Списание резервирования отклонено: %v", err)
+	}
+	limits, err = s.GetWalletLimits("hold-limits-a")
+	if err != nil || limits.DailySpent != "300.00" || limits.DailyHeld != "0.00" {
+		t.Errorf("Списание не учтено в расходах: %+v, %v", limits, err)
+	}
+	if _, err := send("1"); !errors.Is(err, ErrDailyLimitExceeded) {
+		t.Errorf("Ожидалась ошибка ErrDailyLimitExceeded, получена %v", err)
+	}
+
+	report, err := s.VerifyLedger()
+	if err != nil || !report.Balanced {
+		t.Errorf("Главная книга не сбалансирована: %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,176 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"payment_system_api/database"
+	"payment_system_api/fx"
+	"payment_system_api/money"
+)
+
+// RefundTransaction возвращает отправителю транзакции id сумму amount
+// переводом в обратном направлении; nil — весь невозвращённый остаток.
+//
+// Транзакцию можно возвращать частями, пока сумма возвратов не достигнет
+// её суммы. amount задаётся в валюте перевода, то есть в валюте отправителя.
+// Возврат перевода с конвертацией выполняется по курсу исходного перевода:
+// с получателя списывается доля суммы зачисления, равная доле возвращаемой
+// суммы, так что полный возврат списывает ровно зачисленную сумму.
+// Возврат выполняется так же, как SendMoney: с записью в главную книгу,
+// событием TransferCompleted и вебхуками, — и ссылается на исходную
+// транзакцию через поле RefundOf.
+// Возможные ошибки:
+// - ErrTransactionNotFound
+// - ErrNotRefundable — транзакция сама является возвратом
+// - ErrRefundExceedsAmount
+// - ErrInvalidAmount, ErrAmountPrecision
+// - ErrRefundInsufficientFunds — у получателя недостаточно доступных средств
+// - ErrSenderNotFound, ErrRecipientNotFound — кошелёк отправителя
+// или получателя исходного перевода закрыт
+// - ErrSenderFrozen, ErrRecipientFrozen — кошелёк отправителя или получателя
+// возврата заморожен
+func (s *Service) RefundTransaction(id string, amount *money.Decimal) (*TransactionResponse, error) {
+	var refund database.Transaction
+	err := s.runInTransaction(func(tx database.Store) error {
+		// Исходная транзакция блокируется первой, поэтому одновременные
+		// возвраты одной транзакции выполняются по очереди
+		original, err := tx.Transactions().LockByUUID(id)
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrTransactionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if original.RefundOf != nil {
+			return ErrNotRefundable
+		}
+
+		remaining := original.Amount - original.Refunded
+		refunded := remaining
+		if amount != nil {
+			if amount.Sign() <= 0 {
+				return ErrInvalidAmount
+			}
+			if refunded, err = toAmount(*amount, original.Currency); err != nil {
+				return err
+			}
+		}
+		if refunded == 0 || refunded > remaining {
+			return ErrRefundExceedsAmount
+		}
+		debited, err := refundDebit(original, refunded)
+		if err != nil {
+			return err
+		}
+
+		// Отправитель возврата — получатель исходного перевода,
+		// ошибки поиска кошельков относятся к их ролям в исходном переводе
+		fromWallet, toWallet, err := lockWallets(tx, original.ToAddress, original.FromAddress)
+		switch {
+		case errors.Is(err, ErrSenderNotFound):
+			return ErrRecipientNotFound
+		case errors.Is(err, ErrRecipientNotFound):
+			return ErrSenderNotFound
+		case err != nil:
+			return err
+		}
+		if err := checkWalletStatus(fromWallet, toWallet); err != nil {
+			return err
+		}
+		if fromWallet.Balance-fromWallet.Held < debited {
+			return ErrRefundInsufficientFunds
+		}
+
+		refund = database.Transaction{
+			FromAddress: original.ToAddress,
+			ToAddress:   original.FromAddress,
+			Amount:      debited,
+			Currency:    original.ToCurrency,
+			ToAmount:    refunded,
+			ToCurrency:  original.Currency,
+			RefundOf:    &original.UUID,
+		}
+		if original.FXRate != "" {
+			if refund.FXRate, err = inverseRate(original); err != nil {
+				return err
+			}
+			refund.FXQuotedAt = original.FXQuotedAt
+		}
+
+		if err := commitTransfer(tx, &refund, fromWallet, toWallet); err != nil {
+			return err
+		}
+		return tx.Transactions().AddRefunded(original.ID, refunded)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.streams.publish(refund)
+	response := newTransactionResponse(refund)
+	return &response, nil
+}
+
+// refundDebit возвращает сумму списания с получателя transaction
+// при возврате отправителю суммы refunded.
+//
+// Сумма списания — доля суммы зачисления, пропорциональная доле возвращаемой
+// суммы. Доли считаются нарастающим итогом с учётом прежних возвратов,
+// поэтому ошибки округления не накапливаются.
+func refundDebit(transaction *database.Transaction, refunded money.Amount) (money.Amount, error) {
+	if transaction.ToAmount == transaction.Amount {
+		return refunded, nil
+	}
+	// Обе суммы в минимальных единицах, поэтому конвертация выполняется
+	// в пределах одной валюты и показатели валют не учитываются
+	rate := big.NewRat(int64(transaction.ToAmount), int64(transaction.Amount))
+	before, err := money.Convert(transaction.Refunded, transaction.Currency, rate, transaction.Currency)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	after, err := money.Convert(transaction.Refunded+refunded, transaction.Currency, rate, transaction.Currency)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if after-before <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	return after - before, nil
+}
+
+// inverseRate возвращает курс обратного обмена для перевода с конвертацией
+// transaction: единиц его Currency за единицу ToCurrency.
+func inverseRate(transaction *database.Transaction) (string, error) {
+	rate, ok := new(big.Rat).SetString(transaction.FXRate)
+	if !ok || rate.Sign() <= 0 {
+		return "", fmt.Errorf("некорректный курс транзакции %s: %q", transaction.UUID, transaction.FXRate)
+	}
+	quote := fx.Quote{From: transaction.ToCurrency, To: transaction.Currency, Rate: rate.Inv(rate)}
+	return quote.RateString(), nil
+}
+
+// findRefunds возвращает UUID возвратов транзакций transactions,
+// сгруппированные по UUID исходной транзакции.
+// Возвраты запрашиваются только для транзакций, у которых они есть.
+func (s *Service) findRefunds(transactions []database.Transaction) (map[string][]string, error) {
+	var uuids []string
+	for _, t := range transactions {
+		if t.Refunded > 0 {
+			uuids = append(uuids, t.UUID)
+		}
+	}
+	if len(uuids) == 0 {
+		return nil, nil
+	}
+
+	refunds, err := s.store.Transactions().FindRefunds(uuids)
+	if err != nil {
+		return nil, err
+	}
+	byOriginal := make(map[string][]string, len(uuids))
+	for _, refund := range refunds {
+		byOriginal[*refund.RefundOf] = append(byOriginal[*refund.RefundOf], refund.UUID)
+	}
+	return byOriginal, nil
+}
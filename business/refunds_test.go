@@ -0,0 +1,177 @@
+package business
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/fx"
+	"payment_system_api/memory"
+	"payment_system_api/money"
+)
+
+// TestRefunds проверяет полные и частичные возвраты транзакций
+// в хранилище в памяти и в базах данных PostgreSQL и SQLite.
+func TestRefunds(t *testing.T) {
+	forEachStore(t, testRefunds)
+}
+
+// testRefunds выполняет проверки TestRefunds над сервисом s:
+//   - возврат переводит средства обратно и ссылается на исходную транзакцию;
+//   - сумма возвратов не превышает сумму транзакции, возврат нельзя вернуть;
+//   - если у получателя недостаточно средств, возврат не меняет балансы;
+//   - списки транзакций показывают сумму и UUID возвратов;
+//   - балансы кошельков остаются согласованы с главной книгой.
+func testRefunds(t *testing.T, s *Service) {
+	createTestWallets(t, s, 10000, "refund-a")
+	createTestWallets(t, s, 0, "refund-b", "refund-c")
+
+	checkBalances := func(want ...money.Amount) {
+		t.Helper()
+		got := balances(t, s)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("Балансы %v, ожидалось %v", got, want)
+				return
+			}
+		}
+	}
+
+	payment, err := s.SendMoney(TransferRequest{FromAddress: "refund-a", ToAddress: "refund-b", Amount: "30"})
+	if err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+
+	partial := money.Decimal("10")
+	first, err := s.RefundTransaction(payment.UUID, &partial)
+	if err != nil {
+		t.Fatalf("Не удалось выполнить возврат: %v", err)
+	}
+	if first.RefundOf != payment.UUID || first.FromAddress != "refund-b" || first.ToAddress != "refund-a" || first.Amount != "10.00" {
+		t.Errorf("Неверный возврат: %+v", first)
+	}
+	checkBalances(8000, 2000, 0)
+
+	tooMuch := money.Decimal("20.01")
+	if _, err := s.RefundTransaction(payment.UUID, &tooMuch); !errors.Is(err, ErrRefundExceedsAmount) {
+		t.Errorf("Ожидалась ошибка ErrRefundExceedsAmount, получена %v", err)
+	}
+	if _, err := s.RefundTransaction(first.UUID, nil); !errors.Is(err, ErrNotRefundable) {
+		t.Errorf("Ожидалась ошибка ErrNotRefundable, получена %v", err)
+	}
+	if _, err := s.RefundTransaction("unknown", nil); !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("Ожидалась ошибка ErrTransactionNotFound, получена %v", err)
+	}
+
+	// Получатель потратил часть средств: полный остаток вернуть нельзя
+	if _, err := s.SendMoney(TransferRequest{FromAddress: "refund-b", ToAddress: "refund-c", Amount: "15"}); err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	if _, err := s.RefundTransaction(payment.UUID, nil); !errors.Is(err, ErrRefundInsufficientFunds) {
+		t.Errorf("Ожидалась ошибка ErrRefundInsufficientFunds, получена %v", err)
+	}
+	checkBalances(8000, 500, 1500)
+
+	small := money.Decimal("5")
+	second, err := s.RefundTransaction(payment.UUID, &small)
+	if err != nil {
+		t.Fatalf("Не удалось выполнить возврат: %v", err)
+	}
+	checkBalances(8500, 0, 1500)
+
+	page, err := s.GetLastTransactions(10, "")
+	if err != nil {
+		t.Fatalf("Не удалось получить транзакции: %v", err)
+	}
+	found := false
+	for _, transaction := range page.Transactions {
+		if transaction.UUID != payment.UUID {
+			continue
+		}
+		found = true
+		if transaction.Refunded != "15.00" || len(transaction.Refunds) != 2 ||
+			transaction.Refunds[0] != first.UUID || transaction.Refunds[1] != second.UUID {
+			t.Errorf("Неверные возвраты в списке транзакций: %+v", transaction)
+		}
+	}
+	if !found {
+		t.Errorf("Исходная транзакция %s не найдена в списке", payment.UUID)
+	}
+	history, err := s.GetWalletTransactions("refund-a", TransactionFilter{Direction: DirectionIn})
+	if err != nil {
+		t.Fatalf("Не удалось получить историю: %v", err)
+	}
+	if len(history.Transactions) != 2 || history.Transactions[0].RefundOf != payment.UUID {
+		t.Errorf("Неверная история входящих возвратов: %+v", history.Transactions)
+	}
+
+	// Полный возврат остатка
+	if _, err := s.SendMoney(TransferRequest{FromAddress: "refund-c", ToAddress: "refund-b", Amount: "15"}); err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	rest, err := s.RefundTransaction(payment.UUID, nil)
+	if err != nil || rest.Amount != "15.00" {
+		t.Fatalf("Неверный возврат остатка: %+v, %v", rest, err)
+	}
+	checkBalances(10000, 0, 0)
+	if _, err := s.RefundTransaction(payment.UUID, nil); !errors.Is(err, ErrRefundExceedsAmount) {
+		t.Errorf("Ожидалась ошибка ErrRefundExceedsAmount, получена %v", err)
+	}
+
+	report, err := s.VerifyLedger()
+	if err != nil || !report.Balanced {
+		t.Errorf("Главная книга не сбалансирована: %+v, %v", report, err)
+	}
+}
+
+// TestRefundConversion проверяет возврат перевода с конвертацией:
+// с получателя списывается доля суммы зачисления по курсу исходного перевода,
+// и полный возврат частями списывает ровно зачисленную сумму.
+func TestRefundConversion(t *testing.T) {
+	provider := stubRateProvider{quote: fx.Quote{Rate: big.NewRat(185, 2), QuotedAt: time.Now()}}
+	s := NewService(memory.New(), Options{RateProvider: provider, FXQuoteTTL: time.Minute})
+	for _, wallet := range []database.Wallet{
+		{Address: "fx-refund-a", Balance: 10000, Currency: "USD"},
+		{Address: "fx-refund-b", Balance: 0, Currency: "RUB"},
+	} {
+		if err := s.store.Wallets().Create(&wallet); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+
+	payment, err := s.SendMoney(TransferRequest{FromAddress: "fx-refund-a", ToAddress: "fx-refund-b", Amount: "10", ToCurrency: "RUB"})
+	if err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	if payment.ToAmount != "925.00" {
+		t.Fatalf("Неверная сумма зачисления: %+v", payment)
+	}
+
+	partial := money.Decimal("3.33")
+	first, err := s.RefundTransaction(payment.UUID, &partial)
+	if err != nil {
+		t.Fatalf("Не удалось выполнить возврат: %v", err)
+	}
+	if first.Amount != "308.02" || first.Currency != "RUB" || first.ToAmount != "3.33" ||
+		first.ToCurrency != "USD" || first.FXRate != "0.0108108108" {
+		t.Errorf("Неверный возврат с конвертацией: %+v", first)
+	}
+
+	rest, err := s.RefundTransaction(payment.UUID, nil)
+	if err != nil {
+		t.Fatalf("Не удалось выполнить возврат: %v", err)
+	}
+	if rest.Amount != "616.98" || rest.ToAmount != "6.67" {
+		t.Errorf("Неверный возврат остатка: %+v", rest)
+	}
+	if got := balances(t, s); got[0] != 10000 || got[1] != 0 {
+		t.Errorf("Балансы %v, ожидалось [10000 0]", got)
+	}
+
+	report, err := s.VerifyLedger()
+	if err != nil || !report.Balanced {
+		t.Errorf("Главная книга не сбалансирована: %+v, %v", report, err)
+	}
+}
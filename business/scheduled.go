@@ -0,0 +1,293 @@
+package business
+
+import (
+	"errors"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// MaxScheduleHorizon — максимальный срок, на который можно запланировать перевод.
+const MaxScheduleHorizon = 365 * 24 * time.Hour
+
+// ScheduledTransferRequest содержит параметры запланированного перевода.
+type ScheduledTransferRequest struct {
+	TransferRequest
+	ExecuteAt time.Time // момент, не раньше которого выполняется перевод
+}
+
+// ScheduledTransferResponse представляет запланированный перевод, возвращаемый в API.
+type ScheduledTransferResponse struct {
+	ID          string         `json:"id"`                    // идентификатор запланированного перевода
+	From        string         `json:"from"`                  // адрес отправителя
+	To          string         `json:"to"`                    // адрес получателя
+	Amount      money.Decimal  `json:"amount"`                // сумма в валюте отправителя
+	Currency    money.Currency `json:"currency"`              // валюта отправителя
+	ToCurrency  money.Currency `json:"to_currency,omitempty"` // валюта зачисления, если перевод с конвертацией
+	ExecuteAt   time.Time      `json:"execute_at"`            // запланированное время выполнения
+	Status      string         `json:"status"`                // pending, executed, failed или cancelled
+	Transaction string         `json:"transaction,omitempty"` // UUID транзакции выполненного перевода
+	ErrorCode   string         `json:"error_code,omitempty"`  // код ошибки неуспешного перевода
+	Error       string         `json:"error,omitempty"`       // описание ошибки неуспешного перевода
+	ExecutedAt  *time.Time     `json:"executed_at,omitempty"` // момент выполнения или неуспешной попытки
+	CreatedAt   time.Time      `json:"created_at"`            // время создания
+	UpdatedAt   time.Time      `json:"updated_at"`            // время последнего изменения
+}
+
+// ScheduleTransfer планирует перевод req на момент req.ExecuteAt.
+//
+// Кошельки, валюты и сумма проверяются сразу, так же как в SendMoney;
+// достаточность средств и курс конвертации — в момент выполнения
+// (см. ExecuteScheduledTransfers).
+// Возможные ошибки:
+// - ErrInvalidExecuteAt — момент выполнения не в будущем или дальше MaxScheduleHorizon
+// - ErrSenderNotFound, ErrRecipientNotFound
+// - ErrSenderFrozen, ErrRecipientFrozen
+// - ErrInvalidAmount, ErrAmountPrecision
+// - ErrSameAddress
+// - ErrCurrencyMismatch
+func (s *Service) ScheduleTransfer(req ScheduledTransferRequest) (*ScheduledTransferResponse, error) {
+	now := time.Now()
+	if !req.ExecuteAt.After(now) || req.ExecuteAt.After(now.Add(MaxScheduleHorizon)) {
+		return nil, ErrInvalidExecuteAt
+	}
+	fromWallet, toWallet, amount, err := s.checkDeferredTransfer(req.TransferRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := database.ScheduledTransfer{
+		FromAddress: fromWallet.Address,
+		ToAddress:   toWallet.Address,
+		Amount:      amount,
+		Currency:    fromWallet.Currency,
+		ToCurrency:  toWallet.Currency,
+		ExecuteAt:   req.ExecuteAt,
+		Status:      database.ScheduledPending,
+	}
+	if err := s.runInTransaction(func(tx database.Store) error {
+		return tx.ScheduledTransfers().Create(&scheduled)
+	}); err != nil {
+		return nil, err
+	}
+	response := newScheduledTransferResponse(scheduled)
+	return &response, nil
+}
+
+// GetScheduledTransfer возвращает запланированный перевод по идентификатору.
+//
+// Возвращает ErrScheduledTransferNotFound, если перевод не найден.
+func (s *Service) GetScheduledTransfer(id string) (*ScheduledTransferResponse, error) {
+	scheduled, err := s.store.ScheduledTransfers().FindByUUID(id)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrScheduledTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	response := newScheduledTransferResponse(*scheduled)
+	return &response, nil
+}
+
+// CancelScheduledTransfer отменяет ожидающий запланированный перевод id.
+//
+// Перевод, который выполняется в этот момент, блокирует отмену до своего
+// завершения, после чего отмена возвращает ErrScheduledTransferNotPending.
+// Возможные ошибки:
+// - ErrScheduledTransferNotFound
+// - ErrScheduledTransferNotPending — перевод уже выполнен, неуспешен или отменён
+func (s *Service) CancelScheduledTransfer(id string) (*ScheduledTransferResponse, error) {
+	var scheduled *database.ScheduledTransfer
+	err := s.runInTransaction(func(tx database.Store) error {
+		var err error
+		scheduled, err = tx.ScheduledTransfers().LockByUUID(id)
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrScheduledTransferNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if scheduled.Status != database.ScheduledPending {
+			return ErrScheduledTransferNotPending
+		}
+		scheduled.Status = database.ScheduledCancelled
+		return tx.ScheduledTransfers().Update(scheduled)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := newScheduledTransferResponse(*scheduled)
+	return &response, nil
+}
+
+// maxScheduledAttempts — число попыток выполнить запланированный перевод,
+// прерванных внутренними ошибками, после которого перевод считается неуспешным.
+const maxScheduledAttempts = 5
+
+// ExecuteScheduledTransfers выполняет не более limit запланированных переводов,
+// время которых наступило, и возвращает их число.
+//
+// Каждый перевод выполняется в отдельной транзакции так же, как SendMoney.
+// Перевод, завершившийся бизнес-ошибкой (например, ErrInsufficientFunds),
+// переходит в состояние failed с её кодом и больше не выполняется.
+// При внутренней ошибке (например, недоступности источника курсов) попытка
+// учитывается, а ошибка возвращается; следующий вызов сначала выполняет
+// остальные переводы. После maxScheduledAttempts таких попыток перевод
+// переходит в состояние failed с кодом ErrScheduledTransferAborted.
+// Несколько экземпляров сервиса могут вызывать ExecuteScheduledTransfers
+// одновременно: каждый перевод выполняет только один из них.
+func (s *Service) ExecuteScheduledTransfers(limit int) (int, error) {
+	processed := 0
+	for processed < limit {
+		var (
+			scheduled   *database.ScheduledTransfer
+			transaction database.Transaction
+		)
+		err := s.runInTransaction(func(tx database.Store) error {
+			scheduled = nil
+			due, err := tx.ScheduledTransfers().LockDue(time.Now(), 1)
+			if err != nil || len(due) == 0 {
+				return err
+			}
+			scheduled = &due[0]
+
+			transaction, err = s.transfer(tx, TransferRequest{
+				FromAddress: scheduled.FromAddress,
+				ToAddress:   scheduled.ToAddress,
+				Amount:      scheduled.Amount.Decimal(scheduled.Currency),
+				Currency:    scheduled.Currency,
+				ToCurrency:  scheduled.ToCurrency,
+			}, nil, "")
+
+			// Бизнес-ошибки обнаруживаются до изменения данных,
+			// поэтому неуспешный перевод фиксируется в той же транзакции
+			var bizErr *Error
+			switch {
+			case errors.As(err, &bizErr):
+				scheduled.Status = database.ScheduledFailed
+				scheduled.ErrorCode = bizErr.Code
+				scheduled.Error = bizErr.Message
+			case err != nil:
+				return err
+			default:
+				scheduled.Status = database.ScheduledExecuted
+				scheduled.TransactionUUID = &transaction.UUID
+			}
+			executedAt := time.Now()
+			scheduled.ExecutedAt = &executedAt
+			return tx.ScheduledTransfers().Update(scheduled)
+		})
+		if err != nil {
+			if scheduled != nil {
+				if attemptErr := s.recordScheduledAttempt(scheduled.UUID); attemptErr != nil {
+					return processed, errors.Join(err, attemptErr)
+				}
+			}
+			return processed, err
+		}
+		if scheduled == nil {
+			break
+		}
+		if scheduled.Status == database.ScheduledExecuted {
+			s.streams.publish(transaction)
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// recordScheduledAttempt учитывает попытку выполнить запланированный перевод id,
+// прерванную внутренней ошибкой, и после maxScheduledAttempts попыток переводит
+// его в состояние failed.
+func (s *Service) recordScheduledAttempt(id string) error {
+	return s.runInTransaction(func(tx database.Store) error {
+		scheduled, err := tx.ScheduledTransfers().LockByUUID(id)
+		if err != nil {
+			return err
+		}
+		if scheduled.Status != database.ScheduledPending {
+			return nil
+		}
+		scheduled.Attempts++
+		if scheduled.Attempts >= maxScheduledAttempts {
+			executedAt := time.Now()
+			scheduled.Status = database.ScheduledFailed
+			scheduled.ErrorCode = ErrScheduledTransferAborted.Code
+			scheduled.Error = ErrScheduledTransferAborted.Message
+			scheduled.ExecutedAt = &executedAt
+		}
+		return tx.ScheduledTransfers().Update(scheduled)
+	})
+}
+
+// checkDeferredTransfer проверяет перевод req, который будет выполнен позже:
+// сумму, адреса, состояние кошельков и валюты — по тем же правилам, что и transfer.
+// Возвращает кошельки отправителя и получателя и сумму в минимальных единицах.
+func (s *Service) checkDeferredTransfer(req TransferRequest) (*database.Wallet, *database.Wallet, money.Amount, error) {
+	if req.Amount.Sign() <= 0 {
+		return nil, nil, 0, ErrInvalidAmount
+	}
+	if req.FromAddress == req.ToAddress {
+		return nil, nil, 0, ErrSameAddress
+	}
+
+	fromWallet, err := s.store.Wallets().FindByAddress(req.FromAddress)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil, 0, ErrSenderNotFound
+	}
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	toWallet, err := s.store.Wallets().FindByAddress(req.ToAddress)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil, 0, ErrRecipientNotFound
+	}
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if err := checkWalletStatus(fromWallet, toWallet); err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Те же правила валют, что и в transfer
+	if req.Currency != "" && req.Currency != fromWallet.Currency {
+		return nil, nil, 0, ErrCurrencyMismatch
+	}
+	if req.ToCurrency != "" && req.ToCurrency != toWallet.Currency {
+		return nil, nil, 0, ErrCurrencyMismatch
+	}
+	if fromWallet.Currency != toWallet.Currency && req.ToCurrency == "" {
+		return nil, nil, 0, ErrCurrencyMismatch
+	}
+	amount, err := toAmount(req.Amount, fromWallet.Currency)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return fromWallet, toWallet, amount, nil
+}
+
+// newScheduledTransferResponse преобразует модель запланированного перевода в ответ API.
+func newScheduledTransferResponse(t database.ScheduledTransfer) ScheduledTransferResponse {
+	response := ScheduledTransferResponse{
+		ID:         t.UUID,
+		From:       t.FromAddress,
+		To:         t.ToAddress,
+		Amount:     t.Amount.Decimal(t.Currency),
+		Currency:   t.Currency,
+		ExecuteAt:  t.ExecuteAt,
+		Status:     t.Status,
+		ErrorCode:  t.ErrorCode,
+		Error:      t.Error,
+		ExecutedAt: t.ExecutedAt,
+		CreatedAt:  t.CreatedAt,
+		UpdatedAt:  t.UpdatedAt,
+	}
+	if t.ToCurrency != t.Currency {
+		response.ToCurrency = t.ToCurrency
+	}
+	if t.TransactionUUID != nil {
+		response.Transaction = *t.TransactionUUID
+	}
+	return response
+}
@@ -0,0 +1,179 @@
+package business
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// TestScheduledTransfers проверяет запланированные переводы в хранилище в памяти
+// и в базах данных PostgreSQL и SQLite.
+func TestScheduledTransfers(t *testing.T) {
+	forEachStore(t, testScheduledTransfers)
+}
+
+// testScheduledTransfers выполняет проверки TestScheduledTransfers над сервисом s:
+//   - перевод нельзя запланировать на прошедшее время или с неверными кошельками;
+//   - до наступления времени перевод не выполняется;
+//   - наступивший перевод выполняется и ссылается на свою транзакцию;
+//   - при недостатке средств перевод становится неуспешным с кодом ошибки;
+//   - отменённый перевод не выполняется, а выполненный нельзя отменить;
+//   - балансы кошельков остаются согласованы с главной книгой.
+func testScheduledTransfers(t *testing.T, s *Service) {
+	createTestWallets(t, s, 10000, "scheduled-a")
+	createTestWallets(t, s, 0, "scheduled-b")
+
+	past := ScheduledTransferRequest{
+		TransferRequest: TransferRequest{FromAddress: "scheduled-a", ToAddress: "scheduled-b", Amount: "10"},
+		ExecuteAt:       time.Now().Add(-time.Minute),
+	}
+	if _, err := s.ScheduleTransfer(past); !errors.Is(err, ErrInvalidExecuteAt) {
+		t.Errorf("Ожидалась ошибка ErrInvalidExecuteAt, получена %v", err)
+	}
+	unknown := ScheduledTransferRequest{
+		TransferRequest: TransferRequest{FromAddress: "scheduled-a", ToAddress: "scheduled-unknown", Amount: "10"},
+		ExecuteAt:       time.Now().Add(time.Minute),
+	}
+	if _, err := s.ScheduleTransfer(unknown); !errors.Is(err, ErrRecipientNotFound) {
+		t.Errorf("Ожидалась ошибка ErrRecipientNotFound, получена %v", err)
+	}
+
+	executeAt := time.Now().Add(time.Second)
+	schedule := func(amount string) *ScheduledTransferResponse {
+		t.Helper()
+		scheduled, err := s.ScheduleTransfer(ScheduledTransferRequest{
+			TransferRequest: TransferRequest{FromAddress: "scheduled-a", ToAddress: "scheduled-b", Amount: money.Decimal(amount)},
+			ExecuteAt:       executeAt,
+		})
+		if err != nil {
+			t.Fatalf("Не удалось запланировать перевод: %v", err)
+		}
+		if scheduled.Status != database.ScheduledPending {
+			t.Errorf("Неверное состояние нового перевода: %+v", scheduled)
+		}
+		return scheduled
+	}
+	paid := schedule("30")
+	unpaid := schedule("80")
+	cancelled := schedule("5")
+
+	if _, err := s.CancelScheduledTransfer(cancelled.ID); err != nil {
+		t.Fatalf("Не удалось отменить перевод: %v", err)
+	}
+	if _, err := s.CancelScheduledTransfer(cancelled.ID); !errors.Is(err, ErrScheduledTransferNotPending) {
+		t.Errorf("Ожидалась ошибка ErrScheduledTransferNotPending, получена %v", err)
+	}
+	if _, err := s.CancelScheduledTransfer("unknown"); !errors.Is(err, ErrScheduledTransferNotFound) {
+		t.Errorf("Ожидалась ошибка ErrScheduledTransferNotFound, получена %v", err)
+	}
+
+	if executed, err := s.ExecuteScheduledTransfers(10); err != nil || executed != 0 {
+		t.Fatalf("Переводы выполнены до наступления времени: %d, %v", executed, err)
+	}
+
+	time.Sleep(time.Until(executeAt) + 100*time.Millisecond)
+	if executed, err := s.ExecuteScheduledTransfers(10); err != nil || executed != 2 {
+		t.Fatalf("Выполнено %d переводов, ожидалось 2: %v", executed, err)
+	}
+	if executed, err := s.ExecuteScheduledTransfers(10); err != nil || executed != 0 {
+		t.Errorf("Переводы выполнены повторно: %d, %v", executed, err)
+	}
+
+	got, err := s.GetScheduledTransfer(paid.ID)
+	if err != nil {
+		t.Fatalf("Не удалось получить перевод: %v", err)
+	}
+	if got.Status != database.ScheduledExecuted || got.Transaction == "" || got.ExecutedAt == nil {
+		t.Errorf("Неверный выполненный перевод: %+v", got)
+	}
+	if got, err = s.GetScheduledTransfer(unpaid.ID); err != nil {
+		t.Fatalf("Не удалось получить перевод: %v", err)
+	}
+	if got.Status != database.ScheduledFailed || got.ErrorCode != ErrInsufficientFunds.Code || got.Transaction != "" {
+		t.Errorf("Неверный неуспешный перевод: %+v", got)
+	}
+	if data, err := json.Marshal(got); err != nil || !strings.Contains(string(data), `"error_code":"INSUFFICIENT_FUNDS"`) {
+		t.Errorf("Причина ошибки должна быть в поле error_code: %s, %v", data, err)
+	}
+	if got, err = s.GetScheduledTransfer(cancelled.ID); err != nil || got.Status != database.ScheduledCancelled {
+		t.Errorf("Неверный отменённый перевод: %+v, %v", got, err)
+	}
+	if _, err := s.CancelScheduledTransfer(paid.ID); !errors.Is(err, ErrScheduledTransferNotPending) {
+		t.Errorf("Ожидалась ошибка ErrScheduledTransferNotPending, получена %v", err)
+	}
+
+	if got := balances(t, s); got[0] != 7000 || got[1] != 3000 {
+		t.Errorf("Балансы %v, ожидалось [7000 3000]", got)
+	}
+	report, err := s.VerifyLedger()
+	if err != nil || !report.Balanced {
+		t.Errorf("Главная книга не сбалансирована: %+v, %v", report, err)
+	}
+}
+
+// TestScheduledTransferAttempts проверяет запланированные переводы, прерванные
+// внутренними ошибками, в хранилище в памяти и в базах данных PostgreSQL и SQLite.
+func TestScheduledTransferAttempts(t *testing.T) {
+	forEachStore(t, testScheduledTransferAttempts)
+}
+
+// testScheduledTransferAttempts выполняет проверки TestScheduledTransferAttempts
+// над сервисом s:
+//   - внутренняя ошибка перевода возвращается, а попытка учитывается;
+//   - перевод с прерванной попыткой не задерживает следующие за ним;
+//   - после maxScheduledAttempts попыток перевод становится неуспешным
+//     с кодом SCHEDULED_TRANSFER_ABORTED и больше не выбирается.
+func testScheduledTransferAttempts(t *testing.T, s *Service) {
+	s.options.RateProvider = stubRateProvider{err: errors.New("источник курсов недоступен")}
+	for _, wallet := range []database.Wallet{
+		{Address: "sched-attempts-usd", Balance: 10000, Currency: "USD"},
+		{Address: "sched-attempts-rub", Balance: 0, Currency: "RUB"},
+	} {
+		if err := s.store.Wallets().Create(&wallet); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+	createTestWallets(t, s, 10000, "sched-attempts-a", "sched-attempts-b")
+
+	executeAt := time.Now().Add(200 * time.Millisecond)
+	schedule := func(req TransferRequest) *ScheduledTransferResponse {
+		t.Helper()
+		scheduled, err := s.ScheduleTransfer(ScheduledTransferRequest{TransferRequest: req, ExecuteAt: executeAt})
+		if err != nil {
+			t.Fatalf("Не удалось запланировать перевод: %v", err)
+		}
+		return scheduled
+	}
+	// Перевод с конвертацией запланирован первым и не выполняется без курса
+	broken := schedule(TransferRequest{FromAddress: "sched-attempts-usd", ToAddress: "sched-attempts-rub", Amount: "10", ToCurrency: "RUB"})
+	healthy := schedule(TransferRequest{FromAddress: "sched-attempts-a", ToAddress: "sched-attempts-b", Amount: "10"})
+	time.Sleep(time.Until(executeAt) + 100*time.Millisecond)
+
+	if executed, err := s.ExecuteScheduledTransfers(10); err == nil || executed != 0 {
+		t.Fatalf("Первая попытка: выполнено %d, ошибка %v; ожидалась внутренняя ошибка", executed, err)
+	}
+	if executed, err := s.ExecuteScheduledTransfers(10); err == nil || executed != 1 {
+		t.Fatalf("Вторая попытка: выполнено %d, ошибка %v; ожидался 1 перевод и ошибка", executed, err)
+	}
+	if got, err := s.GetScheduledTransfer(healthy.ID); err != nil || got.Status != database.ScheduledExecuted {
+		t.Errorf("Перевод за прерванным должен быть выполнен: %+v, %v", got, err)
+	}
+
+	for attempt := 3; attempt <= maxScheduledAttempts; attempt++ {
+		if _, err := s.ExecuteScheduledTransfers(10); err == nil {
+			t.Errorf("Попытка %d: ожидалась внутренняя ошибка", attempt)
+		}
+	}
+	got, err := s.GetScheduledTransfer(broken.ID)
+	if err != nil || got.Status != database.ScheduledFailed || got.ErrorCode != ErrScheduledTransferAborted.Code {
+		t.Errorf("Перевод должен стать неуспешным после %d попыток: %+v, %v", maxScheduledAttempts, got, err)
+	}
+	if executed, err := s.ExecuteScheduledTransfers(10); err != nil || executed != 0 {
+		t.Errorf("Неуспешный перевод выбран повторно: %d, %v", executed, err)
+	}
+}
@@ -0,0 +1,87 @@
+package business
+
+import (
+	"net/http"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/fx"
+)
+
+// Options задаёт настройки бизнес-логики.
+// Нулевые значения полей заменяются значениями по умолчанию.
+type Options struct {
+	// RateProvider — источник курсов для переводов с конвертацией.
+	// Если он не задан, переводы между валютами недоступны.
+	RateProvider fx.RateProvider
+	// FXQuoteTTL — срок жизни котировки курса, по умолчанию одна минута.
+	// Котировки старше этого срока не используются для переводов.
+	FXQuoteTTL time.Duration
+	// IdempotencyKeyRetention — срок хранения ключей идемпотентности, по умолчанию 24 часа.
+	// По истечении этого срока ключ освобождается и может быть использован повторно.
+	IdempotencyKeyRetention time.Duration
+	// HoldTTL — срок резервирования средств, если он не указан в запросе,
+	// по умолчанию 24 часа.
+	HoldTTL time.Duration
+	// WebhookMaxAttempts — число попыток доставки вебхука, по умолчанию 8.
+	// После последней неудачной попытки доставка помечается мёртвой.
+	WebhookMaxAttempts int
+	// WebhookRetryBackoff — задержка перед первой повторной попыткой доставки
+	// вебхука, по умолчанию 5 секунд. Каждая следующая задержка вдвое больше
+	// предыдущей, но не больше часа.
+	WebhookRetryBackoff time.Duration
+	// WebhookClient — HTTP-клиент для отправки вебхуков,
+	// по умолчанию клиент с тайм-аутом 10 секунд.
+	WebhookClient *http.Client
+	// StandingOrderMaxRetries — число повторных попыток выполнения регулярного
+	// платежа при недостатке средств, если оно не указано в запросе;
+	// nil — 3, 0 — без повторных попыток.
+	StandingOrderMaxRetries *int
+	// StandingOrderRetryInterval — задержка перед повторной попыткой выполнения
+	// регулярного платежа, если она не указана в запросе, по умолчанию один час.
+	StandingOrderRetryInterval time.Duration
+	// StreamPollInterval — период опроса хранилища открытыми потоками транзакций,
+	// по умолчанию одна секунда. Переводы этого экземпляра сервиса передаются
+	// сразу, переводы других экземпляров — не позже чем через этот период.
+	StreamPollInterval time.Duration
+}
+
+// Service выполняет операции платёжной системы над хранилищем данных.
+type Service struct {
+	store   database.Store
+	options Options
+	streams streamHub // открытые потоки транзакций
+}
+
+// NewService возвращает сервис, работающий с хранилищем store.
+func NewService(store database.Store, options Options) *Service {
+	if options.FXQuoteTTL == 0 {
+		options.FXQuoteTTL = time.Minute
+	}
+	if options.IdempotencyKeyRetention == 0 {
+		options.IdempotencyKeyRetention = 24 * time.Hour
+	}
+	if options.HoldTTL == 0 {
+		options.HoldTTL = 24 * time.Hour
+	}
+	if options.WebhookMaxAttempts == 0 {
+		options.WebhookMaxAttempts = 8
+	}
+	if options.WebhookRetryBackoff == 0 {
+		options.WebhookRetryBackoff = 5 * time.Second
+	}
+	if options.StandingOrderMaxRetries == nil {
+		retries := 3
+		options.StandingOrderMaxRetries = &retries
+	}
+	if options.StandingOrderRetryInterval == 0 {
+		options.StandingOrderRetryInterval = time.Hour
+	}
+	if options.StreamPollInterval == 0 {
+		options.StreamPollInterval = time.Second
+	}
+	if options.WebhookClient == nil {
+		options.WebhookClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Service{store: store, options: options}
+}
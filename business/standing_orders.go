@@ -0,0 +1,378 @@
+package business
+
+import (
+	"errors"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+	"payment_system_api/schedule"
+)
+
+// MinStandingOrderInterval — минимальный интервал между выполнениями регулярного платежа.
+const MinStandingOrderInterval = time.Minute
+
+// StandingOrderRequest содержит параметры регулярного платежа.
+//
+// Расписание задаётся ровно одним из полей Cron и Interval.
+type StandingOrderRequest struct {
+	TransferRequest
+	Cron          string        // cron-выражение в UTC, см. schedule.Cron
+	Interval      time.Duration // интервал между выполнениями в целых секундах, не меньше MinStandingOrderInterval
+	StartAt       time.Time     // начало расписания, нулевое — текущий момент
+	EndAt         *time.Time    // дата окончания, nil — без ограничения
+	MaxRuns       int           // максимальное число выполнений, 0 — без ограничения
+	MaxRetries    *int          // число повторных попыток при недостатке средств, nil — Options.StandingOrderMaxRetries
+	RetryInterval time.Duration // задержка перед повторной попыткой в целых секундах, 0 — Options.StandingOrderRetryInterval
+}
+
+// StandingOrderResponse представляет регулярный платёж, возвращаемый в API.
+type StandingOrderResponse struct {
+	ID            string         `json:"id"`                    // идентификатор регулярного платежа
+	From          string         `json:"from"`                  // адрес отправителя
+	To            string         `json:"to"`                    // адрес получателя
+	Amount        money.Decimal  `json:"amount"`                // сумма в валюте отправителя
+	Currency      money.Currency `json:"currency"`              // валюта отправителя
+	ToCurrency    money.Currency `json:"to_currency,omitempty"` // валюта зачисления, если перевод с конвертацией
+	Cron          string         `json:"cron,omitempty"`        // cron-выражение расписания
+	Interval      int64          `json:"interval,omitempty"`    // интервал между выполнениями в секундах
+	StartAt       time.Time      `json:"start_at"`              // начало расписания
+	EndAt         *time.Time     `json:"end_at,omitempty"`      // дата окончания
+	MaxRuns       int            `json:"max_runs,omitempty"`    // максимальное число выполнений
+	MaxRetries    int            `json:"max_retries"`           // число повторных попыток при недостатке средств
+	RetryInterval int64          `json:"retry_interval"`        // задержка перед повторной попыткой в секундах
+	Status        string         `json:"status"`                // active, completed или cancelled
+	Runs          int            `json:"runs"`                  // число завершённых выполнений, включая пропущенные
+	NextRunAt     *time.Time     `json:"next_run_at,omitempty"` // момент следующей попытки активного платежа
+	CreatedAt     time.Time      `json:"created_at"`            // время создания
+	UpdatedAt     time.Time      `json:"updated_at"`            // время последнего изменения
+}
+
+// StandingOrderRunResponse представляет попытку выполнения регулярного платежа.
+type StandingOrderRunResponse struct {
+	Run         int       `json:"run"`                   // номер выполнения, начиная с единицы
+	Attempt     int       `json:"attempt"`               // номер попытки выполнения, начиная с единицы
+	ScheduledAt time.Time `json:"scheduled_at"`          // момент выполнения по расписанию
+	Status      string    `json:"status"`                // executed, retrying или failed
+	Transaction string    `json:"transaction,omitempty"` // UUID транзакции выполненного перевода
+	ErrorCode   string    `json:"error_code,omitempty"`  // код ошибки неуспешной попытки
+	Error       string    `json:"error,omitempty"`       // описание ошибки неуспешной попытки
+	CreatedAt   time.Time `json:"created_at"`            // момент попытки
+}
+
+// CreateStandingOrder создаёт регулярный платёж req.
+//
+// Кошельки, валюты и сумма проверяются сразу, так же как в ScheduleTransfer.
+// Первое выполнение — первый момент расписания не раньше req.StartAt.
+// Возможные ошибки:
+// - ErrInvalidSchedule — расписание, даты или параметры повторных попыток некорректны
+// - ErrSenderNotFound, ErrRecipientNotFound
+// - ErrSenderFrozen, ErrRecipientFrozen
+// - ErrInvalidAmount, ErrAmountPrecision
+// - ErrSameAddress
+// - ErrCurrencyMismatch
+func (s *Service) CreateStandingOrder(req StandingOrderRequest) (*StandingOrderResponse, error) {
+	now := time.Now()
+	start := req.StartAt
+	if start.IsZero() {
+		start = now.Truncate(time.Second)
+	} else if start.Before(now) || start.After(now.Add(MaxScheduleHorizon)) {
+		return nil, ErrInvalidSchedule
+	}
+	if (req.Cron == "") == (req.Interval == 0) ||
+		req.Interval != 0 && (req.Interval < MinStandingOrderInterval || req.Interval%time.Second != 0) ||
+		req.RetryInterval < 0 || req.RetryInterval%time.Second != 0 ||
+		req.MaxRuns < 0 || req.MaxRetries != nil && *req.MaxRetries < 0 {
+		return nil, ErrInvalidSchedule
+	}
+	fromWallet, toWallet, amount, err := s.checkDeferredTransfer(req.TransferRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	order := database.StandingOrder{
+		FromAddress:          fromWallet.Address,
+		ToAddress:            toWallet.Address,
+		Amount:               amount,
+		Currency:             fromWallet.Currency,
+		ToCurrency:           toWallet.Currency,
+		Cron:                 req.Cron,
+		IntervalSeconds:      int64(req.Interval / time.Second),
+		StartAt:              start,
+		EndAt:                req.EndAt,
+		MaxRuns:              req.MaxRuns,
+		MaxRetries:           *s.options.StandingOrderMaxRetries,
+		RetryIntervalSeconds: int64(s.options.StandingOrderRetryInterval / time.Second),
+		Status:               database.StandingOrderActive,
+	}
+	if req.MaxRetries != nil {
+		order.MaxRetries = *req.MaxRetries
+	}
+	if req.RetryInterval != 0 {
+		order.RetryIntervalSeconds = int64(req.RetryInterval / time.Second)
+	}
+	sched, err := standingOrderSchedule(&order)
+	if err != nil {
+		return nil, ErrInvalidSchedule
+	}
+	first := sched.Next(start.Add(-time.Nanosecond))
+	if first.IsZero() || order.EndAt != nil && first.After(*order.EndAt) {
+		return nil, ErrInvalidSchedule
+	}
+	order.RunAt, order.NextAttemptAt = first, first
+
+	if err := s.runInTransaction(func(tx database.Store) error {
+		return tx.StandingOrders().Create(&order)
+	}); err != nil {
+		return nil, err
+	}
+	response := newStandingOrderResponse(order)
+	return &response, nil
+}
+
+// GetStandingOrder возвращает регулярный платёж по идентификатору.
+//
+// Возвращает ErrStandingOrderNotFound, если платёж не найден.
+func (s *Service) GetStandingOrder(id string) (*StandingOrderResponse, error) {
+	order, err := s.store.StandingOrders().FindByUUID(id)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrStandingOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	response := newStandingOrderResponse(*order)
+	return &response, nil
+}
+
+// GetStandingOrderRuns возвращает историю попыток выполнения регулярного платежа id
+// в порядке их выполнения.
+//
+// Возвращает ErrStandingOrderNotFound, если платёж не найден.
+func (s *Service) GetStandingOrderRuns(id string) ([]StandingOrderRunResponse, error) {
+	order, err := s.store.StandingOrders().FindByUUID(id)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrStandingOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	runs, err := s.store.StandingOrders().FindRuns(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]StandingOrderRunResponse, 0, len(runs))
+	for _, run := range runs {
+		response := StandingOrderRunResponse{
+			Run:         run.Run,
+			Attempt:     run.Attempt,
+			ScheduledAt: run.ScheduledAt,
+			Status:      run.Status,
+			ErrorCode:   run.ErrorCode,
+			Error:       run.Error,
+			CreatedAt:   run.CreatedAt,
+		}
+		if run.TransactionUUID != nil {
+			response.Transaction = *run.TransactionUUID
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// CancelStandingOrder отменяет активный регулярный платёж id.
+//
+// Выполняющаяся в этот момент попытка блокирует отмену до своего завершения.
+// Возможные ошибки:
+// - ErrStandingOrderNotFound
+// - ErrStandingOrderNotActive — платёж уже завершён или отменён
+func (s *Service) CancelStandingOrder(id string) (*StandingOrderResponse, error) {
+	var order *database.StandingOrder
+	err := s.runInTransaction(func(tx database.Store) error {
+		var err error
+		order, err = tx.StandingOrders().LockByUUID(id)
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrStandingOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if order.Status != database.StandingOrderActive {
+			return ErrStandingOrderNotActive
+		}
+		order.Status = database.StandingOrderCancelled
+		return tx.StandingOrders().Update(order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := newStandingOrderResponse(*order)
+	return &response, nil
+}
+
+// ExecuteStandingOrders выполняет не более limit попыток регулярных платежей,
+// время которых наступило, и возвращает их число.
+//
+// Каждая попытка выполняется в отдельной транзакции так же, как SendMoney,
+// и записывается в историю платежа. При недостатке средств попытка
+// повторяется через RetryIntervalSeconds, но не больше MaxRetries раз и не позже
+// следующего выполнения по расписанию; после этого, как и при других
+// бизнес-ошибках, выполнение пропускается. Если кошелёк отправителя или
+// получателя закрыт, платёж уже не сможет выполниться и отменяется,
+// а причина записывается в историю. Выполнения, пропущенные из-за
+// простоя планировщика, не наверстываются: следующее выполнение — первый
+// момент расписания после текущего времени.
+// Несколько экземпляров сервиса могут вызывать ExecuteStandingOrders
+// одновременно: каждую попытку выполняет только один из них.
+func (s *Service) ExecuteStandingOrders(limit int) (int, error) {
+	processed := 0
+	for processed < limit {
+		var (
+			order       *database.StandingOrder
+			transaction database.Transaction
+			executed    bool
+		)
+		err := s.runInTransaction(func(tx database.Store) error {
+			order, executed = nil, false
+			now := time.Now()
+			due, err := tx.StandingOrders().LockDue(now, 1)
+			if err != nil || len(due) == 0 {
+				return err
+			}
+			order = &due[0]
+			sched, err := standingOrderSchedule(order)
+			if err != nil {
+				return err
+			}
+
+			transaction, err = s.transfer(tx, TransferRequest{
+				FromAddress: order.FromAddress,
+				ToAddress:   order.ToAddress,
+				Amount:      order.Amount.Decimal(order.Currency),
+				Currency:    order.Currency,
+				ToCurrency:  order.ToCurrency,
+			}, nil, "")
+
+			run := database.StandingOrderRun{
+				StandingOrderID: order.ID,
+				Run:             order.Runs + 1,
+				Attempt:         order.Attempt + 1,
+				ScheduledAt:     order.RunAt,
+			}
+			// Бизнес-ошибки обнаруживаются до изменения данных,
+			// поэтому неуспешная попытка фиксируется в той же транзакции
+			var bizErr *Error
+			switch {
+			case errors.As(err, &bizErr):
+				run.Status = database.StandingOrderRunFailed
+				run.ErrorCode = bizErr.Code
+				run.Error = bizErr.Message
+				if errors.Is(err, ErrInsufficientFunds) && retryStandingOrder(order, sched, now) {
+					run.Status = database.StandingOrderRunRetrying
+				}
+				// Закрытый кошелёк не откроется снова: платёж отменяется,
+				// чтобы не завершаться ошибкой при каждом выполнении
+				if errors.Is(err, ErrSenderNotFound) || errors.Is(err, ErrRecipientNotFound) {
+					order.Status = database.StandingOrderCancelled
+					run.Error += "; регулярный платёж отменён"
+				}
+			case err != nil:
+				return err
+			default:
+				executed = true
+				run.Status = database.StandingOrderRunExecuted
+				run.TransactionUUID = &transaction.UUID
+			}
+			if run.Status != database.StandingOrderRunRetrying && order.Status == database.StandingOrderActive {
+				advanceStandingOrder(order, sched, now)
+			}
+
+			if err := tx.StandingOrders().CreateRun(&run); err != nil {
+				return err
+			}
+			return tx.StandingOrders().Update(order)
+		})
+		if err != nil {
+			return processed, err
+		}
+		if order == nil {
+			break
+		}
+		if executed {
+			s.streams.publish(transaction)
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// standingOrderSchedule возвращает расписание регулярного платежа order.
+func standingOrderSchedule(order *database.StandingOrder) (schedule.Schedule, error) {
+	if order.Cron != "" {
+		return schedule.ParseCron(order.Cron)
+	}
+	return schedule.Interval{Start: order.StartAt, Every: time.Duration(order.IntervalSeconds) * time.Second}, nil
+}
+
+// retryStandingOrder планирует повторную попытку текущего выполнения order
+// и сообщает, запланирована ли она. Повторная попытка не планируется, если
+// попытки исчерпаны или она пришлась бы на следующее выполнение по расписанию.
+func retryStandingOrder(order *database.StandingOrder, sched schedule.Schedule, now time.Time) bool {
+	if order.Attempt >= order.MaxRetries {
+		return false
+	}
+	retryAt := now.Add(time.Duration(order.RetryIntervalSeconds) * time.Second)
+	if next := sched.Next(order.RunAt); !next.IsZero() && !retryAt.Before(next) {
+		return false
+	}
+	order.Attempt++
+	order.NextAttemptAt = retryAt
+	return true
+}
+
+// advanceStandingOrder завершает текущее выполнение order и планирует следующее
+// или завершает платёж, если достигнута дата окончания или число выполнений.
+func advanceStandingOrder(order *database.StandingOrder, sched schedule.Schedule, now time.Time) {
+	order.Runs++
+	order.Attempt = 0
+	after := order.RunAt
+	if now.After(after) {
+		after = now
+	}
+	next := sched.Next(after)
+	if next.IsZero() || order.MaxRuns > 0 && order.Runs >= order.MaxRuns || order.EndAt != nil && next.After(*order.EndAt) {
+		order.Status = database.StandingOrderCompleted
+		return
+	}
+	order.RunAt, order.NextAttemptAt = next, next
+}
+
+// newStandingOrderResponse преобразует модель регулярного платежа в ответ API.
+func newStandingOrderResponse(o database.StandingOrder) StandingOrderResponse {
+	response := StandingOrderResponse{
+		ID:            o.UUID,
+		From:          o.FromAddress,
+		To:            o.ToAddress,
+		Amount:        o.Amount.Decimal(o.Currency),
+		Currency:      o.Currency,
+		Cron:          o.Cron,
+		Interval:      o.IntervalSeconds,
+		StartAt:       o.StartAt,
+		EndAt:         o.EndAt,
+		MaxRuns:       o.MaxRuns,
+		MaxRetries:    o.MaxRetries,
+		RetryInterval: o.RetryIntervalSeconds,
+		Status:        o.Status,
+		Runs:          o.Runs,
+		CreatedAt:     o.CreatedAt,
+		UpdatedAt:     o.UpdatedAt,
+	}
+	if o.ToCurrency != o.Currency {
+		response.ToCurrency = o.ToCurrency
+	}
+	if o.Status == database.StandingOrderActive {
+		next := o.NextAttemptAt
+		response.NextRunAt = &next
+	}
+	return response
+}
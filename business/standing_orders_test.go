@@ -0,0 +1,227 @@
+package business
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/memory"
+	"payment_system_api/money"
+)
+
+// TestStandingOrders проверяет регулярные платежи в хранилище в памяти
+// и в базах данных PostgreSQL и SQLite.
+func TestStandingOrders(t *testing.T) {
+	forEachStore(t, testStandingOrders)
+}
+
+// testStandingOrders выполняет проверки TestStandingOrders над сервисом s:
+//   - некорректное расписание отклоняется;
+//   - выполнение создаёт транзакцию и планирует следующее выполнение;
+//   - по достижении числа выполнений платёж завершается;
+//   - при недостатке средств попытка повторяется, а без повторных попыток
+//     выполнение пропускается с кодом ошибки;
+//   - история попыток записывается для каждого платежа;
+//   - завершённый и отменённый платёж нельзя отменить;
+//   - балансы кошельков остаются согласованы с главной книгой.
+func testStandingOrders(t *testing.T, s *Service) {
+	createTestWallets(t, s, 10000, "standing-a")
+	createTestWallets(t, s, 0, "standing-b")
+	createTestWallets(t, s, 20000, "standing-c")
+
+	transfer := func(amount money.Decimal) TransferRequest {
+		return TransferRequest{FromAddress: "standing-a", ToAddress: "standing-b", Amount: amount}
+	}
+	for _, req := range []StandingOrderRequest{
+		{TransferRequest: transfer("10")},
+		{TransferRequest: transfer("10"), Cron: "0 9 * * *", Interval: time.Hour},
+		{TransferRequest: transfer("10"), Interval: 30 * time.Second},
+		{TransferRequest: transfer("10"), Cron: "0 9 * *"},
+		{TransferRequest: transfer("10"), Cron: "0 0 30 2 *"},
+		{TransferRequest: transfer("10"), Interval: time.Hour, StartAt: time.Now().Add(-time.Hour)},
+	} {
+		if _, err := s.CreateStandingOrder(req); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("Ожидалась ошибка ErrInvalidSchedule для %+v, получена %v", req, err)
+		}
+	}
+
+	daily, err := s.CreateStandingOrder(StandingOrderRequest{TransferRequest: transfer("10"), Cron: "0 9 * * *"})
+	if err != nil {
+		t.Fatalf("Не удалось создать регулярный платёж: %v", err)
+	}
+	if daily.NextRunAt == nil || !daily.NextRunAt.After(time.Now()) || daily.NextRunAt.UTC().Hour() != 9 {
+		t.Errorf("Неверный момент первого выполнения по cron: %+v", daily)
+	}
+
+	create := func(req StandingOrderRequest) *StandingOrderResponse {
+		t.Helper()
+		order, err := s.CreateStandingOrder(req)
+		if err != nil {
+			t.Fatalf("Не удалось создать регулярный платёж: %v", err)
+		}
+		return order
+	}
+	noRetries := 0
+	once := create(StandingOrderRequest{TransferRequest: transfer("10"), Interval: time.Hour, MaxRuns: 1})
+	retried := create(StandingOrderRequest{TransferRequest: transfer("150"), Interval: time.Hour, RetryInterval: time.Second})
+	skipped := create(StandingOrderRequest{TransferRequest: transfer("500"), Interval: time.Hour, MaxRetries: &noRetries})
+
+	if executed, err := s.ExecuteStandingOrders(10); err != nil || executed != 3 {
+		t.Fatalf("Выполнено %d попыток, ожидалось 3: %v", executed, err)
+	}
+	if executed, err := s.ExecuteStandingOrders(10); err != nil || executed != 0 {
+		t.Errorf("Попытки выполнены повторно: %d, %v", executed, err)
+	}
+
+	get := func(id string) *StandingOrderResponse {
+		t.Helper()
+		order, err := s.GetStandingOrder(id)
+		if err != nil {
+			t.Fatalf("Не удалось получить регулярный платёж: %v", err)
+		}
+		return order
+	}
+	if got := get(once.ID); got.Status != database.StandingOrderCompleted || got.Runs != 1 || got.NextRunAt != nil {
+		t.Errorf("Неверный завершённый платёж: %+v", got)
+	}
+	got := get(skipped.ID)
+	if got.Status != database.StandingOrderActive || got.Runs != 1 || got.NextRunAt == nil ||
+		!got.NextRunAt.Equal(skipped.StartAt.Add(time.Hour)) {
+		t.Errorf("Неверный платёж после пропущенного выполнения: %+v", got)
+	}
+	got = get(retried.ID)
+	if got.Runs != 0 || got.NextRunAt == nil || !got.NextRunAt.Before(retried.StartAt.Add(time.Hour)) {
+		t.Fatalf("Неверный платёж после неуспешной попытки: %+v", got)
+	}
+
+	// Пополнение до повторной попытки
+	if _, err := s.SendMoney(TransferRequest{FromAddress: "standing-c", ToAddress: "standing-a", Amount: "100"}); err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	time.Sleep(time.Until(*got.NextRunAt) + 100*time.Millisecond)
+	if executed, err := s.ExecuteStandingOrders(10); err != nil || executed != 1 {
+		t.Fatalf("Выполнено %d попыток, ожидалось 1: %v", executed, err)
+	}
+	if got := get(retried.ID); got.Runs != 1 || got.NextRunAt == nil || !got.NextRunAt.Equal(retried.StartAt.Add(time.Hour)) {
+		t.Errorf("Неверный платёж после повторной попытки: %+v", got)
+	}
+
+	runs, err := s.GetStandingOrderRuns(retried.ID)
+	if err != nil {
+		t.Fatalf("Не удалось получить историю: %v", err)
+	}
+	if len(runs) != 2 ||
+		runs[0].Status != database.StandingOrderRunRetrying || runs[0].ErrorCode != ErrInsufficientFunds.Code || runs[0].Attempt != 1 ||
+		runs[1].Status != database.StandingOrderRunExecuted || runs[1].Transaction == "" || runs[1].Run != 1 || runs[1].Attempt != 2 {
+		t.Errorf("Неверная история повторных попыток: %+v", runs)
+	}
+	if data, err := json.Marshal(runs[0]); err != nil || !strings.Contains(string(data), `"error_code":"INSUFFICIENT_FUNDS"`) {
+		t.Errorf("Причина ошибки должна быть в поле error_code: %s, %v", data, err)
+	}
+	if runs, err = s.GetStandingOrderRuns(skipped.ID); err != nil || len(runs) != 1 ||
+		runs[0].Status != database.StandingOrderRunFailed || runs[0].ErrorCode != ErrInsufficientFunds.Code {
+		t.Errorf("Неверная история пропущенного выполнения: %+v, %v", runs, err)
+	}
+	if runs, err = s.GetStandingOrderRuns(once.ID); err != nil || len(runs) != 1 || runs[0].Transaction == "" {
+		t.Errorf("Неверная история выполнения: %+v, %v", runs, err)
+	}
+	if _, err := s.GetStandingOrderRuns("unknown"); !errors.Is(err, ErrStandingOrderNotFound) {
+		t.Errorf("Ожидалась ошибка ErrStandingOrderNotFound, получена %v", err)
+	}
+
+	if cancelled, err := s.CancelStandingOrder(skipped.ID); err != nil || cancelled.Status != database.StandingOrderCancelled {
+		t.Errorf("Неверная отмена: %+v, %v", cancelled, err)
+	}
+	for _, id := range []string{skipped.ID, once.ID} {
+		if _, err := s.CancelStandingOrder(id); !errors.Is(err, ErrStandingOrderNotActive) {
+			t.Errorf("Ожидалась ошибка ErrStandingOrderNotActive, получена %v", err)
+		}
+	}
+
+	if got := balances(t, s); got[0] != 4000 || got[1] != 16000 || got[2] != 10000 {
+		t.Errorf("Балансы %v, ожидалось [4000 16000 10000]", got)
+	}
+	report, err := s.VerifyLedger()
+	if err != nil || !report.Balanced {
+		t.Errorf("Главная книга не сбалансирована: %+v, %v", report, err)
+	}
+}
+
+// TestStandingOrderClosedWallet проверяет отмену регулярного платежа на закрытый
+// кошелёк в хранилище в памяти и в базах данных PostgreSQL и SQLite.
+func TestStandingOrderClosedWallet(t *testing.T) {
+	forEachStore(t, testStandingOrderClosedWallet)
+}
+
+// testStandingOrderClosedWallet выполняет проверки TestStandingOrderClosedWallet
+// над сервисом s: если кошелёк получателя закрыт, выполнение записывается
+// в историю с причиной, платёж отменяется и больше не выполняется.
+func testStandingOrderClosedWallet(t *testing.T, s *Service) {
+	createTestWallets(t, s, 10000, "standing-closed-a")
+	createTestWallets(t, s, 0, "standing-closed-b")
+
+	order, err := s.CreateStandingOrder(StandingOrderRequest{
+		TransferRequest: TransferRequest{FromAddress: "standing-closed-a", ToAddress: "standing-closed-b", Amount: "10"},
+		Interval:        time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Не удалось создать регулярный платёж: %v", err)
+	}
+	if err := s.CloseWallet("standing-closed-b", WalletStatusRequest{}); err != nil {
+		t.Fatalf("Не удалось закрыть кошелек: %v", err)
+	}
+
+	if executed, err := s.ExecuteStandingOrders(10); err != nil || executed != 1 {
+		t.Fatalf("Выполнено %d попыток, ожидалось 1: %v", executed, err)
+	}
+	got, err := s.GetStandingOrder(order.ID)
+	if err != nil || got.Status != database.StandingOrderCancelled || got.NextRunAt != nil {
+		t.Errorf("Платёж на закрытый кошелек должен быть отменён: %+v, %v", got, err)
+	}
+	runs, err := s.GetStandingOrderRuns(order.ID)
+	if err != nil || len(runs) != 1 || runs[0].Status != database.StandingOrderRunFailed ||
+		runs[0].ErrorCode != ErrRecipientNotFound.Code || !strings.Contains(runs[0].Error, "отменён") {
+		t.Errorf("Неверная история отменённого платежа: %+v, %v", runs, err)
+	}
+}
+
+// TestStandingOrderDefaultRetries проверяет число повторных попыток
+// регулярного платежа по умолчанию: 3 без настройки и 0, если повторы отключены.
+func TestStandingOrderDefaultRetries(t *testing.T) {
+	noRetries := 0
+	for _, test := range []struct {
+		options Options
+		want    int
+	}{
+		{Options{}, 3},
+		{Options{StandingOrderMaxRetries: &noRetries}, 0},
+	} {
+		s := NewService(memory.New(), test.options)
+		createTestWallets(t, s, 10000, "retries-a", "retries-b")
+		order, err := s.CreateStandingOrder(StandingOrderRequest{
+			TransferRequest: TransferRequest{FromAddress: "retries-a", ToAddress: "retries-b", Amount: "500"},
+			Interval:        time.Hour,
+		})
+		if err != nil {
+			t.Fatalf("Не удалось создать регулярный платёж: %v", err)
+		}
+		if order.MaxRetries != test.want {
+			t.Errorf("Повторных попыток %d, ожидалось %d", order.MaxRetries, test.want)
+		}
+		if test.want != 0 {
+			continue
+		}
+
+		// Без повторных попыток выполнение при недостатке средств сразу пропускается
+		if _, err := s.ExecuteStandingOrders(10); err != nil {
+			t.Fatalf("Не удалось выполнить регулярные платежи: %v", err)
+		}
+		runs, err := s.GetStandingOrderRuns(order.ID)
+		if err != nil || len(runs) != 1 || runs[0].Status != database.StandingOrderRunFailed {
+			t.Errorf("Неверная история выполнения без повторов: %+v, %v", runs, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package business
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"payment_system_api/database"
+)
+
+// streamBacklogPage — число транзакций, читаемых потоком из хранилища за раз.
+const streamBacklogPage = 100
+
+// TransactionEvent — транзакция в потоке новых транзакций.
+type TransactionEvent struct {
+	// ID — идентификатор события. Передав последний полученный ID
+	// в SubscribeTransactions, клиент возобновляет поток без пропусков.
+	ID          string
+	Transaction TransactionResponse
+}
+
+// TransactionStream — поток транзакций, зафиксированных после подписки,
+// и пропущенных транзакций при возобновлении. Поток нужно закрыть методом Close.
+//
+// Транзакции читаются из хранилища в порядке фиксации, поэтому поток
+// получает и переводы, выполненные другими экземплярами сервиса.
+type TransactionStream struct {
+	service *Service
+	address string
+
+	// position — позиция фиксации последней переданной транзакции,
+	// backlog — прочитанные из хранилища, но ещё не переданные транзакции.
+	position database.CommitPosition
+	backlog  []database.Transaction
+
+	// notify получает сигнал, когда этот экземпляр сервиса фиксирует
+	// подходящую транзакцию, чтобы не ждать следующего опроса хранилища.
+	notify chan struct{}
+}
+
+// SubscribeTransactions открывает поток транзакций кошелька address
+// или, если он пуст, всех транзакций.
+//
+// Если lastEventID пуст, поток начинается с транзакций, зафиксированных
+// после подписки. Иначе сначала передаются транзакции, зафиксированные
+// после события lastEventID, в том числе до подписки.
+// Возможные ошибки:
+// - ErrWalletNotFound — кошелёк address не найден
+// - ErrInvalidCursor — lastEventID повреждён или получен не от API
+func (s *Service) SubscribeTransactions(address, lastEventID string) (*TransactionStream, error) {
+	var (
+		position database.CommitPosition
+		err      error
+	)
+	if lastEventID != "" {
+		if position, err = decodeStreamCursor(lastEventID); err != nil {
+			return nil, err
+		}
+	}
+	if address != "" {
+		if _, err := s.findWallet(address); err != nil {
+			return nil, err
+		}
+	}
+	if lastEventID == "" {
+		if position, err = s.store.Transactions().LastCommitPosition(); err != nil {
+			return nil, err
+		}
+	}
+	stream := &TransactionStream{
+		service:  s,
+		address:  address,
+		position: position,
+		notify:   make(chan struct{}, 1),
+	}
+	s.streams.subscribe(stream)
+	return stream, nil
+}
+
+// Next возвращает следующую транзакцию потока, ожидая её при необходимости.
+//
+// Пока новых транзакций нет, хранилище опрашивается с периодом
+// Options.StreamPollInterval и сразу после переводов этого экземпляра сервиса.
+// Возвращает ошибку контекста ctx, если он завершился раньше, и ошибки хранилища.
+func (t *TransactionStream) Next(ctx context.Context) (*TransactionEvent, error) {
+	for len(t.backlog) == 0 {
+		backlog, err := t.service.store.Transactions().FindAfter(t.position, t.address, streamBacklogPage)
+		if err != nil {
+			return nil, err
+		}
+		if len(backlog) > 0 {
+			t.backlog = backlog
+			break
+		}
+
+		timer := time.NewTimer(t.service.options.StreamPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-t.notify:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+
+	transaction := t.backlog[0]
+	t.backlog = t.backlog[1:]
+	t.position = database.CommitPosition{XID: transaction.XID, ID: transaction.ID}
+	return &TransactionEvent{
+		ID:          encodeStreamCursor(t.position),
+		Transaction: newTransactionResponse(transaction),
+	}, nil
+}
+
+// Close закрывает поток.
+func (t *TransactionStream) Close() {
+	t.service.streams.unsubscribe(t)
+}
+
+// streamHub сообщает открытым потокам о транзакциях, зафиксированных
+// этим экземпляром сервиса.
+type streamHub struct {
+	mu      sync.Mutex
+	streams map[*TransactionStream]struct{}
+}
+
+// subscribe добавляет поток t в рассылку.
+func (h *streamHub) subscribe(t *TransactionStream) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.streams == nil {
+		h.streams = make(map[*TransactionStream]struct{})
+	}
+	h.streams[t] = struct{}{}
+}
+
+// unsubscribe исключает поток t из рассылки.
+func (h *streamHub) unsubscribe(t *TransactionStream) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.streams, t)
+}
+
+// publish будит потоки, которым подходят зафиксированные транзакции transactions.
+//
+// Сами транзакции потоки читают из хранилища, поэтому сигнал не блокирует
+// перевод: если поток ещё не обработал предыдущий, новый не нужен.
+func (h *streamHub) publish(transactions ...database.Transaction) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for t := range h.streams {
+		for _, transaction := range transactions {
+			if t.address != "" && t.address != transaction.FromAddress && t.address != transaction.ToAddress {
+				continue
+			}
+			select {
+			case t.notify <- struct{}{}:
+			default:
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// TestSubscribeTransactions проверяет потоки новых транзакций
+// в хранилище в памяти и в базах данных PostgreSQL и SQLite.
+func TestSubscribeTransactions(t *testing.T) {
+	forEachStore(t, testSubscribeTransactions)
+}
+
+// testSubscribeTransactions выполняет проверки TestSubscribeTransactions над сервисом s:
+//   - поток получает транзакции, зафиксированные после подписки, в порядке фиксации;
+//   - поток кошелька получает только его переводы;
+//   - возобновлённый поток получает пропущенные и новые транзакции без пропусков и повторов;
+//   - медленный клиент получает все транзакции, сколько бы их ни накопилось;
+//   - поток получает переводы другого экземпляра сервиса над тем же хранилищем.
+func testSubscribeTransactions(t *testing.T, s *Service) {
+	createTestWallets(t, s, 100000, "stream-a", "stream-b", "stream-c")
+	if _, err := s.SubscribeTransactions("stream-unknown", ""); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("Ожидалась ошибка ErrWalletNotFound, получена %v", err)
+	}
+	if _, err := s.SubscribeTransactions("", "x"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Ожидалась ошибка ErrInvalidCursor, получена %v", err)
+	}
+
+	// Перевод до подписки не попадает в поток без идентификатора события
+	if _, err := s.SendMoney(TransferRequest{FromAddress: "stream-a", ToAddress: "stream-b", Amount: "1"}); err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	all, err := s.SubscribeTransactions("", "")
+	if err != nil {
+		t.Fatalf("Не удалось открыть поток: %v", err)
+	}
+	defer all.Close()
+	wallet, err := s.SubscribeTransactions("stream-c", "")
+	if err != nil {
+		t.Fatalf("Не удалось открыть поток: %v", err)
+	}
+	defer wallet.Close()
+
+	send := func(from, to string) string {
+		t.Helper()
+		response, err := s.SendMoney(TransferRequest{FromAddress: from, ToAddress: to, Amount: "1"})
+		if err != nil {
+			t.Fatalf("Не удалось выполнить перевод: %v", err)
+		}
+		return response.UUID
+	}
+
+	first := send("stream-a", "stream-b")
+	second := send("stream-b", "stream-c")
+	firstEvent := nextStreamEvent(t, all)
+	if firstEvent.Transaction.UUID != first || nextStreamEvent(t, all).Transaction.UUID != second {
+		t.Errorf("Поток всех транзакций получил транзакции не по порядку")
+	}
+	if event := nextStreamEvent(t, wallet); event.Transaction.UUID != second {
+		t.Errorf("Поток кошелька получил чужую транзакцию %+v", event.Transaction)
+	}
+	expectNoStreamEvent(t, wallet)
+
+	// Возобновление после первого события: вторая транзакция читается
+	// из хранилища, третья приходит после подписки
+	resumed, err := s.SubscribeTransactions("", firstEvent.ID)
+	if err != nil {
+		t.Fatalf("Не удалось возобновить поток: %v", err)
+	}
+	defer resumed.Close()
+	third := send("stream-c", "stream-a")
+	for _, want := range []string{second, third} {
+		if event := nextStreamEvent(t, resumed); event.Transaction.UUID != want {
+			t.Errorf("Возобновлённый поток: получена %s, ожидалась %s", event.Transaction.UUID, want)
+		}
+	}
+	expectNoStreamEvent(t, resumed)
+
+	// Медленный клиент: транзакций больше, чем читается из хранилища за раз
+	slow, err := s.SubscribeTransactions("", "")
+	if err != nil {
+		t.Fatalf("Не удалось открыть поток: %v", err)
+	}
+	defer slow.Close()
+	var sent []string
+	for i := 0; i <= streamBacklogPage; i++ {
+		sent = append(sent, send("stream-a", "stream-b"))
+	}
+	for i, want := range sent {
+		if event := nextStreamEvent(t, slow); event.Transaction.UUID != want {
+			t.Fatalf("Медленный поток: транзакция %d %s, ожидалась %s", i, event.Transaction.UUID, want)
+		}
+	}
+
+	// Другой экземпляр сервиса не сообщает потокам этого экземпляра о переводах:
+	// поток находит их, опрашивая хранилище
+	other := NewService(s.store, Options{})
+	polled := NewService(s.store, Options{StreamPollInterval: 10 * time.Millisecond})
+	stream, err := polled.SubscribeTransactions("", "")
+	if err != nil {
+		t.Fatalf("Не удалось открыть поток: %v", err)
+	}
+	defer stream.Close()
+	response, err := other.SendMoney(TransferRequest{FromAddress: "stream-b", ToAddress: "stream-a", Amount: "1"})
+	if err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	if event := nextStreamEvent(t, stream); event.Transaction.UUID != response.UUID {
+		t.Errorf("Поток получил %s, ожидалась транзакция другого экземпляра %s", event.Transaction.UUID, response.UUID)
+	}
+}
+
+// TestSubscribeTransactionsCommitOrder проверяет в PostgreSQL, что поток
+// не пропускает транзакцию, получившую меньший идентификатор, но зафиксированную
+// позже транзакции с большим, в том числе при возобновлении.
+func TestSubscribeTransactionsCommitOrder(t *testing.T) {
+	s := setupTestDB(t)
+	createTestWallets(t, s, 100000, "order-a", "order-b")
+	stream, err := s.SubscribeTransactions("", "")
+	if err != nil {
+		t.Fatalf("Не удалось открыть поток: %v", err)
+	}
+	defer stream.Close()
+
+	// Транзакция A создаёт запись и остаётся открытой
+	created := make(chan uint)
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- s.store.InTransaction(func(tx database.Store) error {
+			transaction := database.Transaction{
+				FromAddress: "order-a", ToAddress: "order-b", Amount: 100, ToAmount: 100,
+				Currency: money.DefaultCurrency, ToCurrency: money.DefaultCurrency,
+			}
+			if err := tx.Transactions().Create(&transaction); err != nil {
+				close(created)
+				return err
+			}
+			created <- transaction.ID
+			<-release
+			return nil
+		})
+	}()
+	earlierID, ok := <-created
+	if !ok {
+		t.Fatalf("Не удалось создать транзакцию: %v", <-done)
+	}
+
+	// Транзакция B получает больший идентификатор и фиксируется первой
+	later, err := s.SendMoney(TransferRequest{FromAddress: "order-b", ToAddress: "order-a", Amount: "1"})
+	if err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	expectNoStreamEvent(t, stream)
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Транзакция не зафиксирована: %v", err)
+	}
+	earlierEvent := nextStreamEvent(t, stream)
+	laterEvent := nextStreamEvent(t, stream)
+	transaction, err := s.store.Transactions().FindByUUID(earlierEvent.Transaction.UUID)
+	if err != nil || transaction.ID != earlierID || laterEvent.Transaction.UUID != later.UUID {
+		t.Fatalf("Поток получил %s и %s, ожидались транзакция %d и %s",
+			earlierEvent.Transaction.UUID, laterEvent.Transaction.UUID, earlierID, later.UUID)
+	}
+
+	resumed, err := s.SubscribeTransactions("", earlierEvent.ID)
+	if err != nil {
+		t.Fatalf("Не удалось возобновить поток: %v", err)
+	}
+	defer resumed.Close()
+	if event := nextStreamEvent(t, resumed); event.Transaction.UUID != later.UUID {
+		t.Errorf("Возобновлённый поток получил %s, ожидалась %s", event.Transaction.UUID, later.UUID)
+	}
+	expectNoStreamEvent(t, resumed)
+}
+
+// nextStreamEvent возвращает следующее событие потока stream, ожидая его не дольше секунды.
+func nextStreamEvent(t *testing.T, stream *TransactionStream) *TransactionEvent {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	event, err := stream.Next(ctx)
+	if err != nil {
+		t.Fatalf("Не удалось получить событие: %v", err)
+	}
+	return event
+}
+
+// expectNoStreamEvent проверяет, что в потоке stream нет событий.
+func expectNoStreamEvent(t *testing.T, stream *TransactionStream) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if event, err := stream.Next(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Ожидалось истечение ожидания, получено %+v, %v", event, err)
+	}
+}
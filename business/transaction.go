@@ -0,0 +1,56 @@
+package business
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"payment_system_api/database"
+)
+
+// maxTransactionAttempts — максимальное число попыток выполнить транзакцию
+// при ошибках сериализации и взаимоблокировках.
+const maxTransactionAttempts = 5
+
+// storeErrors сопоставляет нарушения инвариантов, обнаруженные хранилищем,
+// бизнес-ошибкам. Бизнес-логика проверяет эти инварианты сама, хранилище
+// защищает их на случай ошибки в коде или изменения данных в обход сервиса.
+var storeErrors = map[error]*Error{
+	database.ErrNegativeBalance:   ErrInsufficientFunds,
+	database.ErrNonPositiveAmount: ErrInvalidAmount,
+	database.ErrUnknownWallet:     ErrWalletNotFound,
+
+	database.ErrRefundExceedsAmount: ErrRefundExceedsAmount,
+}
+
+// runInTransaction выполняет fn в транзакции хранилища.
+//
+// Если транзакция прервана из-за ошибки сериализации или взаимоблокировки,
+// она повторяется целиком с небольшой случайной задержкой,
+// но не более maxTransactionAttempts раз; после последней неудачной попытки
+// возвращается ErrConcurrentUpdate. Нарушения инвариантов хранилища
+// возвращаются как соответствующие бизнес-ошибки.
+func (s *Service) runInTransaction(fn func(tx database.Store) error) error {
+	for attempt := 1; ; attempt++ {
+		err := s.store.InTransaction(fn)
+		if !database.IsRetryableError(err) {
+			return translateStoreError(err)
+		}
+		if attempt == maxTransactionAttempts {
+			return ErrConcurrentUpdate
+		}
+		backoff := time.Duration(attempt) * 10 * time.Millisecond
+		time.Sleep(backoff + rand.N(backoff))
+	}
+}
+
+// translateStoreError заменяет нарушение инварианта хранилища бизнес-ошибкой
+// из storeErrors, остальные ошибки возвращает без изменений.
+func translateStoreError(err error) error {
+	for storeErr, businessErr := range storeErrors {
+		if errors.Is(err, storeErr) {
+			return businessErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package business
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// TestStoreInvariants проверяет, что нарушения инвариантов, обнаруженные
+// хранилищем в обход проверок бизнес-логики, возвращаются как бизнес-ошибки
+// и откатывают транзакцию: в хранилище в памяти и в базах данных PostgreSQL и SQLite.
+func TestStoreInvariants(t *testing.T) {
+	forEachStore(t, testStoreInvariants)
+}
+
+// TestRunInTransactionRetries проверяет повтор транзакций, прерванных ошибкой
+// сериализации: успех на повторной попытке и ErrConcurrentUpdate
+// после maxTransactionAttempts неудачных попыток.
+func TestRunInTransactionRetries(t *testing.T) {
+	s := newTestService()
+	serializationFailure := &pgconn.PgError{Code: "40001"}
+
+	attempts := 0
+	err := s.runInTransaction(func(tx database.Store) error {
+		attempts++
+		if attempts == 1 {
+			return serializationFailure
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("Ожидался успех на второй попытке, получено %d попыток, ошибка %v", attempts, err)
+	}
+
+	attempts = 0
+	err = s.runInTransaction(func(tx database.Store) error {
+		attempts++
+		return serializationFailure
+	})
+	if !errors.Is(err, ErrConcurrentUpdate) || attempts != maxTransactionAttempts {
+		t.Errorf("Ожидалась ошибка ErrConcurrentUpdate после %d попыток, получено %d попыток, ошибка %v",
+			maxTransactionAttempts, attempts, err)
+	}
+}
+
+// testStoreInvariants выполняет проверки TestStoreInvariants над сервисом s.
+func testStoreInvariants(t *testing.T, s *Service) {
+	createTestWallets(t, s, 100, "wallet-a", "wallet-b")
+
+	cases := []struct {
+		name string
+		fn   func(tx database.Store) error
+		want error
+	}{
+		{"отрицательный баланс", func(tx database.Store) error {
+			wallet, err := tx.Wallets().LockByAddress("wallet-a")
+			if err != nil {
+				return err
+			}
+			return tx.Wallets().AddBalance(wallet.ID, -101)
+		}, ErrInsufficientFunds},
+		{"резерв больше баланса", func(tx database.Store) error {
+			wallet, err := tx.Wallets().LockByAddress("wallet-a")
+			if err != nil {
+				return err
+			}
+			return tx.Wallets().AddHeld(wallet.ID, 101)
+		}, ErrInsufficientFunds},
+		{"баланс меньше резерва", func(tx database.Store) error {
+			wallet, err := tx.Wallets().LockByAddress("wallet-a")
+			if err != nil {
+				return err
+			}
+			if err := tx.Wallets().AddHeld(wallet.ID, 50); err != nil {
+				return err
+			}
+			return tx.Wallets().AddBalance(wallet.ID, -51)
+		}, ErrInsufficientFunds},
+		{"кошелёк с отрицательным балансом", func(tx database.Store) error {
+			return tx.Wallets().Create(&database.Wallet{Address: "wallet-c", Balance: -1})
+		}, ErrInsufficientFunds},
+		{"нулевая сумма", func(tx database.Store) error {
+			return tx.Transactions().Create(&database.Transaction{
+				FromAddress: "wallet-a", ToAddress: "wallet-b",
+				Currency: money.DefaultCurrency, ToCurrency: money.DefaultCurrency,
+			})
+		}, ErrInvalidAmount},
+		{"несуществующий кошелёк", func(tx database.Store) error {
+			return tx.Transactions().Create(&database.Transaction{
+				FromAddress: "wallet-a", ToAddress: "wallet-unknown", Amount: 1, ToAmount: 1,
+				Currency: money.DefaultCurrency, ToCurrency: money.DefaultCurrency,
+			})
+		}, ErrWalletNotFound},
+		{"возврат больше суммы транзакции", func(tx database.Store) error {
+			transaction := database.Transaction{
+				FromAddress: "wallet-a", ToAddress: "wallet-b", Amount: 10, ToAmount: 10,
+				Currency: money.DefaultCurrency, ToCurrency: money.DefaultCurrency,
+			}
+			if err := tx.Transactions().Create(&transaction); err != nil {
+				return err
+			}
+			return tx.Transactions().AddRefunded(transaction.ID, 11)
+		}, ErrRefundExceedsAmount},
+	}
+	for _, tc := range cases {
+		err := s.runInTransaction(tc.fn)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: ожидалась ошибка %v, получена %v", tc.name, tc.want, err)
+		}
+	}
+
+	if got := balances(t, s); len(got) != 2 || got[0] != 100 || got[1] != 100 {
+		t.Errorf("Балансы не должны измениться, получено %v", got)
+	}
+	transactions, err := s.store.Transactions().Find(database.TransactionQuery{})
+	if err != nil || len(transactions) != 0 {
+		t.Errorf("Транзакции не должны сохраниться, получено %v, %v", transactions, err)
+	}
+}
@@ -1,6 +1,6 @@
 // Package business содержит бизнес-логику платёжной системы.
-// Он предоставляет функции для перевода средств между кошельками,
-// получения баланса и списка последних транзакций.
+// Операции выполняет Service над хранилищем database.Store: перевод средств
+// между кошельками, получение баланса и списка последних транзакций.
 package business
 
 import (
@@ -8,113 +8,388 @@ import (
 	"time"
 
 	"payment_system_api/database"
-
-	"gorm.io/gorm"
+	"payment_system_api/money"
 )
 
+// TransferRequest описывает параметры перевода средств между кошельками.
+type TransferRequest struct {
+	FromAddress string         // адрес отправителя
+	ToAddress   string         // адрес получателя
+	Amount      money.Decimal  // сумма перевода в валюте кошелька отправителя
+	Currency    money.Currency // ожидаемая валюта перевода, пустая строка — валюта кошелька отправителя
+	ToCurrency  money.Currency // валюта зачисления; если отличается от валюты отправителя, перевод выполняется с конвертацией
+
+	batchID *string // пакет, в составе которого выполняется перевод (см. SendBatch)
+	capture bool    // перевод списывает резервирование, лимиты проверены при его создании (см. CaptureHold)
+}
+
 // TransactionResponse представляет транзакцию,
 // возвращаемую в API. В отличие от модели базы данных,
-// сумма хранится в виде float64.
+// сумма представлена десятичной строкой в валюте перевода.
 type TransactionResponse struct {
-	FromAddress string    `json:"from_address"` // адрес отправителя
-	ToAddress   string    `json:"to_address"`   // адрес получателя
-	Amount      float64   `json:"amount"`       // сумма перевода
-	Timestamp   time.Time `json:"timestamp"`    // время создания транзакции
-	UUID        string    `json:"uuid"`         // уникальный идентификатор транзакции
+	FromAddress string         `json:"from_address"` // адрес отправителя
+	ToAddress   string         `json:"to_address"`   // адрес получателя
+	Amount      money.Decimal  `json:"amount"`       // сумма перевода
+	Currency    money.Currency `json:"currency"`     // валюта перевода
+	Timestamp   time.Time      `json:"timestamp"`    // время создания транзакции
+	UUID        string         `json:"uuid"`         // уникальный идентификатор транзакции
+
+	// Поля конвертации, заполняются только для переводов между валютами.
+	ToAmount   money.Decimal  `json:"to_amount,omitempty"`    // сумма зачисления в валюте получателя
+	ToCurrency money.Currency `json:"to_currency,omitempty"`  // валюта зачисления
+	FXRate     string         `json:"fx_rate,omitempty"`      // использованный курс
+	FXQuotedAt *time.Time     `json:"fx_quoted_at,omitempty"` // момент котировки курса
+
+	// Поля возвратов.
+	RefundOf string        `json:"refund_of,omitempty"` // UUID исходной транзакции, если это возврат
+	Refunded money.Decimal `json:"refunded,omitempty"`  // сумма возвратов в валюте перевода, если они были
+	Refunds  []string      `json:"refunds,omitempty"`   // UUID возвратов в порядке создания, заполняется в списках
+
+	BatchID string `json:"batch_id,omitempty"` // идентификатор пакета, если перевод выполнен в составе пакета
+}
+
+// BalanceResponse представляет баланс кошелька, возвращаемый в API.
+type BalanceResponse struct {
+	Address  string         `json:"адрес"`  // адрес кошелька
+	Balance  money.Decimal  `json:"баланс"` // учётный баланс, включая зарезервированные средства
+	Currency money.Currency `json:"валюта"` // валюта кошелька
+
+	Available money.Decimal `json:"доступно"`        // доступный для переводов баланс
+	Held      money.Decimal `json:"зарезервировано"` // сумма активных резервирований
+
+	Status string `json:"статус"` // состояние кошелька: active или frozen
 }
 
 // SendMoney выполняет транзакцию перевода средств с одного кошелька на другой.
 //
-// Проверяет наличие кошельков, достаточность средств и корректность суммы.
-// Все операции выполняются в одной транзакции GORM.
+// Проверяет наличие кошельков, их состояние, совпадение валют, достаточность средств,
+// лимиты расходов отправителя (см. SetWalletLimits) и корректность суммы.
+// Перевод между кошельками в разных валютах выполняется
+// только если в req.ToCurrency явно указана валюта получателя: сумма
+// конвертируется по курсу Options.RateProvider, курс и момент котировки сохраняются
+// в транзакции.
+// Все операции выполняются в одной транзакции хранилища, оба кошелька
+// блокируются до её завершения. В той же транзакции в исходящую очередь
+// записывается событие TransferCompleted (см. PublishEvents) и ставятся
+// в очередь вебхуки о списании и зачислении (см. DeliverWebhooks).
+// После фиксации транзакция передаётся открытым потокам (см. SubscribeTransactions).
+// Возвращает созданную транзакцию.
 // Возможные ошибки:
-// - "кошелек отправителя не найден"
-// - "кошелек получаетеля не найден"
-// - "недостаточно средств"
-// - "сумма должна быть положительной"
-// - "нельзя отправлять деньги на тот же адрес"
-func SendMoney(fromAddress, toAddress string, amount float64) error {
-	amountAsInteger := int64(amount * 100)
-	return database.DB.Transaction(func(tx *gorm.DB) error {
-		var fromWallet, toWallet database.Wallet
-
-		if err := tx.Where("address = ?", fromAddress).First(&fromWallet).Error; err != nil {
-			return errors.New("кошелек отправителя не найден")
-		}
+// - ErrSenderNotFound
+// - ErrRecipientNotFound
+// - ErrSenderFrozen, ErrRecipientFrozen
+// - ErrInsufficientFunds
+// - ErrTransferLimitExceeded, ErrDailyLimitExceeded, ErrMonthlyLimitExceeded,
+// ErrHourlyCountExceeded — с общим кодом LIMIT_EXCEEDED
+// - ErrInvalidAmount
+// - ErrAmountPrecision
+// - ErrSameAddress
+// - ErrCurrencyMismatch
+// - ErrFXUnavailable, ErrRateNotFound, ErrQuoteExpired
+func (s *Service) SendMoney(req TransferRequest) (*TransactionResponse, error) {
+	var transaction database.Transaction
+	err := s.runInTransaction(func(tx database.Store) error {
+		var err error
+		transaction, err = s.transfer(tx, req, nil, "")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.streams.publish(transaction)
+	response := newTransactionResponse(transaction)
+	return &response, nil
+}
 
-		if err := tx.Where("address = ?", toAddress).First(&toWallet).Error; err != nil {
-			return errors.New("кошелек получателя не найден")
-		}
+// transfer переводит средства в рамках уже открытой транзакции tx.
+//
+// idempotencyKey и requestHash сохраняются в записи транзакции,
+// idempotencyKey может быть nil, если ключ идемпотентности не передан.
+func (s *Service) transfer(tx database.Store, req TransferRequest, idempotencyKey *string, requestHash string) (database.Transaction, error) {
+	if req.Amount.Sign() <= 0 {
+		return database.Transaction{}, ErrInvalidAmount
+	}
 
-		// Проверка баланса
-		if fromWallet.Balance < amountAsInteger {
-			return errors.New("недостаточно средств")
-		}
+	if req.FromAddress == req.ToAddress {
+		return database.Transaction{}, ErrSameAddress
+	}
 
-		if amountAsInteger <= 0 {
-			return errors.New("сумма должна быть положительной")
+	fromWallet, toWallet, err := lockWallets(tx, req.FromAddress, req.ToAddress)
+	if err != nil {
+		return database.Transaction{}, err
+	}
+	if err := checkWalletStatus(fromWallet, toWallet); err != nil {
+		return database.Transaction{}, err
+	}
+
+	if req.Currency != "" && req.Currency != fromWallet.Currency {
+		return database.Transaction{}, ErrCurrencyMismatch
+	}
+
+	amount, err := toAmount(req.Amount, fromWallet.Currency)
+	if err != nil {
+		return database.Transaction{}, err
+	}
+
+	transaction := database.Transaction{
+		FromAddress:    req.FromAddress,
+		ToAddress:      req.ToAddress,
+		Amount:         amount,
+		Currency:       fromWallet.Currency,
+		ToAmount:       amount,
+		ToCurrency:     toWallet.Currency,
+		BatchID:        req.batchID,
+		IdempotencyKey: idempotencyKey,
+		RequestHash:    requestHash,
+	}
+
+	if req.ToCurrency != "" && req.ToCurrency != toWallet.Currency {
+		return database.Transaction{}, ErrCurrencyMismatch
+	}
+
+	// Переводы между валютами выполняются только по явному запросу конвертации
+	if fromWallet.Currency != toWallet.Currency {
+		if req.ToCurrency == "" {
+			return database.Transaction{}, ErrCurrencyMismatch
 		}
+		if err := s.applyConversion(&transaction); err != nil {
+			return database.Transaction{}, err
+		}
+	}
 
-		if fromAddress == toAddress {
-			return errors.New("нельзя отправлять деньги на тот же адрес")
+	// Проверка баланса: зарезервированные средства недоступны для переводов
+	if fromWallet.Balance-fromWallet.Held < amount {
+		return database.Transaction{}, ErrInsufficientFunds
+	}
+
+	// Лимиты расходов проверяются под блокировкой кошелька отправителя
+	if !req.capture {
+		if err := checkLimits(tx, fromWallet, amount); err != nil {
+			return database.Transaction{}, err
 		}
+	}
+
+	if err := commitTransfer(tx, &transaction, fromWallet, toWallet); err != nil {
+		return database.Transaction{}, err
+	}
+	return transaction, nil
+}
 
-		// Обновление баланса
-		fromWallet.Balance -= amountAsInteger
-		toWallet.Balance += amountAsInteger
+// commitTransfer сохраняет проверенный перевод transaction между заблокированными
+// кошельками fromWallet и toWallet: запись транзакции, проводки главной книги,
+// событие TransferCompleted и вебхуки.
+func commitTransfer(tx database.Store, transaction *database.Transaction, fromWallet, toWallet *database.Wallet) error {
+	// Запись транзакции
+	if err := tx.Transactions().Create(transaction); err != nil {
+		return err
+	}
+
+	// Проводки главной книги, обновляющие балансы кошельков
+	if err := postTransfer(tx, transaction, fromWallet, toWallet); err != nil {
+		return err
+	}
+
+	// Событие для других сервисов, публикуется из исходящей очереди
+	if err := recordTransferCompleted(tx, *transaction); err != nil {
+		return err
+	}
 
-		if err := tx.Save(&fromWallet).Error; err != nil {
+	// Вебхуки кошельков отправителя и получателя
+	return enqueueWebhooks(tx, *transaction)
+}
+
+// postTransfer записывает в главную книгу проводки перевода transaction
+// и применяет их к балансам кошельков fromWallet и toWallet.
+//
+// Перевод в одной валюте — дебет счёта отправителя и кредит счёта получателя.
+// Перевод с конвертацией проходит через клиринговые счета конвертации,
+// чтобы запись журнала была сбалансирована в каждой из валют.
+func postTransfer(tx database.Store, transaction *database.Transaction, fromWallet, toWallet *database.Wallet) error {
+	fromAccount, err := walletAccount(tx, fromWallet)
+	if err != nil {
+		return err
+	}
+	toAccount, err := walletAccount(tx, toWallet)
+	if err != nil {
+		return err
+	}
+
+	lines := []ledgerLine{
+		{account: fromAccount, amount: -transaction.Amount},
+		{account: toAccount, amount: transaction.ToAmount},
+	}
+	if transaction.Currency != transaction.ToCurrency {
+		fxFrom, err := tx.Ledger().SystemAccount(database.AccountKindFX, transaction.Currency)
+		if err != nil {
 			return err
 		}
-		if err := tx.Save(&toWallet).Error; err != nil {
+		fxTo, err := tx.Ledger().SystemAccount(database.AccountKindFX, transaction.ToCurrency)
+		if err != nil {
 			return err
 		}
+		lines = append(lines,
+			ledgerLine{account: fxFrom, amount: transaction.Amount},
+			ledgerLine{account: fxTo, amount: -transaction.ToAmount},
+		)
+	}
+
+	entry := database.JournalEntry{TransactionID: &transaction.ID, Description: "перевод " + transaction.UUID}
+	return postEntry(tx, &entry, lines)
+}
+
+// applyConversion заполняет сумму зачисления, курс и момент котировки транзакции
+// по актуальной котировке обмена transaction.Currency на transaction.ToCurrency.
+func (s *Service) applyConversion(transaction *database.Transaction) error {
+	quote, err := s.currentQuote(transaction.Currency, transaction.ToCurrency)
+	if err != nil {
+		return err
+	}
+	toAmount, err := money.Convert(transaction.Amount, transaction.Currency, quote.Rate, transaction.ToCurrency)
+	if err != nil {
+		return ErrInvalidAmount
+	}
+	if toAmount <= 0 {
+		return ErrInvalidAmount
+	}
+	transaction.ToAmount = toAmount
+	transaction.FXRate = quote.RateString()
+	transaction.FXQuotedAt = &quote.QuotedAt
+	return nil
+}
 
-		// Запись транзакции
-		transaction := database.Transaction{
-			FromAddress: fromAddress,
-			ToAddress:   toAddress,
-			Amount:      amountAsInteger,
+// toAmount переводит десятичную сумму в минимальные единицы валюты currency,
+// заменяя ошибки пакета money бизнес-ошибками.
+func toAmount(value money.Decimal, currency money.Currency) (money.Amount, error) {
+	amount, err := value.Amount(currency)
+	switch {
+	case errors.Is(err, money.ErrTooManyDecimals):
+		return 0, ErrAmountPrecision
+	case err != nil:
+		return 0, ErrInvalidAmount
+	}
+	return amount, nil
+}
+
+// lockWallets загружает кошельки отправителя и получателя с блокировкой
+// до конца транзакции tx.
+//
+// Блокировки берутся в порядке возрастания адресов, поэтому встречные переводы
+// между одной и той же парой кошельков не могут взаимно заблокироваться.
+func lockWallets(tx database.Store, fromAddress, toAddress string) (fromWallet, toWallet *database.Wallet, err error) {
+	if fromAddress < toAddress {
+		if fromWallet, err = lockWallet(tx, fromAddress, ErrSenderNotFound); err == nil {
+			toWallet, err = lockWallet(tx, toAddress, ErrRecipientNotFound)
 		}
-		if err := tx.Create(&transaction).Error; err != nil {
-			return err
+	} else {
+		if toWallet, err = lockWallet(tx, toAddress, ErrRecipientNotFound); err == nil {
+			fromWallet, err = lockWallet(tx, fromAddress, ErrSenderNotFound)
 		}
+	}
+	return fromWallet, toWallet, err
+}
 
-		return nil
-	})
+// lockWallet загружает кошелек по адресу с блокировкой.
+// Если кошелек не найден, возвращает notFound.
+func lockWallet(tx database.Store, address string, notFound error) (*database.Wallet, error) {
+	wallet, err := tx.Wallets().LockByAddress(address)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, notFound
+	}
+	return wallet, err
 }
 
-// GetWalletBalance возвращает текущий баланс кошелька по адресу.
+// checkWalletStatus проверяет, что кошелёк fromWallet может отправить перевод,
+// а кошелёк toWallet — получить его.
 //
-// Баланс возвращается в виде float64.
-// Возвращает ошибку, если кошелек не найден.
-func GetWalletBalance(address string) (float64, error) {
-	var wallet database.Wallet
-	if err := database.DB.Where("address = ?", address).First(&wallet).Error; err != nil {
-		return 0, err
+// Замороженный кошелёк не отправляет переводы, а с блокировкой входящих
+// переводов — и не получает их.
+func checkWalletStatus(fromWallet, toWallet *database.Wallet) error {
+	if fromWallet.Status == database.WalletFrozen {
+		return ErrSenderFrozen
+	}
+	if toWallet.Status == database.WalletFrozen && toWallet.BlockIncoming {
+		return ErrRecipientFrozen
 	}
-	return float64(wallet.Balance) / 100, nil
+	return nil
+}
+
+// GetWalletBalance возвращает учётный и доступный баланс кошелька по адресу.
+//
+// Возвращает ErrWalletNotFound, если кошелек не найден.
+func (s *Service) GetWalletBalance(address string) (*BalanceResponse, error) {
+	wallet, err := s.findWallet(address)
+	if err != nil {
+		return nil, err
+	}
+	return &BalanceResponse{
+		Address:  wallet.Address,
+		Balance:  wallet.Balance.Decimal(wallet.Currency),
+		Currency: wallet.Currency,
+
+		Available: (wallet.Balance - wallet.Held).Decimal(wallet.Currency),
+		Held:      wallet.Held.Decimal(wallet.Currency),
+
+		Status: wallet.Status,
+	}, nil
+}
+
+// TransactionPage — страница списка транзакций.
+type TransactionPage struct {
+	Transactions []TransactionResponse `json:"transactions"`          // транзакции страницы
+	NextCursor   string                `json:"next_cursor,omitempty"` // курсор следующей страницы, пустой на последней
 }
 
-// GetLastTransactions возвращает последние N транзакций,
+// GetLastTransactions возвращает страницу из не более чем count транзакций,
+// отсортированных по времени создания в порядке убывания.
 //
-// отсортированные по времени создания в порядке убывания.
-func GetLastTransactions(count int) ([]TransactionResponse, error) {
-	var transactionsDB []database.Transaction
-	if err := database.DB.Order("timestamp desc").Limit(count).Find(&transactionsDB).Error; err != nil {
+// Пустой cursor означает первую страницу, иначе — курсор NextCursor предыдущей страницы.
+// count ограничивается MaxTransactionPageSize.
+// Возможные ошибки:
+// - ErrInvalidCursor
+func (s *Service) GetLastTransactions(count int, cursor string) (*TransactionPage, error) {
+	transactionsDB, next, err := s.findTransactionPage(database.TransactionQuery{}, cursor, count)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err := s.findRefunds(transactionsDB)
+	if err != nil {
 		return nil, err
 	}
 
-	var transactionsAPI []TransactionResponse
+	page := &TransactionPage{Transactions: make([]TransactionResponse, 0, len(transactionsDB)), NextCursor: next}
 	for _, t := range transactionsDB {
-		transactionsAPI = append(transactionsAPI, TransactionResponse{
-			FromAddress: t.FromAddress,
-			ToAddress:   t.ToAddress,
-			Amount:      float64(t.Amount) / 100,
-			Timestamp:   t.Timestamp,
-			UUID:        t.UUID,
-		})
+		response := newTransactionResponse(t)
+		response.Refunds = refunds[t.UUID]
+		page.Transactions = append(page.Transactions, response)
 	}
+	return page, nil
+}
 
-	return transactionsAPI, nil
+// newTransactionResponse преобразует модель транзакции базы данных в ответ API.
+func newTransactionResponse(t database.Transaction) TransactionResponse {
+	response := TransactionResponse{
+		FromAddress: t.FromAddress,
+		ToAddress:   t.ToAddress,
+		Amount:      t.Amount.Decimal(t.Currency),
+		Currency:    t.Currency,
+		Timestamp:   t.Timestamp,
+		UUID:        t.UUID,
+	}
+	if t.FXRate != "" {
+		response.ToAmount = t.ToAmount.Decimal(t.ToCurrency)
+		response.ToCurrency = t.ToCurrency
+		response.FXRate = t.FXRate
+		response.FXQuotedAt = t.FXQuotedAt
+	}
+	if t.RefundOf != nil {
+		response.RefundOf = *t.RefundOf
+	}
+	if t.Refunded > 0 {
+		response.Refunded = t.Refunded.Decimal(t.Currency)
+	}
+	if t.BatchID != nil {
+		response.BatchID = *t.BatchID
+	}
+	return response
 }
@@ -0,0 +1,254 @@
+package business
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// MaxWalletPageSize — максимальный размер страницы списка кошельков.
+const MaxWalletPageSize = 100
+
+// WalletResponse представляет полные сведения о кошельке, возвращаемые в API.
+type WalletResponse struct {
+	Address   string         `json:"address"`    // адрес кошелька
+	Currency  money.Currency `json:"currency"`   // валюта кошелька
+	Balance   money.Decimal  `json:"balance"`    // учётный баланс, включая зарезервированные средства
+	Available money.Decimal  `json:"available"`  // доступный для переводов баланс
+	Held      money.Decimal  `json:"held"`       // сумма активных резервирований
+	CreatedAt time.Time      `json:"created_at"` // время открытия кошелька
+	UpdatedAt time.Time      `json:"updated_at"` // время последнего изменения
+
+	Status        string `json:"status"`         // состояние кошелька: active, frozen или closed
+	BlockIncoming bool   `json:"block_incoming"` // заморожены ли и входящие переводы
+}
+
+// WalletStatusRequest описывает изменение состояния кошелька.
+type WalletStatusRequest struct {
+	Reason        string // причина изменения
+	Actor         string // кто изменил состояние
+	BlockIncoming bool   // при заморозке: блокировать и входящие переводы
+}
+
+// WalletStatusChangeResponse — запись истории изменений состояния кошелька.
+type WalletStatusChangeResponse struct {
+	FromStatus    string    `json:"from_status"`    // прежнее состояние
+	ToStatus      string    `json:"to_status"`      // новое состояние
+	BlockIncoming bool      `json:"block_incoming"` // блокировка входящих переводов после изменения
+	Reason        string    `json:"reason"`         // причина изменения
+	Actor         string    `json:"actor"`          // кто изменил состояние
+	CreatedAt     time.Time `json:"created_at"`     // момент изменения
+}
+
+// WalletPage — страница списка кошельков.
+type WalletPage struct {
+	Wallets  []WalletResponse `json:"wallets"`   // кошельки на странице
+	Page     int              `json:"page"`      // номер страницы, начиная с 1
+	PageSize int              `json:"page_size"` // размер страницы
+	Total    int64            `json:"total"`     // общее число открытых кошельков
+}
+
+// CreateWallet открывает новый кошелёк в валюте currency с нулевым балансом.
+//
+// Пустая валюта означает money.DefaultCurrency. Вместе с кошельком
+// открывается его счёт в главной книге.
+func (s *Service) CreateWallet(currency money.Currency) (*WalletResponse, error) {
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	address, err := database.GenerateWalletAddress()
+	if err != nil {
+		return nil, err
+	}
+	wallet := database.Wallet{Address: address, Currency: currency}
+	err = s.runInTransaction(func(tx database.Store) error {
+		if err := tx.Wallets().Create(&wallet); err != nil {
+			return err
+		}
+		_, err := walletAccount(tx, &wallet)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := newWalletResponse(wallet)
+	return &response, nil
+}
+
+// GetWallet возвращает сведения об открытом кошельке по адресу.
+//
+// Возвращает ErrWalletNotFound, если кошелек не найден или закрыт.
+func (s *Service) GetWallet(address string) (*WalletResponse, error) {
+	wallet, err := s.findWallet(address)
+	if err != nil {
+		return nil, err
+	}
+	response := newWalletResponse(*wallet)
+	return &response, nil
+}
+
+// findWallet возвращает открытый кошелёк по адресу или ErrWalletNotFound.
+func (s *Service) findWallet(address string) (*database.Wallet, error) {
+	wallet, err := s.store.Wallets().FindByAddress(address)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrWalletNotFound
+	}
+	return wallet, err
+}
+
+// ListWallets возвращает страницу page размера pageSize списка открытых кошельков,
+// упорядоченного по времени открытия.
+func (s *Service) ListWallets(page, pageSize int) (*WalletPage, error) {
+	result := &WalletPage{Wallets: []WalletResponse{}, Page: page, PageSize: pageSize}
+	total, err := s.store.Wallets().Count()
+	if err != nil {
+		return nil, err
+	}
+	result.Total = total
+
+	wallets, err := s.store.Wallets().List((page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, wallet := range wallets {
+		result.Wallets = append(result.Wallets, newWalletResponse(wallet))
+	}
+	return result, nil
+}
+
+// CloseWallet закрывает кошелёк по адресу.
+//
+// Кошелёк помечается закрытым и перестаёт участвовать в переводах,
+// его история сохраняется. Закрыть можно только кошелёк с нулевым балансом.
+// Причина и исполнитель req необязательны и сохраняются в истории состояний.
+// Возможные ошибки:
+// - ErrWalletNotFound
+// - ErrWalletNotEmpty
+func (s *Service) CloseWallet(address string, req WalletStatusRequest) error {
+	return s.runInTransaction(func(tx database.Store) error {
+		wallet, err := lockWallet(tx, address, ErrWalletNotFound)
+		if err != nil {
+			return err
+		}
+		if wallet.Balance != 0 {
+			return ErrWalletNotEmpty
+		}
+		if err := setWalletStatus(tx, wallet, database.WalletClosed, false, req); err != nil {
+			return err
+		}
+		return tx.Wallets().Close(wallet)
+	})
+}
+
+// FreezeWallet замораживает кошелёк по адресу.
+//
+// Замороженный кошелёк не может отправлять переводы, а при req.BlockIncoming —
+// и получать их. Повторная заморозка меняет блокировку входящих переводов.
+// Возможные ошибки:
+// - ErrInvalidStatusChange — не указаны причина или исполнитель
+// - ErrWalletNotFound
+func (s *Service) FreezeWallet(address string, req WalletStatusRequest) (*WalletResponse, error) {
+	return s.changeWalletStatus(address, database.WalletFrozen, req.BlockIncoming, req)
+}
+
+// UnfreezeWallet снимает заморозку с кошелька по адресу.
+//
+// Возможные ошибки:
+// - ErrInvalidStatusChange — не указаны причина или исполнитель
+// - ErrWalletNotFound
+// - ErrWalletNotFrozen
+func (s *Service) UnfreezeWallet(address string, req WalletStatusRequest) (*WalletResponse, error) {
+	return s.changeWalletStatus(address, database.WalletActive, false, req)
+}
+
+// changeWalletStatus переводит открытый кошелёк в состояние status:
+// замораживает его или снимает заморозку.
+func (s *Service) changeWalletStatus(address, status string, blockIncoming bool, req WalletStatusRequest) (*WalletResponse, error) {
+	if strings.TrimSpace(req.Reason) == "" || strings.TrimSpace(req.Actor) == "" {
+		return nil, ErrInvalidStatusChange
+	}
+	var wallet *database.Wallet
+	err := s.runInTransaction(func(tx database.Store) error {
+		var err error
+		wallet, err = lockWallet(tx, address, ErrWalletNotFound)
+		if err != nil {
+			return err
+		}
+		if status == database.WalletActive && wallet.Status != database.WalletFrozen {
+			return ErrWalletNotFrozen
+		}
+		return setWalletStatus(tx, wallet, status, blockIncoming, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := newWalletResponse(*wallet)
+	return &response, nil
+}
+
+// setWalletStatus сохраняет новое состояние кошелька и запись о его изменении.
+func setWalletStatus(tx database.Store, wallet *database.Wallet, status string, blockIncoming bool, req WalletStatusRequest) error {
+	change := database.WalletStatusChange{
+		WalletAddress: wallet.Address,
+		FromStatus:    wallet.Status,
+		ToStatus:      status,
+		BlockIncoming: blockIncoming,
+		Reason:        req.Reason,
+		Actor:         req.Actor,
+	}
+	if err := tx.Wallets().RecordStatusChange(&change); err != nil {
+		return err
+	}
+	wallet.Status = status
+	wallet.BlockIncoming = blockIncoming
+	return tx.Wallets().UpdateStatus(wallet)
+}
+
+// GetWalletStatusHistory возвращает историю изменений состояния кошелька,
+// в том числе закрытого, от старых к новым.
+//
+// Возвращает ErrWalletNotFound, если кошелёк с таким адресом не открывался.
+func (s *Service) GetWalletStatusHistory(address string) ([]WalletStatusChangeResponse, error) {
+	_, err := s.store.Wallets().FindByAddressUnscoped(address)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.store.Wallets().FindStatusChanges(address)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]WalletStatusChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, WalletStatusChangeResponse{
+			FromStatus:    change.FromStatus,
+			ToStatus:      change.ToStatus,
+			BlockIncoming: change.BlockIncoming,
+			Reason:        change.Reason,
+			Actor:         change.Actor,
+			CreatedAt:     change.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// newWalletResponse преобразует модель кошелька базы данных в ответ API.
+func newWalletResponse(w database.Wallet) WalletResponse {
+	return WalletResponse{
+		Address:   w.Address,
+		Currency:  w.Currency,
+		Balance:   w.Balance.Decimal(w.Currency),
+		Available: (w.Balance - w.Held).Decimal(w.Currency),
+		Held:      w.Held.Decimal(w.Currency),
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+
+		Status:        w.Status,
+		BlockIncoming: w.BlockIncoming,
+	}
+}
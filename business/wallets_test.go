@@ -0,0 +1,188 @@
+package business
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestWalletLifecycle проверяет открытие, получение, список и закрытие кошельков.
+//
+// Кошелёк с ненулевым балансом закрыть нельзя, закрытый кошелёк
+// не возвращается и не участвует в переводах.
+func TestWalletLifecycle(t *testing.T) {
+	s := newTestService()
+
+	usd, err := s.CreateWallet("USD")
+	if err != nil {
+		t.Fatalf("Не удалось открыть кошелек: %v", err)
+	}
+	if len(usd.Address) != 64 || usd.Currency != "USD" || usd.Balance != "0.00" {
+		t.Errorf("Неверные сведения о новом кошельке: %+v", usd)
+	}
+	rub, err := s.CreateWallet("")
+	if err != nil {
+		t.Fatalf("Не удалось открыть кошелек: %v", err)
+	}
+	if rub.Currency != "RUB" {
+		t.Errorf("Ожидалась валюта по умолчанию RUB, получена %s", rub.Currency)
+	}
+
+	got, err := s.GetWallet(usd.Address)
+	if err != nil || got.Address != usd.Address {
+		t.Fatalf("Не удалось получить кошелек: %+v, %v", got, err)
+	}
+
+	page, err := s.ListWallets(1, 1)
+	if err != nil {
+		t.Fatalf("Не удалось получить список кошельков: %v", err)
+	}
+	if page.Total != 2 || len(page.Wallets) != 1 || page.Wallets[0].Address != usd.Address {
+		t.Errorf("Неверная первая страница: %+v", page)
+	}
+
+	wallet, err := s.store.Wallets().FindByAddress(rub.Address)
+	if err != nil {
+		t.Fatalf("Не удалось найти кошелек: %v", err)
+	}
+	if err := s.store.Wallets().AddBalance(wallet.ID, 100); err != nil {
+		t.Fatalf("Не удалось изменить баланс: %v", err)
+	}
+	if err := s.CloseWallet(rub.Address, WalletStatusRequest{}); !errors.Is(err, ErrWalletNotEmpty) {
+		t.Errorf("Ожидалась ошибка ErrWalletNotEmpty, получена %v", err)
+	}
+
+	if err := s.CloseWallet(usd.Address, WalletStatusRequest{}); err != nil {
+		t.Fatalf("Не удалось закрыть кошелек: %v", err)
+	}
+	if _, err := s.GetWallet(usd.Address); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("Закрытый кошелек не должен находиться, получена ошибка %v", err)
+	}
+	if err := s.CloseWallet(usd.Address, WalletStatusRequest{}); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("Повторное закрытие должно вернуть ErrWalletNotFound, получена %v", err)
+	}
+}
+
+// TestWalletStatus проверяет заморозку кошельков в хранилище в памяти
+// и в базах данных PostgreSQL и SQLite.
+func TestWalletStatus(t *testing.T) {
+	forEachStore(t, testWalletStatus)
+}
+
+// testWalletStatus выполняет проверки TestWalletStatus над сервисом s:
+//   - без причины или исполнителя состояние не меняется;
+//   - замороженный кошелёк не отправляет переводы, возвраты и резервирования,
+//     но получает переводы, пока не заблокированы входящие;
+//   - после разморозки переводы выполняются;
+//   - каждое изменение состояния, включая закрытие, попадает в историю.
+func testWalletStatus(t *testing.T, s *Service) {
+	createTestWallets(t, s, 10000, "status-a", "status-b")
+	send := func(from, to string) error {
+		_, err := s.SendMoney(TransferRequest{FromAddress: from, ToAddress: to, Amount: "1"})
+		return err
+	}
+
+	payment, err := s.SendMoney(TransferRequest{FromAddress: "status-a", ToAddress: "status-b", Amount: "10"})
+	if err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+
+	if _, err := s.FreezeWallet("status-a", WalletStatusRequest{Reason: "подозрение на взлом"}); !errors.Is(err, ErrInvalidStatusChange) {
+		t.Errorf("Ожидалась ошибка ErrInvalidStatusChange, получена %v", err)
+	}
+	if _, err := s.FreezeWallet("status-unknown", WalletStatusRequest{Reason: "взлом", Actor: "admin"}); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("Ожидалась ошибка ErrWalletNotFound, получена %v", err)
+	}
+	if _, err := s.UnfreezeWallet("status-a", WalletStatusRequest{Reason: "проверка", Actor: "admin"}); !errors.Is(err, ErrWalletNotFrozen) {
+		t.Errorf("Ожидалась ошибка ErrWalletNotFrozen, получена %v", err)
+	}
+
+	wallet, err := s.FreezeWallet("status-b", WalletStatusRequest{Reason: "подозрение на взлом", Actor: "admin"})
+	if err != nil {
+		t.Fatalf("Не удалось заморозить кошелек: %v", err)
+	}
+	if wallet.Status != "frozen" || wallet.BlockIncoming {
+		t.Errorf("Неверное состояние замороженного кошелька: %+v", wallet)
+	}
+	if balance, err := s.GetWalletBalance("status-b"); err != nil || balance.Status != "frozen" {
+		t.Errorf("Баланс должен содержать состояние frozen: %+v, %v", balance, err)
+	}
+	if err := send("status-b", "status-a"); !errors.Is(err, ErrSenderFrozen) {
+		t.Errorf("Ожидалась ошибка ErrSenderFrozen, получена %v", err)
+	}
+	if _, err := s.CreateHold(HoldRequest{WalletAddress: "status-b", ToAddress: "status-a", Amount: "1"}); !errors.Is(err, ErrSenderFrozen) {
+		t.Errorf("Резервирование: ожидалась ошибка ErrSenderFrozen, получена %v", err)
+	}
+	// Возврат списывается с получателя исходного перевода
+	if _, err := s.RefundTransaction(payment.UUID, nil); !errors.Is(err, ErrSenderFrozen) {
+		t.Errorf("Возврат: ожидалась ошибка ErrSenderFrozen, получена %v", err)
+	}
+	if err := send("status-a", "status-b"); err != nil {
+		t.Errorf("Входящие переводы не заблокированы, получена ошибка %v", err)
+	}
+
+	if _, err := s.FreezeWallet("status-b", WalletStatusRequest{Reason: "подтверждён взлом", Actor: "security", BlockIncoming: true}); err != nil {
+		t.Fatalf("Не удалось заблокировать входящие переводы: %v", err)
+	}
+	if err := send("status-a", "status-b"); !errors.Is(err, ErrRecipientFrozen) {
+		t.Errorf("Ожидалась ошибка ErrRecipientFrozen, получена %v", err)
+	}
+	if _, err := s.ScheduleTransfer(ScheduledTransferRequest{
+		TransferRequest: TransferRequest{FromAddress: "status-a", ToAddress: "status-b", Amount: "1"},
+		ExecuteAt:       time.Now().Add(time.Hour),
+	}); !errors.Is(err, ErrRecipientFrozen) {
+		t.Errorf("Запланированный перевод: ожидалась ошибка ErrRecipientFrozen, получена %v", err)
+	}
+
+	wallet, err = s.UnfreezeWallet("status-b", WalletStatusRequest{Reason: "доступ восстановлен", Actor: "security"})
+	if err != nil {
+		t.Fatalf("Не удалось разморозить кошелек: %v", err)
+	}
+	if wallet.Status != "active" || wallet.BlockIncoming {
+		t.Errorf("Неверное состояние размороженного кошелька: %+v", wallet)
+	}
+	if err := send("status-b", "status-a"); err != nil {
+		t.Errorf("Перевод после разморозки: %v", err)
+	}
+	if err := send("status-a", "status-b"); err != nil {
+		t.Errorf("Перевод после разморозки: %v", err)
+	}
+	if got := balances(t, s); got[0] != 8900 || got[1] != 11100 {
+		t.Errorf("Неверные балансы: %v", got)
+	}
+
+	createTestWallets(t, s, 0, "status-c")
+	if err := s.CloseWallet("status-c", WalletStatusRequest{Reason: "по заявлению", Actor: "client"}); err != nil {
+		t.Fatalf("Не удалось закрыть кошелек: %v", err)
+	}
+	history, err := s.GetWalletStatusHistory("status-c")
+	if err != nil || len(history) != 1 || history[0].FromStatus != "active" || history[0].ToStatus != "closed" || history[0].Actor != "client" {
+		t.Errorf("Неверная история закрытого кошелька: %+v, %v", history, err)
+	}
+
+	history, err = s.GetWalletStatusHistory("status-b")
+	if err != nil {
+		t.Fatalf("Не удалось получить историю состояний: %v", err)
+	}
+	want := []WalletStatusChangeResponse{
+		{FromStatus: "active", ToStatus: "frozen", Reason: "подозрение на взлом", Actor: "admin"},
+		{FromStatus: "frozen", ToStatus: "frozen", BlockIncoming: true, Reason: "подтверждён взлом", Actor: "security"},
+		{FromStatus: "frozen", ToStatus: "active", Reason: "доступ восстановлен", Actor: "security"},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("Ожидалось %d изменений состояния, получено %+v", len(want), history)
+	}
+	for i, change := range history {
+		change.CreatedAt = time.Time{}
+		if change != want[i] {
+			t.Errorf("Изменение %d: ожидалось %+v, получено %+v", i, want[i], change)
+		}
+	}
+	if _, err := s.GetWalletStatusHistory("status-unknown"); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("Ожидалась ошибка ErrWalletNotFound, получена %v", err)
+	}
+	report, err := s.VerifyLedger()
+	if err != nil || !report.Balanced {
+		t.Errorf("Главная книга не сбалансирована: %+v, %v", report, err)
+	}
+}
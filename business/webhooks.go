@@ -0,0 +1,392 @@
+package business
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"payment_system_api/database"
+)
+
+// Типы событий, о которых сообщают вебхуки.
+const (
+	WebhookEventDebited  = "wallet.debited"  // средства списаны с кошелька
+	WebhookEventCredited = "wallet.credited" // средства зачислены на кошелёк
+)
+
+// Заголовки запроса вебхука.
+const (
+	// WebhookSignatureHeader содержит подпись "sha256=<hex>", см. SignWebhook.
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// WebhookTimestampHeader содержит момент отправки в секундах Unix, входящий в подпись.
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	// WebhookDeliveryHeader содержит идентификатор доставки; он не меняется
+	// при повторных попытках, по нему получатель может отбрасывать дубликаты.
+	WebhookDeliveryHeader = "X-Webhook-Delivery"
+	// WebhookEventHeader содержит тип события.
+	WebhookEventHeader = "X-Webhook-Event"
+)
+
+// MaxDeliveryPageSize — максимальный размер страницы списка доставок вебхуков.
+const MaxDeliveryPageSize = 100
+
+// maxWebhookBackoff — максимальная задержка между попытками доставки вебхука.
+const maxWebhookBackoff = time.Hour
+
+// webhookClaimLease — время, на которое выбранная для отправки доставка
+// скрывается от других процессов. Если процесс завершится, не сохранив
+// результат попытки, доставка будет отправлена повторно по его истечении.
+const webhookClaimLease = 5 * time.Minute
+
+// WebhookResponse представляет вебхук, возвращаемый в API.
+type WebhookResponse struct {
+	ID        uint      `json:"id"`               // идентификатор вебхука
+	URL       string    `json:"url"`              // адрес получателя
+	Wallet    string    `json:"wallet,omitempty"` // кошелёк подписки, пусто — все кошельки
+	Secret    string    `json:"secret,omitempty"` // ключ подписи, возвращается только при регистрации
+	CreatedAt time.Time `json:"created_at"`       // время регистрации
+}
+
+// WebhookPayload — тело запроса вебхука.
+type WebhookPayload struct {
+	Event       string              `json:"event"`       // тип события: WebhookEventDebited или WebhookEventCredited
+	Wallet      string              `json:"wallet"`      // кошелёк, с которым произошло событие
+	Transaction TransactionResponse `json:"transaction"` // перевод, вызвавший событие
+}
+
+// DeliveryFilter задаёт выборку доставок вебхуков.
+type DeliveryFilter struct {
+	WebhookID uint   // доставки вебхука, 0 — всех вебхуков
+	Status    string // доставки в состоянии Status, пустая строка — в любом
+}
+
+// DeliveryResponse представляет доставку вебхука, возвращаемую в API.
+type DeliveryResponse struct {
+	ID             uint            `json:"id"`                        // идентификатор доставки
+	WebhookID      uint            `json:"webhook_id"`                // вебхук доставки
+	Event          string          `json:"event"`                     // тип события
+	Wallet         string          `json:"wallet"`                    // кошелёк события
+	Status         string          `json:"status"`                    // pending, delivered или dead
+	Attempts       int             `json:"attempts"`                  // число выполненных попыток
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"` // время следующей попытки для ожидающих доставок
+	LastError      string          `json:"last_error,omitempty"`      // ошибка последней неудачной попытки
+	ResponseStatus int             `json:"response_status,omitempty"` // HTTP-код последнего ответа получателя
+	CreatedAt      time.Time       `json:"created_at"`                // время возникновения события
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`    // время успешной доставки
+	Payload        json.RawMessage `json:"payload"`                   // тело запроса
+}
+
+// DeliveryPage — страница списка доставок вебхуков.
+type DeliveryPage struct {
+	Deliveries []DeliveryResponse `json:"deliveries"` // доставки на странице, от новых к старым
+	Page       int                `json:"page"`       // номер страницы, начиная с 1
+	PageSize   int                `json:"page_size"`  // размер страницы
+	Total      int64              `json:"total"`      // общее число доставок, выбранных фильтром
+}
+
+// CreateWebhook регистрирует вебхук с адресом rawURL для событий кошелька
+// walletAddress или, если он пуст, для событий всех кошельков.
+//
+// Возвращает вебхук вместе с ключом подписи; позже ключ не возвращается.
+// Возможные ошибки:
+// - ErrInvalidWebhookURL
+// - ErrWalletNotFound
+func (s *Service) CreateWebhook(rawURL, walletAddress string) (*WebhookResponse, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении случайных байт: %w", err)
+	}
+
+	webhook := database.Webhook{URL: rawURL, Secret: hex.EncodeToString(secret)}
+	if walletAddress != "" {
+		if _, err := s.findWallet(walletAddress); err != nil {
+			return nil, err
+		}
+		webhook.WalletAddress = &walletAddress
+	}
+	if err := s.runInTransaction(func(tx database.Store) error {
+		return tx.Webhooks().Create(&webhook)
+	}); err != nil {
+		return nil, err
+	}
+	response := newWebhookResponse(webhook)
+	response.Secret = webhook.Secret
+	return &response, nil
+}
+
+// ListWebhooks возвращает все вебхуки в порядке регистрации без ключей подписи.
+func (s *Service) ListWebhooks() ([]WebhookResponse, error) {
+	webhooks, err := s.store.Webhooks().List()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		result = append(result, newWebhookResponse(webhook))
+	}
+	return result, nil
+}
+
+// DeleteWebhook удаляет вебхук id вместе с его доставками.
+//
+// Возвращает ErrWebhookNotFound, если вебхук не найден.
+func (s *Service) DeleteWebhook(id uint) error {
+	err := s.store.Webhooks().Delete(id)
+	if errors.Is(err, database.ErrNotFound) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+// ListWebhookDeliveries возвращает страницу page размера pageSize списка доставок,
+// выбранных filter, от новых к старым.
+func (s *Service) ListWebhookDeliveries(filter DeliveryFilter, page, pageSize int) (*DeliveryPage, error) {
+	query := database.DeliveryQuery{WebhookID: filter.WebhookID, Status: filter.Status}
+	result := &DeliveryPage{Deliveries: []DeliveryResponse{}, Page: page, PageSize: pageSize}
+	total, err := s.store.Webhooks().CountDeliveries(query)
+	if err != nil {
+		return nil, err
+	}
+	result.Total = total
+
+	deliveries, err := s.store.Webhooks().ListDeliveries(query, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, delivery := range deliveries {
+		result.Deliveries = append(result.Deliveries, newDeliveryResponse(delivery))
+	}
+	return result, nil
+}
+
+// ReplayWebhookDelivery ставит доставку id в очередь на немедленную отправку
+// с обнулённым числом попыток, в том числе уже выполненную или мёртвую доставку.
+//
+// Возвращает ErrDeliveryNotFound, если доставка не найдена.
+func (s *Service) ReplayWebhookDelivery(id uint) (*DeliveryResponse, error) {
+	var delivery *database.WebhookDelivery
+	err := s.runInTransaction(func(tx database.Store) error {
+		var err error
+		delivery, err = tx.Webhooks().FindDelivery(id)
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrDeliveryNotFound
+		}
+		if err != nil {
+			return err
+		}
+		delivery.Status = database.DeliveryPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = time.Now()
+		delivery.LastError = ""
+		delivery.ResponseStatus = 0
+		delivery.DeliveredAt = nil
+		return tx.Webhooks().UpdateDelivery(delivery)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := newDeliveryResponse(*delivery)
+	return &response, nil
+}
+
+// DeleteWebhookDelivery удаляет доставку id; если она ещё не выполнена, она не будет отправлена.
+//
+// Возвращает ErrDeliveryNotFound, если доставка не найдена.
+func (s *Service) DeleteWebhookDelivery(id uint) error {
+	err := s.store.Webhooks().DeleteDelivery(id)
+	if errors.Is(err, database.ErrNotFound) {
+		return ErrDeliveryNotFound
+	}
+	return err
+}
+
+// DeliverWebhooks отправляет не более limit доставок, время попытки которых
+// наступило, и возвращает число успешно доставленных.
+//
+// Доставка считается успешной, если получатель ответил кодом 2xx. После неудачной
+// попытки следующая назначается с экспоненциально растущей задержкой
+// (Options.WebhookRetryBackoff), а после Options.WebhookMaxAttempts неудачных
+// попыток доставка помечается мёртвой и ждёт ручного повтора.
+// Несколько экземпляров сервиса могут вызывать DeliverWebhooks одновременно:
+// каждую доставку отправляет только один из них.
+func (s *Service) DeliverWebhooks(limit int) (int, error) {
+	var claimed []database.WebhookDelivery
+	err := s.runInTransaction(func(tx database.Store) error {
+		var err error
+		claimed, err = tx.Webhooks().ClaimDeliveries(time.Now(), webhookClaimLease, limit)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range claimed {
+		delivery := &claimed[i]
+		webhook, err := s.store.Webhooks().Find(delivery.WebhookID)
+		if errors.Is(err, database.ErrNotFound) {
+			// Вебхук удалён вместе с доставками, пока она ждала отправки
+			continue
+		}
+		if err != nil {
+			return delivered, err
+		}
+
+		status, sendErr := s.sendWebhook(webhook, delivery)
+		delivery.Attempts++
+		delivery.ResponseStatus = status
+		now := time.Now()
+		switch {
+		case sendErr == nil:
+			delivery.Status = database.DeliveryDelivered
+			delivery.DeliveredAt = &now
+			delivery.LastError = ""
+			delivered++
+		case delivery.Attempts >= s.options.WebhookMaxAttempts:
+			delivery.Status = database.DeliveryDead
+			delivery.LastError = sendErr.Error()
+		default:
+			delivery.NextAttemptAt = now.Add(s.webhookBackoff(delivery.Attempts))
+			delivery.LastError = sendErr.Error()
+		}
+		if err := s.store.Webhooks().UpdateDelivery(delivery); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// SignWebhook возвращает подпись тела запроса вебхука body, отправленного
+// в момент timestamp (значение заголовка WebhookTimestampHeader):
+// "sha256=" и HMAC-SHA256 строки "<timestamp>.<body>" с ключом secret в hex.
+//
+// Получатель проверяет подпись, вычисляя её тем же способом, и отвергает
+// запросы со слишком старым timestamp, чтобы их нельзя было воспроизвести.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendWebhook выполняет одну попытку доставки delivery по вебхуку webhook
+// и возвращает HTTP-код ответа (0, если ответа не было) и ошибку неуспешной попытки.
+func (s *Service) sendWebhook(webhook *database.Webhook, delivery *database.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(webhook.Secret, timestamp, body))
+
+	resp, err := s.options.WebhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Тело ответа дочитывается, чтобы соединение можно было использовать повторно
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("получатель ответил %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookBackoff возвращает задержку перед попыткой, следующей за attempts неудачными.
+func (s *Service) webhookBackoff(attempts int) time.Duration {
+	backoff := s.options.WebhookRetryBackoff
+	for i := 1; i < attempts && backoff < maxWebhookBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxWebhookBackoff)
+}
+
+// enqueueWebhooks ставит в очередь доставки о списании и зачислении по переводу
+// transaction для вебхуков кошельков отправителя и получателя и вебхуков всех кошельков.
+//
+// Доставки создаются в транзакции перевода tx и отправляются только после её фиксации.
+func enqueueWebhooks(tx database.Store, transaction database.Transaction) error {
+	response := newTransactionResponse(transaction)
+	targets := []struct{ event, address string }{
+		{WebhookEventDebited, transaction.FromAddress},
+		{WebhookEventCredited, transaction.ToAddress},
+	}
+	for _, target := range targets {
+		webhooks, err := tx.Webhooks().Subscribers(target.address)
+		if err != nil {
+			return err
+		}
+		if len(webhooks) == 0 {
+			continue
+		}
+		payload, err := json.Marshal(WebhookPayload{Event: target.event, Wallet: target.address, Transaction: response})
+		if err != nil {
+			return err
+		}
+		for _, webhook := range webhooks {
+			err := tx.Webhooks().CreateDelivery(&database.WebhookDelivery{
+				WebhookID:     webhook.ID,
+				Event:         target.event,
+				WalletAddress: target.address,
+				Payload:       string(payload),
+				Status:        database.DeliveryPending,
+				NextAttemptAt: time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newWebhookResponse преобразует модель вебхука в ответ API без ключа подписи.
+func newWebhookResponse(w database.Webhook) WebhookResponse {
+	response := WebhookResponse{ID: w.ID, URL: w.URL, CreatedAt: w.CreatedAt}
+	if w.WalletAddress != nil {
+		response.Wallet = *w.WalletAddress
+	}
+	return response
+}
+
+// newDeliveryResponse преобразует модель доставки вебхука в ответ API.
+func newDeliveryResponse(d database.WebhookDelivery) DeliveryResponse {
+	response := DeliveryResponse{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		Event:          d.Event,
+		Wallet:         d.WalletAddress,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastError:      d.LastError,
+		ResponseStatus: d.ResponseStatus,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+		Payload:        json.RawMessage(d.Payload),
+	}
+	if d.Status == database.DeliveryPending {
+		next := d.NextAttemptAt
+		response.NextAttemptAt = &next
+	}
+	return response
+}
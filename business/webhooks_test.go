@@ -0,0 +1,187 @@
+package business
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"payment_system_api/database"
+)
+
+// webhookReceiver — тестовый получатель вебхуков, проверяющий подпись запросов
+// и отвечающий ошибкой на первые failures запросов.
+type webhookReceiver struct {
+	t        *testing.T
+	secret   string
+	mu       sync.Mutex
+	failures int
+	received []WebhookPayload
+}
+
+// ServeHTTP проверяет подпись запроса и запоминает тело успешно принятого вебхука.
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		r.t.Errorf("Не удалось прочитать тело вебхука: %v", err)
+	}
+	timestamp := req.Header.Get(WebhookTimestampHeader)
+	if got, want := req.Header.Get(WebhookSignatureHeader), SignWebhook(r.secret, timestamp, body); got != want {
+		r.t.Errorf("Неверная подпись вебхука: %q, ожидалась %q", got, want)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		r.t.Errorf("Тело вебхука не разобрано: %v", err)
+	}
+	if req.Header.Get(WebhookEventHeader) != payload.Event {
+		r.t.Errorf("Заголовок события %q не совпадает с телом %q", req.Header.Get(WebhookEventHeader), payload.Event)
+	}
+	r.received = append(r.received, payload)
+}
+
+// TestDeliverWebhooks проверяет регистрацию и доставку вебхуков
+// в хранилище в памяти и в базах данных PostgreSQL и SQLite.
+func TestDeliverWebhooks(t *testing.T) {
+	forEachStore(t, testDeliverWebhooks)
+}
+
+// testDeliverWebhooks выполняет проверки TestDeliverWebhooks над сервисом s:
+//   - перевод ставит в очередь списание для вебхука отправителя, зачисление
+//     для вебхука получателя и оба события для вебхука всех кошельков;
+//   - запросы подписаны ключом вебхука;
+//   - неудачная доставка повторяется, а после последней попытки становится мёртвой;
+//   - мёртвую доставку можно повторить, удалённая доставка не отправляется.
+func testDeliverWebhooks(t *testing.T, s *Service) {
+	s.options.WebhookMaxAttempts = 2
+	s.options.WebhookRetryBackoff = time.Millisecond
+	createTestWallets(t, s, 1000, "hook-a", "hook-b")
+
+	if _, err := s.CreateWebhook("ftp://example.com", ""); !errors.Is(err, ErrInvalidWebhookURL) {
+		t.Errorf("Ожидалась ошибка ErrInvalidWebhookURL, получена %v", err)
+	}
+	if _, err := s.CreateWebhook("http://example.com", "hook-unknown"); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("Ожидалась ошибка ErrWalletNotFound, получена %v", err)
+	}
+
+	receivers := make(map[string]*webhookReceiver)
+	webhooks := make(map[string]uint)
+	for _, wallet := range []string{"hook-a", "hook-b", ""} {
+		receiver := &webhookReceiver{t: t}
+		server := httptest.NewServer(receiver)
+		t.Cleanup(server.Close)
+		webhook, err := s.CreateWebhook(server.URL, wallet)
+		if err != nil {
+			t.Fatalf("Не удалось зарегистрировать вебхук: %v", err)
+		}
+		receiver.secret = webhook.Secret
+		receivers[wallet] = receiver
+		webhooks[wallet] = webhook.ID
+	}
+	receivers["hook-b"].failures = 2
+	receivers[""].failures = 1
+
+	transfer, err := s.SendMoney(TransferRequest{FromAddress: "hook-a", ToAddress: "hook-b", Amount: "1"})
+	if err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+
+	// Первая попытка: вебхук кошелька-отправителя доставлен, для остальных назначен повтор
+	if delivered, err := s.DeliverWebhooks(10); err != nil || delivered != 2 {
+		t.Fatalf("Первая попытка: доставлено %d, ошибка %v, ожидалось 2", delivered, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if delivered, err := s.DeliverWebhooks(10); err != nil || delivered != 1 {
+		t.Fatalf("Вторая попытка: доставлено %d, ошибка %v, ожидалось 1", delivered, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if delivered, err := s.DeliverWebhooks(10); err != nil || delivered != 0 {
+		t.Fatalf("Третья попытка: доставлено %d, ошибка %v, ожидалось 0", delivered, err)
+	}
+
+	want := map[string][]string{
+		"hook-a": {WebhookEventDebited},
+		"hook-b": nil,
+		"":       {WebhookEventDebited, WebhookEventCredited},
+	}
+	for wallet, events := range want {
+		received := receivers[wallet].received
+		if len(received) != len(events) {
+			t.Errorf("Вебхук %q: получено %d событий, ожидалось %d", wallet, len(received), len(events))
+			continue
+		}
+		for _, event := range events {
+			found := false
+			for _, payload := range received {
+				found = found || (payload.Event == event && payload.Transaction.UUID == transfer.UUID)
+			}
+			if !found {
+				t.Errorf("Вебхук %q не получил событие %s", wallet, event)
+			}
+		}
+	}
+
+	dead, err := s.ListWebhookDeliveries(DeliveryFilter{Status: database.DeliveryDead}, 1, 10)
+	if err != nil || dead.Total != 1 || len(dead.Deliveries) != 1 {
+		t.Fatalf("Ожидалась одна мёртвая доставка, получено %+v, %v", dead, err)
+	}
+	delivery := dead.Deliveries[0]
+	if delivery.WebhookID != webhooks["hook-b"] || delivery.Event != WebhookEventCredited ||
+		delivery.Attempts != 2 || delivery.ResponseStatus != http.StatusServiceUnavailable || delivery.LastError == "" {
+		t.Errorf("Неверная мёртвая доставка: %+v", delivery)
+	}
+	all, err := s.ListWebhookDeliveries(DeliveryFilter{}, 1, 10)
+	if err != nil || all.Total != 4 {
+		t.Errorf("Ожидалось 4 доставки, получено %+v, %v", all, err)
+	}
+
+	// Повтор мёртвой доставки
+	replayed, err := s.ReplayWebhookDelivery(delivery.ID)
+	if err != nil || replayed.Status != database.DeliveryPending || replayed.Attempts != 0 {
+		t.Fatalf("Неверный результат повтора: %+v, %v", replayed, err)
+	}
+	if delivered, err := s.DeliverWebhooks(10); err != nil || delivered != 1 {
+		t.Fatalf("Повтор: доставлено %d, ошибка %v, ожидалось 1", delivered, err)
+	}
+	if received := receivers["hook-b"].received; len(received) != 1 || received[0].Event != WebhookEventCredited {
+		t.Errorf("Вебхук получателя: получено %+v", received)
+	}
+
+	// Удалённая доставка не отправляется
+	if _, err := s.SendMoney(TransferRequest{FromAddress: "hook-a", ToAddress: "hook-b", Amount: "1"}); err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	pending, err := s.ListWebhookDeliveries(DeliveryFilter{WebhookID: webhooks["hook-a"], Status: database.DeliveryPending}, 1, 10)
+	if err != nil || len(pending.Deliveries) != 1 {
+		t.Fatalf("Ожидалась одна ожидающая доставка, получено %+v, %v", pending, err)
+	}
+	if err := s.DeleteWebhookDelivery(pending.Deliveries[0].ID); err != nil {
+		t.Fatalf("Не удалось удалить доставку: %v", err)
+	}
+	if err := s.DeleteWebhookDelivery(pending.Deliveries[0].ID); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Errorf("Ожидалась ошибка ErrDeliveryNotFound, получена %v", err)
+	}
+	if err := s.DeleteWebhook(webhooks[""]); err != nil {
+		t.Fatalf("Не удалось удалить вебхук: %v", err)
+	}
+	if delivered, err := s.DeliverWebhooks(10); err != nil || delivered != 1 {
+		t.Fatalf("После удаления: доставлено %d, ошибка %v, ожидалось 1", delivered, err)
+	}
+	if len(receivers["hook-a"].received) != 1 || len(receivers[""].received) != 2 {
+		t.Errorf("Удалённые доставки не должны отправляться")
+	}
+	if err := s.DeleteWebhook(webhooks[""]); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("Ожидалась ошибка ErrWebhookNotFound, получена %v", err)
+	}
+}
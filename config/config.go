@@ -5,21 +5,64 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Виды хранилища данных.
+const (
+	StorageDatabase = "database" // база данных по адресу DATABASE_URL: PostgreSQL или SQLite по схеме URL
+	StorageMemory   = "memory"   // хранилище в памяти процесса, данные теряются при остановке
+
+	// storageDatabaseLegacy — прежнее название StorageDatabase, принимается для совместимости.
+	storageDatabaseLegacy = "postgres"
+)
+
 // Config хранит настройки приложения, включая URL базы данных.
 type Config struct {
-	DatabaseURL string
+	Storage           string // вид хранилища данных: StorageDatabase или StorageMemory
+	DatabaseURL       string
+	IdempotencyKeyTTL time.Duration // срок хранения ключей идемпотентности
+	HoldTTL           time.Duration // срок резервирования средств по умолчанию
+	FXRatesFile       string        // путь к JSON-файлу со статическими курсами валют, пустой — конвертация отключена
+	FXQuoteTTL        time.Duration // срок жизни котировки курса
+
+	EventsPublisher     string        // получатель событий: "stdout" или "file:<путь>", пустая строка — публикация отключена
+	EventsRelayInterval time.Duration // период публикации событий из исходящей очереди
+
+	WebhookMaxAttempts  int           // число попыток доставки вебхука до перевода её в мёртвые
+	WebhookRetryBackoff time.Duration // задержка перед первой повторной попыткой доставки вебхука
+
+	SchedulerInterval time.Duration // период проверки запланированных переводов и регулярных платежей
+
+	StandingOrderMaxRetries    int           // число повторных попыток регулярного платежа при недостатке средств, 0 — без повторов
+	StandingOrderRetryInterval time.Duration // задержка перед повторной попыткой регулярного платежа
+
+	StreamPollInterval time.Duration // период опроса хранилища потоками транзакций
+
+	AdminTokens map[string]string // токены административных эндпоинтов: токен → имя администратора
 }
 
 // LoadConfig загружает конфигурацию приложения.
 //
 // Она выполняет следующие действия:
 // 1. Пытается загрузить файл .env (если он существует).
-// 2. Считывает переменную окружения DATABASE_URL.
-// 3. Если DATABASE_URL не задана, завершает работу с ошибкой.
+// 2. Считывает вид хранилища STORAGE: database (по умолчанию; прежнее значение postgres
+// равнозначно ему) или memory.
+// 3. Для хранилища database считывает DATABASE_URL и завершает работу с ошибкой, если она не задана.
+// 4. Считывает необязательные параметры: IDEMPOTENCY_KEY_TTL (по умолчанию 24h),
+// HOLD_TTL (по умолчанию 24h),
+// FX_RATES_FILE, FX_QUOTE_TTL (по умолчанию 1m), EVENTS_PUBLISHER
+// EVENTS_RELAY_INTERVAL (по умолчанию 1s), WEBHOOK_MAX_ATTEMPTS (по умолчанию 8),
+// WEBHOOK_RETRY_BACKOFF (по умолчанию 5s), SCHEDULER_INTERVAL (по умолчанию 1s),
+// STANDING_ORDER_MAX_RETRIES (по умолчанию 3, 0 отключает повторные попытки),
+// STANDING_ORDER_RETRY_INTERVAL (по умолчанию 1h)
+// и STREAM_POLL_INTERVAL (по умолчанию 1s).
+// 5. Считывает токены администраторов ADMIN_TOKENS в виде "имя:токен,имя:токен";
+// без них административные эндпоинты отклоняют все запросы.
 // Возвращает указатель на структуру Config с загруженными значениями.
 func LoadConfig() *Config {
 	err := godotenv.Load()
@@ -28,11 +71,115 @@ func LoadConfig() *Config {
 
 	}
 
+	storage := os.Getenv("STORAGE")
+	if storage == "" || storage == storageDatabaseLegacy {
+		storage = StorageDatabase
+	}
+	if storage != StorageDatabase && storage != StorageMemory {
+		log.Fatalf("Некорректное значение переменной окружения STORAGE: %q", storage)
+	}
+
 	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
+	if storage == StorageDatabase && dbURL == "" {
 		log.Fatalf("Переменная окружения DATABASE_URL не задана")
 	}
+	eventsPublisher := os.Getenv("EVENTS_PUBLISHER")
+	if eventsPublisher != "" && eventsPublisher != "stdout" && !strings.HasPrefix(eventsPublisher, "file:") {
+		log.Fatalf("Некорректное значение переменной окружения EVENTS_PUBLISHER: %q", eventsPublisher)
+	}
 	return &Config{
-		DatabaseURL: dbURL,
+		Storage:           storage,
+		DatabaseURL:       dbURL,
+		IdempotencyKeyTTL: getDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+		HoldTTL:           getDuration("HOLD_TTL", 24*time.Hour),
+		FXRatesFile:       os.Getenv("FX_RATES_FILE"),
+		FXQuoteTTL:        getDuration("FX_QUOTE_TTL", time.Minute),
+
+		EventsPublisher:     eventsPublisher,
+		EventsRelayInterval: getDuration("EVENTS_RELAY_INTERVAL", time.Second),
+
+		WebhookMaxAttempts:  getInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookRetryBackoff: getDuration("WEBHOOK_RETRY_BACKOFF", 5*time.Second),
+
+		SchedulerInterval: getDuration("SCHEDULER_INTERVAL", time.Second),
+
+		StandingOrderMaxRetries:    getNonNegativeInt("STANDING_ORDER_MAX_RETRIES", 3),
+		StandingOrderRetryInterval: getDuration("STANDING_ORDER_RETRY_INTERVAL", time.Hour),
+
+		StreamPollInterval: getDuration("STREAM_POLL_INTERVAL", time.Second),
+
+		AdminTokens: getAdminTokens("ADMIN_TOKENS"),
+	}
+}
+
+// getDuration считывает длительность из переменной окружения name.
+//
+// Если переменная не задана, возвращает defaultValue.
+// Если значение некорректно или не положительно, завершает работу с ошибкой.
+func getDuration(name string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Fatalf("Некорректное значение переменной окружения %s: %q", name, value)
+	}
+	return duration
+}
+
+// getInt считывает целое число из переменной окружения name.
+//
+// Если переменная не задана, возвращает defaultValue.
+// Если значение некорректно или не положительно, завершает работу с ошибкой.
+func getInt(name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Fatalf("Некорректное значение переменной окружения %s: %q", name, value)
+	}
+	return n
+}
+
+// getAdminTokens считывает токены администраторов из переменной окружения name
+// в виде "имя:токен,имя:токен" и возвращает их как токен → имя.
+//
+// Если переменная не задана, возвращает пустой набор.
+// Если запись некорректна или токен повторяется, завершает работу с ошибкой.
+func getAdminTokens(name string) map[string]string {
+	tokens := make(map[string]string)
+	value := os.Getenv(name)
+	if value == "" {
+		return tokens
+	}
+	for _, entry := range strings.Split(value, ",") {
+		admin, token, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || admin == "" || token == "" {
+			log.Fatalf("Некорректная запись в переменной окружения %s: ожидается имя:токен", name)
+		}
+		if _, duplicate := tokens[token]; duplicate {
+			log.Fatalf("Повторяющийся токен в переменной окружения %s", name)
+		}
+		tokens[token] = admin
+	}
+	return tokens
+}
+
+// getNonNegativeInt считывает неотрицательное целое число из переменной окружения name.
+//
+// Если переменная не задана, возвращает defaultValue.
+// Если значение некорректно или отрицательно, завершает работу с ошибкой.
+func getNonNegativeInt(name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Fatalf("Некорректное значение переменной окружения %s: %q", name, value)
 	}
+	return n
 }
@@ -11,31 +11,40 @@ import (
 const testDSN = "host=127.0.0.1 user=testuser password=testpass dbname=testdb port=5433 sslmode=disable"
 
 // TestMain выполняет подготовку тестового окружения.
-
+//
 // Она подключается к тестовой базе данных, выполняет миграции
-// и затем запускает все тесты.
+// и затем запускает все тесты. Если тестовая база данных недоступна,
+// DB остаётся nil: тесты, которым она нужна, пропускаются (см. requireTestDB),
+// остальные выполняются.
 // После завершения тестов происходит завершение программы с соответствующим кодом выхода.
 func TestMain(m *testing.M) {
-	ConnectDB(testDSN)
-	if DB == nil {
-		log.Fatalf("Не удалось подключиться к тестовой базе данных.")
+	db, err := open(testDSN)
+	if err != nil {
+		log.Printf("Тестовая база данных недоступна, тесты PostgreSQL пропускаются: %v", err)
+	} else {
+		DB = db
+		Migrate()
 	}
 
-	Migrate()
-
 	code := m.Run()
 
 	os.Exit(code)
 }
 
+// requireTestDB пропускает тест, если тестовая база данных недоступна.
+func requireTestDB(t *testing.T) {
+	t.Helper()
+	if DB == nil {
+		t.Skip("Тестовая база данных недоступна")
+	}
+}
+
 // TestInitialSetup проверяет работу функции InitialSetup.
 //
 // Перед вызовом InitialSetup база данных должна быть пустой по таблице Wallet.
 // После вызова InitialSetup должно создаться ровно 10 кошельков.
 func TestConnectAndMigrate(t *testing.T) {
-	if DB == nil {
-		t.Fatalf("Переменная DB не была инициализирована в TestMain.")
-	}
+	requireTestDB(t)
 
 	if !DB.Migrator().HasTable(&Wallet{}) {
 		t.Fatalf("Таблица Wallet не была создана.")
@@ -47,13 +56,14 @@ func TestConnectAndMigrate(t *testing.T) {
 
 // TestInitialSetup проверяет, что функция InitialSetup работает корректно
 func TestInitialSetup(t *testing.T) {
+	requireTestDB(t)
 	var count int64
 	DB.Model(&Wallet{}).Count(&count)
 	if count != 0 {
 		t.Errorf("Перед запуском теста в базе уже были кошельки.")
 	}
 
-	InitialSetup()
+	InitialSetup(NewStore(DB))
 	DB.Model(&Wallet{}).Count(&count)
 	if count != 10 {
 		t.Errorf("Ожидалось 10 кошельков, найдено %d.", count)
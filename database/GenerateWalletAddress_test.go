@@ -4,7 +4,7 @@ import (
 	"testing"
 )
 
-// TestGenerateWalletAddress проверяет работу функции generateWalletAddress.
+// TestGenerateWalletAddress проверяет работу функции GenerateWalletAddress.
 //
 // Тест выполняет следующие проверки:
 //   - Генерируются два адреса без ошибок.
@@ -12,13 +12,13 @@ import (
 //   - Длина каждого адреса соответствует ожидаемой (64 символа).
 func TestGenerateWalletAddress(t *testing.T) {
 	// Генерируем два адреса
-	address1, err1 := generateWalletAddress()
+	address1, err1 := GenerateWalletAddress()
 	if err1 != nil {
 		t.Fatalf("Не удалось сгенерировать первый адрес: %v", err1)
 	}
 	t.Logf("Сгенерирован первый адрес: %s", address1)
 
-	address2, err2 := generateWalletAddress()
+	address2, err2 := GenerateWalletAddress()
 	if err2 != nil {
 		t.Fatalf("Не удалось сгенерировать второй адрес: %v", err2)
 	}
@@ -0,0 +1,244 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	gosqlite "github.com/glebarez/go-sqlite"
+	"github.com/glebarez/sqlite"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteScheme — схема строки подключения к базе данных SQLite, например
+// "sqlite://payment.db" или "sqlite://:memory:".
+const sqliteScheme = "sqlite://"
+
+// dialect описывает особенности СУБД, которые должно учитывать хранилище.
+type dialect interface {
+	// name возвращает имя диалекта, совпадающее с каталогом его миграций.
+	name() string
+	// open возвращает драйвер GORM для строки подключения dsn без схемы диалекта.
+	open(dsn string) gorm.Dialector
+	// configure настраивает пул соединений после подключения.
+	configure(pool *sql.DB)
+	// lock возвращает запрос db, блокирующий выбранные строки до конца транзакции.
+	lock(db *gorm.DB) *gorm.DB
+	// lockSkipLocked возвращает запрос db, блокирующий выбранные строки
+	// до конца транзакции и пропускающий строки, уже заблокированные другими.
+	lockSkipLocked(db *gorm.DB) *gorm.DB
+	// isUniqueViolation сообщает, вызвана ли ошибка err нарушением ограничения уникальности.
+	isUniqueViolation(err error) bool
+	// isCheckViolation сообщает, вызвана ли ошибка err нарушением ограничения CHECK.
+	isCheckViolation(err error) bool
+	// isForeignKeyViolation сообщает, вызвана ли ошибка err нарушением внешнего ключа.
+	isForeignKeyViolation(err error) bool
+	// isRetryable сообщает, можно ли повторить транзакцию, завершившуюся ошибкой err.
+	isRetryable(err error) bool
+	// lockMigrations не даёт другим процессам применять миграции
+	// до конца транзакции tx.
+	lockMigrations(tx *gorm.DB) error
+	// tryLock пытается без ожидания захватить до конца транзакции tx
+	// блокировку key, общую для всех процессов, и сообщает, удалось ли это.
+	tryLock(tx *gorm.DB, key int64) (bool, error)
+	// settled возвращает запрос db, оставляющий только строки с колонкой xid,
+	// после которых не может быть зафиксировано строк с меньшим (xid, id).
+	settled(db *gorm.DB) *gorm.DB
+}
+
+// dialects содержит поддерживаемые диалекты по имени драйвера GORM.
+var dialects = map[string]dialect{
+	"postgres":        postgresDialect{},
+	sqlite.DriverName: sqliteDialect{},
+}
+
+// parseDSN определяет диалект по схеме строки подключения dsn
+// и возвращает его вместе со строкой подключения для драйвера.
+//
+// Строки со схемой sqlite:// открываются в SQLite, остальные —
+// в PostgreSQL (как URL postgres://, так и в формате "host=... user=...").
+func parseDSN(dsn string) (dialect, string) {
+	if path, ok := strings.CutPrefix(dsn, sqliteScheme); ok {
+		return sqliteDialect{}, path
+	}
+	return postgresDialect{}, dsn
+}
+
+// dialectOf возвращает диалект подключения db.
+func dialectOf(db *gorm.DB) dialect {
+	if d, ok := dialects[db.Dialector.Name()]; ok {
+		return d
+	}
+	return postgresDialect{}
+}
+
+// Ключи рекомендательных блокировок PostgreSQL.
+const (
+	migrationLockKey = 7262011485 // удерживает транзакция миграции
+	outboxLockKey    = 7262011486 // удерживает транзакция публикации событий
+)
+
+// postgresDialect — диалект PostgreSQL.
+type postgresDialect struct{}
+
+// name возвращает "postgres".
+func (postgresDialect) name() string {
+	return "postgres"
+}
+
+// open возвращает драйвер PostgreSQL.
+func (postgresDialect) open(dsn string) gorm.Dialector {
+	return postgres.Open(dsn)
+}
+
+// configure оставляет настройки пула по умолчанию.
+func (postgresDialect) configure(*sql.DB) {}
+
+// lock блокирует выбранные строки через SELECT ... FOR UPDATE.
+func (postgresDialect) lock(db *gorm.DB) *gorm.DB {
+	return db.Clauses(clause.Locking{Strength: "UPDATE"})
+}
+
+// lockSkipLocked блокирует выбранные строки через SELECT ... FOR UPDATE SKIP LOCKED.
+func (postgresDialect) lockSkipLocked(db *gorm.DB) *gorm.DB {
+	return db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+}
+
+// isUniqueViolation проверяет SQLSTATE 23505.
+func (postgresDialect) isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isCheckViolation проверяет SQLSTATE 23514.
+func (postgresDialect) isCheckViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23514"
+}
+
+// isForeignKeyViolation проверяет SQLSTATE 23503.
+func (postgresDialect) isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// isRetryable проверяет ошибки сериализации (SQLSTATE 40001) и взаимоблокировки (SQLSTATE 40P01).
+func (postgresDialect) isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+// lockMigrations захватывает рекомендательную блокировку migrationLockKey,
+// которая снимается при завершении транзакции.
+func (postgresDialect) lockMigrations(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error
+}
+
+// tryLock захватывает рекомендательную блокировку key через pg_try_advisory_xact_lock.
+func (postgresDialect) tryLock(tx *gorm.DB, key int64) (bool, error) {
+	var locked bool
+	err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", key).Scan(&locked).Error
+	return locked, err
+}
+
+// settled оставляет строки транзакций PostgreSQL, номер которых меньше номера
+// старейшей незавершённой транзакции: все более поздние строки получат больший xid.
+func (postgresDialect) settled(db *gorm.DB) *gorm.DB {
+	return db.Where("xid < pg_snapshot_xmin(pg_current_snapshot())::text::bigint")
+}
+
+// sqliteDialect — диалект SQLite.
+//
+// SQLite не поддерживает блокировку отдельных строк. Вместо неё пул ограничен
+// одним соединением, поэтому транзакции выполняются строго по очереди
+// и читают только зафиксированные данные.
+type sqliteDialect struct{}
+
+// name возвращает "sqlite".
+func (sqliteDialect) name() string {
+	return "sqlite"
+}
+
+// open возвращает драйвер SQLite с включёнными внешними ключами, журналом WAL
+// и ожиданием снятия блокировки файла другими процессами.
+// Транзакции сразу захватывают блокировку записи (BEGIN IMMEDIATE).
+func (sqliteDialect) open(dsn string) gorm.Dialector {
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	pragmas := "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+	return sqlite.Open(dsn + separator + pragmas)
+}
+
+// configure ограничивает пул одним соединением.
+//
+// Это сериализует транзакции и сохраняет базу ":memory:",
+// которая существует только в рамках соединения.
+func (sqliteDialect) configure(pool *sql.DB) {
+	pool.SetMaxOpenConns(1)
+	pool.SetMaxIdleConns(1)
+	pool.SetConnMaxLifetime(0)
+}
+
+// lock не меняет запрос: транзакции SQLite уже выполняются по очереди.
+func (sqliteDialect) lock(db *gorm.DB) *gorm.DB {
+	return db
+}
+
+// lockSkipLocked не меняет запрос: транзакции SQLite уже выполняются по очереди.
+func (sqliteDialect) lockSkipLocked(db *gorm.DB) *gorm.DB {
+	return db
+}
+
+// isUniqueViolation проверяет коды SQLITE_CONSTRAINT_UNIQUE и SQLITE_CONSTRAINT_PRIMARYKEY.
+func (sqliteDialect) isUniqueViolation(err error) bool {
+	code := sqliteCode(err)
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// isCheckViolation проверяет код SQLITE_CONSTRAINT_CHECK.
+func (sqliteDialect) isCheckViolation(err error) bool {
+	return sqliteCode(err) == sqlite3.SQLITE_CONSTRAINT_CHECK
+}
+
+// isForeignKeyViolation проверяет код SQLITE_CONSTRAINT_FOREIGNKEY.
+func (sqliteDialect) isForeignKeyViolation(err error) bool {
+	return sqliteCode(err) == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
+
+// isRetryable проверяет, что база данных занята другим процессом (SQLITE_BUSY, SQLITE_LOCKED).
+func (sqliteDialect) isRetryable(err error) bool {
+	code := sqliteCode(err) & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
+
+// lockMigrations ничего не делает: транзакция SQLite начинается с BEGIN IMMEDIATE
+// и уже удерживает блокировку записи всего файла базы данных.
+func (sqliteDialect) lockMigrations(*gorm.DB) error {
+	return nil
+}
+
+// sqliteCode возвращает расширенный код ошибки SQLite или 0, если err не ошибка SQLite.
+func sqliteCode(err error) int {
+	var sqliteErr *gosqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return 0
+	}
+	return sqliteErr.Code()
+}
+
+// tryLock всегда успешен: транзакция SQLite уже удерживает блокировку записи всего файла.
+func (sqliteDialect) tryLock(*gorm.DB, int64) (bool, error) {
+	return true, nil
+}
+
+// settled не меняет запрос: транзакции SQLite выполняются по очереди,
+// и идентификаторы строк растут в порядке фиксации.
+func (sqliteDialect) settled(db *gorm.DB) *gorm.DB {
+	return db
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"payment_system_api/money"
+)
+
+// Состояния резервирования средств.
+const (
+	HoldActive   = "active"   // средства зарезервированы, возможны списания и отмена
+	HoldCaptured = "captured" // зарезервированная сумма списана полностью
+	HoldVoided   = "voided"   // резервирование отменено, остаток освобождён
+	HoldExpired  = "expired"  // срок резервирования истёк, остаток освобождён
+)
+
+// Hold представляет резервирование средств на кошельке для последующего
+// списания в пользу кошелька получателя.
+//
+// Пока резервирование активно, его несписанный остаток Amount-Captured
+// учитывается в поле Held кошелька и недоступен для переводов.
+type Hold struct {
+	ID            uint           `gorm:"primaryKey"`
+	UUID          string         `gorm:"unique;not null"`    // уникальный идентификатор резервирования
+	WalletAddress string         `gorm:"index;not null"`     // кошелёк, на котором зарезервированы средства
+	ToAddress     string         `gorm:"not null"`           // кошелёк, на который зачисляются списания
+	Amount        money.Amount   `gorm:"not null"`           // зарезервированная сумма в минимальных единицах валюты
+	Captured      money.Amount   `gorm:"not null;default:0"` // уже списанная сумма
+	Currency      money.Currency `gorm:"size:3;not null"`    // валюта кошелька ISO 4217
+	Status        string         `gorm:"not null"`           // состояние: HoldActive, HoldCaptured, HoldVoided или HoldExpired
+	ExpiresAt     time.Time      `gorm:"not null"`           // момент, после которого остаток освобождается
+	CreatedAt     time.Time      // время резервирования
+	UpdatedAt     time.Time      // время последнего изменения
+}
+
+// Remaining возвращает несписанный остаток резервирования.
+func (h *Hold) Remaining() money.Amount {
+	return h.Amount - h.Captured
+}
+
+// BeforeCreate генерирует UUID резервирования перед сохранением.
+func (h *Hold) BeforeCreate(*gorm.DB) error {
+	h.UUID = uuid.New().String()
+	return nil
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"time"
+
+	"payment_system_api/money"
+)
+
+// Виды счетов главной книги.
+const (
+	AccountKindWallet   = "wallet"   // счёт кошелька
+	AccountKindIssuance = "issuance" // системный счёт эмиссии, источник начальных балансов
+	AccountKindFX       = "fx"       // системный клиринговый счёт конвертации валют
+)
+
+// Account представляет счёт главной книги.
+//
+// У каждого кошелька есть ровно один счёт. Системные счета (эмиссия, клиринг
+// конвертации) заводятся по одному на валюту и не привязаны к кошельку.
+type Account struct {
+	ID        uint           `gorm:"primaryKey"`
+	Code      string         `gorm:"unique;not null"` // уникальный код счёта, например "wallet:<адрес>" или "fx:USD"
+	Kind      string         `gorm:"not null"`        // вид счёта: wallet, issuance или fx
+	Currency  money.Currency `gorm:"size:3;not null"` // валюта счёта ISO 4217
+	WalletID  *uint          `gorm:"uniqueIndex"`     // кошелёк, которому принадлежит счёт; NULL для системных счетов
+	CreatedAt time.Time      // время открытия счёта
+}
+
+// JournalEntry представляет запись журнала — набор проводок одной операции.
+//
+// Сумма проводок записи в каждой валюте равна нулю.
+type JournalEntry struct {
+	ID            uint      `gorm:"primaryKey"`
+	TransactionID *uint     `gorm:"index"` // транзакция, породившая запись; NULL для начальных балансов
+	Description   string    // описание операции
+	CreatedAt     time.Time // время создания записи
+}
+
+// Posting представляет проводку — изменение баланса одного счёта.
+//
+// Положительная сумма увеличивает баланс счёта (кредит), отрицательная — уменьшает (дебет).
+type Posting struct {
+	ID             uint           `gorm:"primaryKey"`
+	JournalEntryID uint           `gorm:"index;not null"`  // запись журнала, к которой относится проводка
+	AccountID      uint           `gorm:"index;not null"`  // счёт проводки
+	Amount         money.Amount   `gorm:"not null"`        // сумма в минимальных единицах валюты счёта
+	Currency       money.Currency `gorm:"size:3;not null"` // валюта проводки ISO 4217
+	CreatedAt      time.Time      // время проводки
+}
@@ -0,0 +1,21 @@
+package database
+
+import (
+	"time"
+
+	"payment_system_api/money"
+)
+
+// WalletLimit представляет лимиты расходов кошелька.
+//
+// Суммы задаются в минимальных единицах валюты кошелька; nil — лимит не установлен.
+type WalletLimit struct {
+	ID            uint          `gorm:"primaryKey"`
+	WalletAddress string        `gorm:"unique;not null"` // кошелёк, к переводам с которого применяются лимиты
+	MaxTransfer   *money.Amount // максимальная сумма одного перевода
+	DailyTotal    *money.Amount // максимальная сумма переводов за календарные сутки UTC
+	MonthlyTotal  *money.Amount // максимальная сумма переводов за календарный месяц UTC
+	HourlyCount   *int          // максимальное число переводов за последний час
+	CreatedAt     time.Time     // время создания
+	UpdatedAt     time.Time     // время последнего изменения
+}
@@ -0,0 +1,288 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles содержит SQL-скрипты миграций: по каталогу на диалект,
+// в каждом — пары файлов <версия>_<название>.up.sql и <версия>_<название>.down.sql.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migration — версия схемы базы данных.
+type Migration struct {
+	Version int64  // номер версии, миграции применяются по возрастанию номеров
+	Name    string // название миграции
+	up      string // скрипт применения
+	down    string // скрипт отката
+}
+
+// MigrationStatus описывает состояние миграции в базе данных.
+type MigrationStatus struct {
+	Version   int64      // номер версии
+	Name      string     // название миграции
+	AppliedAt *time.Time // момент применения, nil — миграция не применена
+}
+
+// schemaMigration — запись о применённой миграции в таблице schema_migrations.
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// TableName возвращает имя таблицы применённых миграций.
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// createMigrationsTable создаёт таблицу schema_migrations, если её ещё нет.
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    bigint PRIMARY KEY,
+    name       text NOT NULL,
+    applied_at timestamp NOT NULL
+)`
+
+// Migrate применяет к базе данных DB все ещё не применённые миграции.
+//
+// При ошибке завершает работу программы.
+func Migrate() {
+	applied, err := MigrateUp(DB)
+	if err != nil {
+		log.Fatalf("Миграция базы данных не удалась %v", err)
+	}
+	log.Printf("Миграция базы данных успешна, применено миграций: %d", applied)
+}
+
+// MigrateUp применяет к базе данных db все ещё не применённые миграции
+// по возрастанию версий и возвращает их количество.
+//
+// Каждая миграция выполняется в отдельной транзакции под блокировкой миграций,
+// поэтому несколько экземпляров сервиса, запущенных одновременно,
+// применяют каждую миграцию ровно один раз.
+func MigrateUp(db *gorm.DB) (int, error) {
+	d := dialectOf(db)
+	migrations, err := loadMigrations(d)
+	if err != nil {
+		return 0, err
+	}
+	applied := 0
+	for _, m := range migrations {
+		done, err := runMigration(db, d, m, true)
+		if err != nil {
+			return applied, err
+		}
+		if done {
+			log.Printf("Применена миграция %04d_%s", m.Version, m.Name)
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// MigrateDown откатывает в базе данных db не более steps последних
+// применённых миграций и возвращает число откаченных.
+//
+// Миграция, которую между выбором и откатом успел откатить другой процесс,
+// считается выполненным шагом, но не входит в возвращаемое число: вместе
+// с ним откатывается не больше steps миграций.
+func MigrateDown(db *gorm.DB, steps int) (int, error) {
+	d := dialectOf(db)
+	migrations, err := loadMigrations(d)
+	if err != nil {
+		return 0, err
+	}
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	rolledBack := 0
+	for step := 0; step < steps; step++ {
+		applied, err := appliedMigrations(db)
+		if err != nil {
+			return rolledBack, err
+		}
+		if len(applied) == 0 {
+			break
+		}
+		var last schemaMigration
+		for _, record := range applied {
+			if record.Version > last.Version {
+				last = record
+			}
+		}
+		m, ok := byVersion[last.Version]
+		if !ok {
+			return rolledBack, fmt.Errorf("нет скриптов миграции %04d_%s", last.Version, last.Name)
+		}
+		done, err := runMigration(db, d, m, false)
+		if err != nil {
+			return rolledBack, err
+		}
+		if done {
+			log.Printf("Откачена миграция %04d_%s", m.Version, m.Name)
+			rolledBack++
+		}
+	}
+	return rolledBack, nil
+}
+
+// MigrationStatuses возвращает состояние всех известных миграций в базе данных db
+// по возрастанию версий.
+func MigrationStatuses(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations(dialectOf(db))
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// runMigration применяет (up) или откатывает миграцию m в отдельной транзакции.
+//
+// Состояние миграции проверяется уже под блокировкой, поэтому, если другой
+// процесс успел применить или откатить её, runMigration ничего не делает
+// и возвращает false.
+func runMigration(db *gorm.DB, d dialect, m Migration, up bool) (bool, error) {
+	done := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := d.lockMigrations(tx); err != nil {
+			return fmt.Errorf("не удалось захватить блокировку миграций: %w", err)
+		}
+		applied, err := appliedMigrations(tx)
+		if err != nil {
+			return err
+		}
+		if _, ok := applied[m.Version]; ok == up {
+			return nil
+		}
+
+		script := m.down
+		if up {
+			script = m.up
+		}
+		if err := tx.Exec(script).Error; err != nil {
+			return fmt.Errorf("миграция %04d_%s: %w", m.Version, m.Name, err)
+		}
+		if up {
+			err = tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: tx.NowFunc()}).Error
+		} else {
+			err = tx.Delete(&schemaMigration{Version: m.Version}).Error
+		}
+		if err != nil {
+			return err
+		}
+		done = true
+		return nil
+	})
+	return done, err
+}
+
+// appliedMigrations возвращает применённые миграции по версиям,
+// при необходимости создавая таблицу schema_migrations.
+func appliedMigrations(db *gorm.DB) (map[int64]schemaMigration, error) {
+	if err := db.Exec(createMigrationsTable).Error; err != nil {
+		return nil, fmt.Errorf("не удалось создать таблицу schema_migrations: %w", err)
+	}
+	var records []schemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]schemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// loadMigrations читает миграции диалекта d и возвращает их по возрастанию версий.
+//
+// Возвращает ошибку, если имя файла не соответствует формату, у миграции нет
+// одного из скриптов или две миграции имеют одну версию.
+func loadMigrations(d dialect) ([]Migration, error) {
+	dir := path.Join("migrations", d.name())
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		base, direction, ok := splitMigrationFile(entry.Name())
+		if !ok {
+			return nil, fmt.Errorf("некорректное имя файла миграции %q", entry.Name())
+		}
+		versionText, name, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionText, 10, 64)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("некорректное имя файла миграции %q", entry.Name())
+		}
+		script, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("версия миграции %d используется дважды: %q и %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.up = string(script)
+		} else {
+			m.down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("у миграции %04d_%s нет скрипта применения или отката", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitMigrationFile разбирает имя файла миграции на основу <версия>_<название>
+// и направление: "up" или "down".
+func splitMigrationFile(file string) (base, direction string, ok bool) {
+	base, ok = strings.CutSuffix(file, ".sql")
+	if !ok {
+		return "", "", false
+	}
+	for _, direction := range []string{"up", "down"} {
+		if base, ok := strings.CutSuffix(base, "."+direction); ok {
+			return base, direction, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// TestMigrations проверяет применение, откат и состояние миграций в базе SQLite.
+//
+// Тест выполняет следующие проверки:
+//   - MigrateUp применяет все миграции и создаёт таблицы;
+//   - повторный и параллельный вызов MigrateUp ничего не применяют;
+//   - MigrateDown откатывает последние миграции и удаляет таблицы;
+//   - MigrationStatuses отражает применённые и откаченные миграции.
+func TestMigrations(t *testing.T) {
+	db, err := open("sqlite://" + filepath.Join(t.TempDir(), "migrations.db"))
+	if err != nil {
+		t.Fatalf("Не удалось открыть базу данных: %v", err)
+	}
+	migrations, err := loadMigrations(dialectOf(db))
+	if err != nil {
+		t.Fatalf("Не удалось загрузить миграции: %v", err)
+	}
+
+	applied, err := MigrateUp(db)
+	if err != nil {
+		t.Fatalf("MigrateUp вернула ошибку: %v", err)
+	}
+	if applied != len(migrations) {
+		t.Errorf("Применено %d миграций, ожидалось %d", applied, len(migrations))
+	}
+	for _, model := range []any{&Wallet{}, &Transaction{}, &Account{}, &JournalEntry{}, &Posting{}} {
+		if !db.Migrator().HasTable(model) {
+			t.Errorf("Таблица %T не была создана", model)
+		}
+	}
+
+	// Параллельные вызовы не должны применять миграции повторно
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if applied, err := MigrateUp(db); err != nil || applied != 0 {
+				t.Errorf("Повторная MigrateUp: применено %d, ошибка %v", applied, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	rolledBack, err := MigrateDown(db, len(migrations)+1)
+	if err != nil {
+		t.Fatalf("MigrateDown вернула ошибку: %v", err)
+	}
+	if rolledBack != len(migrations) {
+		t.Errorf("Откачено %d миграций, ожидалось %d", rolledBack, len(migrations))
+	}
+	if db.Migrator().HasTable(&Wallet{}) {
+		t.Errorf("Таблица Wallet не была удалена")
+	}
+
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("MigrateUp после отката вернула ошибку: %v", err)
+	}
+	if _, err := MigrateDown(db, 1); err != nil {
+		t.Fatalf("MigrateDown вернула ошибку: %v", err)
+	}
+	statuses, err := MigrationStatuses(db)
+	if err != nil {
+		t.Fatalf("MigrationStatuses вернула ошибку: %v", err)
+	}
+	for i, status := range statuses {
+		last := i == len(statuses)-1
+		if (status.AppliedAt == nil) != last {
+			t.Errorf("Миграция %04d_%s: применена %v, ожидалось %v", status.Version, status.Name, status.AppliedAt != nil, !last)
+		}
+	}
+}
+
+// TestMigrateDownConcurrentRollback проверяет, что MigrateDown не откатывает
+// лишнюю миграцию, если выбранную ею миграцию одновременно откатил другой процесс.
+func TestMigrateDownConcurrentRollback(t *testing.T) {
+	db, err := open("sqlite://" + filepath.Join(t.TempDir(), "migrations.db"))
+	if err != nil {
+		t.Fatalf("Не удалось открыть базу данных: %v", err)
+	}
+	migrations, err := loadMigrations(dialectOf(db))
+	if err != nil {
+		t.Fatalf("Не удалось загрузить миграции: %v", err)
+	}
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("MigrateUp вернула ошибку: %v", err)
+	}
+
+	// Другой процесс откатывает последнюю миграцию сразу после того,
+	// как MigrateDown прочитала список применённых
+	last := migrations[len(migrations)-1]
+	triggered := false
+	err = db.Callback().Query().After("gorm:query").Register("test:concurrent_rollback", func(tx *gorm.DB) {
+		if triggered || tx.Statement.Table != "schema_migrations" {
+			return
+		}
+		triggered = true
+		if done, err := runMigration(db, dialectOf(db), last, false); err != nil || !done {
+			t.Errorf("Конкурентный откат: выполнен %v, ошибка %v", done, err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Не удалось зарегистрировать обработчик: %v", err)
+	}
+
+	rolledBack, err := MigrateDown(db, 1)
+	if err != nil || rolledBack != 0 {
+		t.Errorf("MigrateDown: откачено %d, ошибка %v; ожидалось 0", rolledBack, err)
+	}
+	if !triggered {
+		t.Fatalf("Конкурентный откат не выполнен")
+	}
+	statuses, err := MigrationStatuses(db)
+	if err != nil {
+		t.Fatalf("MigrationStatuses вернула ошибку: %v", err)
+	}
+	for i, status := range statuses {
+		last := i == len(statuses)-1
+		if (status.AppliedAt == nil) != last {
+			t.Errorf("Миграция %04d_%s: применена %v, ожидалось %v", status.Version, status.Name, status.AppliedAt != nil, !last)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+-- Исходная схема, которую раньше создавал AutoMigrate.
+-- Все объекты создаются условно, чтобы миграция применялась и к базам,
+-- созданным до появления версионных миграций.
+
+CREATE TABLE IF NOT EXISTS wallets (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    address    text NOT NULL,
+    balance    bigint,
+    currency   varchar(3) NOT NULL DEFAULT 'RUB',
+    CONSTRAINT uni_wallets_address UNIQUE (address)
+);
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT 'RUB';
+CREATE INDEX IF NOT EXISTS idx_wallets_deleted_at ON wallets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id              bigserial PRIMARY KEY,
+    from_address    text,
+    to_address      text,
+    amount          bigint,
+    "timestamp"     timestamptz,
+    uuid            text NOT NULL,
+    currency        varchar(3) NOT NULL DEFAULT 'RUB',
+    to_amount       bigint,
+    to_currency     varchar(3),
+    fx_rate         text,
+    fx_quoted_at    timestamptz,
+    idempotency_key text,
+    request_hash    text,
+    CONSTRAINT uni_transactions_uuid UNIQUE (uuid)
+);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS to_amount bigint;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS to_currency varchar(3);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fx_rate text;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fx_quoted_at timestamptz;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS idempotency_key text;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS request_hash text;
+CREATE INDEX IF NOT EXISTS idx_transactions_from_address ON transactions (from_address);
+CREATE INDEX IF NOT EXISTS idx_transactions_to_address ON transactions (to_address);
+CREATE INDEX IF NOT EXISTS idx_transactions_timestamp_id ON transactions ("timestamp", id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_idempotency_key ON transactions (idempotency_key);
+
+CREATE TABLE IF NOT EXISTS accounts (
+    id         bigserial PRIMARY KEY,
+    code       text NOT NULL,
+    kind       text NOT NULL,
+    currency   varchar(3) NOT NULL,
+    wallet_id  bigint,
+    created_at timestamptz,
+    CONSTRAINT uni_accounts_code UNIQUE (code)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_wallet_id ON accounts (wallet_id);
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id             bigserial PRIMARY KEY,
+    transaction_id bigint,
+    description    text,
+    created_at     timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_transaction_id ON journal_entries (transaction_id);
+
+CREATE TABLE IF NOT EXISTS postings (
+    id               bigserial PRIMARY KEY,
+    journal_entry_id bigint NOT NULL,
+    account_id       bigint NOT NULL,
+    amount           bigint NOT NULL,
+    currency         varchar(3) NOT NULL,
+    created_at       timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_postings_journal_entry_id ON postings (journal_entry_id);
+CREATE INDEX IF NOT EXISTS idx_postings_account_id ON postings (account_id);
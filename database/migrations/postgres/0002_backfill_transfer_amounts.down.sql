@@ -0,0 +1,2 @@
+-- Заполненные суммы зачисления совпадают с суммами списания
+-- и остаются корректными, поэтому откатывать нечего.
@@ -0,0 +1,10 @@
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS fk_transactions_to_address,
+    DROP CONSTRAINT IF EXISTS fk_transactions_from_address,
+    DROP CONSTRAINT IF EXISTS chk_transactions_to_amount,
+    DROP CONSTRAINT IF EXISTS chk_transactions_amount;
+
+ALTER TABLE wallets
+    DROP CONSTRAINT IF EXISTS chk_wallets_balance,
+    ALTER COLUMN balance DROP NOT NULL,
+    ALTER COLUMN balance DROP DEFAULT;
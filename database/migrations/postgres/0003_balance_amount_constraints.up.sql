@@ -0,0 +1,15 @@
+-- Инварианты, которые раньше проверялись только в коде:
+-- баланс кошелька не отрицателен, суммы транзакций положительны,
+-- а транзакции ссылаются на существующие кошельки.
+
+UPDATE wallets SET balance = 0 WHERE balance IS NULL;
+ALTER TABLE wallets
+    ALTER COLUMN balance SET DEFAULT 0,
+    ALTER COLUMN balance SET NOT NULL,
+    ADD CONSTRAINT chk_wallets_balance CHECK (balance >= 0);
+
+ALTER TABLE transactions
+    ADD CONSTRAINT chk_transactions_amount CHECK (amount > 0),
+    ADD CONSTRAINT chk_transactions_to_amount CHECK (to_amount > 0),
+    ADD CONSTRAINT fk_transactions_from_address FOREIGN KEY (from_address) REFERENCES wallets (address),
+    ADD CONSTRAINT fk_transactions_to_address FOREIGN KEY (to_address) REFERENCES wallets (address);
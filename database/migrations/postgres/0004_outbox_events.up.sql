@@ -0,0 +1,12 @@
+-- Исходящая очередь событий (transactional outbox).
+CREATE TABLE outbox_events (
+    id           bigserial PRIMARY KEY,
+    type         text NOT NULL,
+    payload      text NOT NULL,
+    created_at   timestamptz,
+    published_at timestamptz,
+    attempts     bigint NOT NULL DEFAULT 0,
+    last_error   text
+);
+-- Фоновая публикация выбирает неопубликованные события по возрастанию id.
+CREATE INDEX idx_outbox_events_pending ON outbox_events (id) WHERE published_at IS NULL;
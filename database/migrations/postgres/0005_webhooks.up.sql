@@ -0,0 +1,27 @@
+-- Вебхуки и очередь их доставок.
+CREATE TABLE webhooks (
+    id             bigserial PRIMARY KEY,
+    url            text NOT NULL,
+    wallet_address text REFERENCES wallets (address),
+    secret         text NOT NULL,
+    created_at     timestamptz
+);
+CREATE INDEX idx_webhooks_wallet_address ON webhooks (wallet_address);
+
+CREATE TABLE webhook_deliveries (
+    id              bigserial PRIMARY KEY,
+    webhook_id      bigint NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event           text NOT NULL,
+    wallet_address  text NOT NULL,
+    payload         text NOT NULL,
+    status          text NOT NULL CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts        bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL,
+    last_error      text NOT NULL DEFAULT '',
+    response_status bigint NOT NULL DEFAULT 0,
+    created_at      timestamptz,
+    delivered_at    timestamptz
+);
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
+-- Фоновая отправка выбирает ожидающие доставки по времени следующей попытки.
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,2 @@
+DROP TABLE holds;
+ALTER TABLE wallets DROP COLUMN held;
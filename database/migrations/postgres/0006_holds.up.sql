@@ -0,0 +1,26 @@
+-- Резервирование средств: зарезервированная сумма кошелька недоступна
+-- для переводов и не может превышать его баланс.
+ALTER TABLE wallets
+    ADD COLUMN held bigint NOT NULL DEFAULT 0,
+    ADD CONSTRAINT chk_wallets_held CHECK (held >= 0 AND held <= balance);
+
+CREATE TABLE holds (
+    id             bigserial PRIMARY KEY,
+    uuid           text NOT NULL,
+    wallet_address text NOT NULL REFERENCES wallets (address),
+    to_address     text NOT NULL REFERENCES wallets (address),
+    amount         bigint NOT NULL,
+    captured       bigint NOT NULL DEFAULT 0,
+    currency       varchar(3) NOT NULL,
+    status         text NOT NULL,
+    expires_at     timestamptz NOT NULL,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    CONSTRAINT uni_holds_uuid UNIQUE (uuid),
+    CONSTRAINT chk_holds_amount CHECK (amount > 0),
+    CONSTRAINT chk_holds_captured CHECK (captured >= 0 AND captured <= amount),
+    CONSTRAINT chk_holds_status CHECK (status IN ('active', 'captured', 'voided', 'expired'))
+);
+CREATE INDEX idx_holds_wallet_address ON holds (wallet_address);
+-- Фоновая задача выбирает истёкшие активные резервирования.
+CREATE INDEX idx_holds_expiring ON holds (expires_at) WHERE status = 'active';
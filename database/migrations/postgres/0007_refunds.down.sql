@@ -0,0 +1,4 @@
+DROP INDEX idx_transactions_refund_of;
+ALTER TABLE transactions
+    DROP COLUMN refunded,
+    DROP COLUMN refund_of;
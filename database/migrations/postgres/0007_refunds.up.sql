@@ -0,0 +1,8 @@
+-- Возвраты: возврат — перевод в обратном направлении со ссылкой на исходную
+-- транзакцию. Сумма возвратов транзакции не может превышать её сумму.
+ALTER TABLE transactions
+    ADD COLUMN refund_of text,
+    ADD COLUMN refunded bigint NOT NULL DEFAULT 0,
+    ADD CONSTRAINT fk_transactions_refund_of FOREIGN KEY (refund_of) REFERENCES transactions (uuid),
+    ADD CONSTRAINT chk_transactions_refunded CHECK (refunded >= 0 AND refunded <= amount);
+CREATE INDEX idx_transactions_refund_of ON transactions (refund_of);
@@ -0,0 +1,2 @@
+DROP INDEX idx_transactions_batch_id;
+ALTER TABLE transactions DROP COLUMN batch_id;
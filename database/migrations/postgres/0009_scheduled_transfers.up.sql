@@ -0,0 +1,24 @@
+-- Запланированные переводы: фоновая задача выполняет их в момент execute_at.
+CREATE TABLE scheduled_transfers (
+    id               bigserial PRIMARY KEY,
+    uuid             text NOT NULL,
+    from_address     text NOT NULL REFERENCES wallets (address),
+    to_address       text NOT NULL REFERENCES wallets (address),
+    amount           bigint NOT NULL,
+    currency         varchar(3) NOT NULL,
+    to_currency      varchar(3) NOT NULL,
+    execute_at       timestamptz NOT NULL,
+    status           text NOT NULL,
+    transaction_uuid text REFERENCES transactions (uuid),
+    error_code       text NOT NULL DEFAULT '',
+    error            text NOT NULL DEFAULT '',
+    executed_at      timestamptz,
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    CONSTRAINT uni_scheduled_transfers_uuid UNIQUE (uuid),
+    CONSTRAINT chk_scheduled_transfers_amount CHECK (amount > 0),
+    CONSTRAINT chk_scheduled_transfers_status CHECK (status IN ('pending', 'executed', 'failed', 'cancelled'))
+);
+CREATE INDEX idx_scheduled_transfers_from_address ON scheduled_transfers (from_address);
+-- Фоновая задача выбирает ожидающие переводы, время которых наступило.
+CREATE INDEX idx_scheduled_transfers_due ON scheduled_transfers (execute_at) WHERE status = 'pending';
@@ -0,0 +1,47 @@
+-- Регулярные платежи: фоновая задача выполняет их по cron-выражению или интервалу.
+CREATE TABLE standing_orders (
+    id                     bigserial PRIMARY KEY,
+    uuid                   text NOT NULL,
+    from_address           text NOT NULL REFERENCES wallets (address),
+    to_address             text NOT NULL REFERENCES wallets (address),
+    amount                 bigint NOT NULL,
+    currency               varchar(3) NOT NULL,
+    to_currency            varchar(3) NOT NULL,
+    cron                   text NOT NULL DEFAULT '',
+    interval_seconds       bigint NOT NULL DEFAULT 0,
+    start_at               timestamptz NOT NULL,
+    end_at                 timestamptz,
+    max_runs               integer NOT NULL DEFAULT 0,
+    max_retries            integer NOT NULL DEFAULT 0,
+    retry_interval_seconds bigint NOT NULL DEFAULT 0,
+    status                 text NOT NULL,
+    runs                   integer NOT NULL DEFAULT 0,
+    attempt                integer NOT NULL DEFAULT 0,
+    run_at                 timestamptz NOT NULL,
+    next_attempt_at        timestamptz NOT NULL,
+    created_at             timestamptz,
+    updated_at             timestamptz,
+    CONSTRAINT uni_standing_orders_uuid UNIQUE (uuid),
+    CONSTRAINT chk_standing_orders_amount CHECK (amount > 0),
+    CONSTRAINT chk_standing_orders_schedule CHECK ((cron <> '') <> (interval_seconds > 0)),
+    CONSTRAINT chk_standing_orders_status CHECK (status IN ('active', 'completed', 'cancelled'))
+);
+CREATE INDEX idx_standing_orders_from_address ON standing_orders (from_address);
+-- Фоновая задача выбирает активные платежи, время попытки которых наступило.
+CREATE INDEX idx_standing_orders_due ON standing_orders (next_attempt_at) WHERE status = 'active';
+
+-- История попыток выполнения регулярных платежей.
+CREATE TABLE standing_order_runs (
+    id                bigserial PRIMARY KEY,
+    standing_order_id bigint NOT NULL REFERENCES standing_orders (id),
+    run               integer NOT NULL,
+    attempt           integer NOT NULL,
+    scheduled_at      timestamptz NOT NULL,
+    status            text NOT NULL,
+    transaction_uuid  text REFERENCES transactions (uuid),
+    error_code        text NOT NULL DEFAULT '',
+    error             text NOT NULL DEFAULT '',
+    created_at        timestamptz,
+    CONSTRAINT chk_standing_order_runs_status CHECK (status IN ('executed', 'retrying', 'failed'))
+);
+CREATE INDEX idx_standing_order_runs_standing_order_id ON standing_order_runs (standing_order_id);
@@ -0,0 +1,3 @@
+DROP TABLE wallet_status_changes;
+ALTER TABLE wallets DROP COLUMN block_incoming;
+ALTER TABLE wallets DROP COLUMN status;
@@ -0,0 +1,7 @@
+-- Номер транзакции PostgreSQL, в которой создана запись. Идентификаторы выдаются
+-- при вставке и не совпадают с порядком фиксации, поэтому поток транзакций
+-- читает записи по (xid, id) и только из уже завершившихся транзакций.
+-- Существующие записи получают 0 и остаются в порядке идентификаторов.
+ALTER TABLE transactions ADD COLUMN xid bigint NOT NULL DEFAULT 0;
+ALTER TABLE transactions ALTER COLUMN xid SET DEFAULT pg_current_xact_id()::text::bigint;
+CREATE INDEX idx_transactions_xid_id ON transactions (xid, id);
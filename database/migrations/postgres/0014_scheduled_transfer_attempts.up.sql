@@ -0,0 +1,4 @@
+-- Число попыток выполнить запланированный перевод, прерванных внутренними
+-- ошибками: такие переводы выбираются после остальных и после нескольких
+-- попыток переходят в состояние failed, не задерживая очередь.
+ALTER TABLE scheduled_transfers ADD COLUMN attempts integer NOT NULL DEFAULT 0;
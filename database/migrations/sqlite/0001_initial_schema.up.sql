@@ -0,0 +1,66 @@
+-- Исходная схема, которую раньше создавал AutoMigrate.
+-- Все объекты создаются условно, чтобы миграция применялась и к базам,
+-- созданным до появления версионных миграций.
+
+CREATE TABLE IF NOT EXISTS wallets (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    address    text NOT NULL,
+    balance    integer,
+    currency   text NOT NULL DEFAULT 'RUB',
+    CONSTRAINT uni_wallets_address UNIQUE (address)
+);
+CREATE INDEX IF NOT EXISTS idx_wallets_deleted_at ON wallets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    from_address    text,
+    to_address      text,
+    amount          integer,
+    "timestamp"     datetime,
+    uuid            text NOT NULL,
+    currency        text NOT NULL DEFAULT 'RUB',
+    to_amount       integer,
+    to_currency     text,
+    fx_rate         text,
+    fx_quoted_at    datetime,
+    idempotency_key text,
+    request_hash    text,
+    CONSTRAINT uni_transactions_uuid UNIQUE (uuid)
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_from_address ON transactions (from_address);
+CREATE INDEX IF NOT EXISTS idx_transactions_to_address ON transactions (to_address);
+CREATE INDEX IF NOT EXISTS idx_transactions_timestamp_id ON transactions ("timestamp", id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_idempotency_key ON transactions (idempotency_key);
+
+CREATE TABLE IF NOT EXISTS accounts (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    code       text NOT NULL,
+    kind       text NOT NULL,
+    currency   text NOT NULL,
+    wallet_id  integer,
+    created_at datetime,
+    CONSTRAINT uni_accounts_code UNIQUE (code)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_wallet_id ON accounts (wallet_id);
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id             integer PRIMARY KEY AUTOINCREMENT,
+    transaction_id integer,
+    description    text,
+    created_at     datetime
+);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_transaction_id ON journal_entries (transaction_id);
+
+CREATE TABLE IF NOT EXISTS postings (
+    id               integer PRIMARY KEY AUTOINCREMENT,
+    journal_entry_id integer NOT NULL,
+    account_id       integer NOT NULL,
+    amount           integer NOT NULL,
+    currency         text NOT NULL,
+    created_at       datetime
+);
+CREATE INDEX IF NOT EXISTS idx_postings_journal_entry_id ON postings (journal_entry_id);
+CREATE INDEX IF NOT EXISTS idx_postings_account_id ON postings (account_id);
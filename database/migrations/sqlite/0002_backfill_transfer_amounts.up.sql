@@ -0,0 +1,5 @@
+-- Транзакции, созданные до появления переводов с конвертацией,
+-- зачисляются в той же сумме и валюте, что и списываются.
+UPDATE transactions
+SET to_amount = amount, to_currency = currency
+WHERE to_currency IS NULL OR to_currency = '';
@@ -0,0 +1,42 @@
+CREATE TABLE transactions_old (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    from_address    text,
+    to_address      text,
+    amount          integer,
+    "timestamp"     datetime,
+    uuid            text NOT NULL,
+    currency        text NOT NULL DEFAULT 'RUB',
+    to_amount       integer,
+    to_currency     text,
+    fx_rate         text,
+    fx_quoted_at    datetime,
+    idempotency_key text,
+    request_hash    text,
+    CONSTRAINT uni_transactions_uuid UNIQUE (uuid)
+);
+INSERT INTO transactions_old SELECT
+    id, from_address, to_address, amount, "timestamp", uuid, currency,
+    to_amount, to_currency, fx_rate, fx_quoted_at, idempotency_key, request_hash
+FROM transactions;
+DROP TABLE transactions;
+ALTER TABLE transactions_old RENAME TO transactions;
+CREATE INDEX idx_transactions_from_address ON transactions (from_address);
+CREATE INDEX idx_transactions_to_address ON transactions (to_address);
+CREATE INDEX idx_transactions_timestamp_id ON transactions ("timestamp", id);
+CREATE UNIQUE INDEX idx_transactions_idempotency_key ON transactions (idempotency_key);
+
+CREATE TABLE wallets_old (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    address    text NOT NULL,
+    balance    integer,
+    currency   text NOT NULL DEFAULT 'RUB',
+    CONSTRAINT uni_wallets_address UNIQUE (address)
+);
+INSERT INTO wallets_old (id, created_at, updated_at, deleted_at, address, balance, currency)
+SELECT id, created_at, updated_at, deleted_at, address, balance, currency FROM wallets;
+DROP TABLE wallets;
+ALTER TABLE wallets_old RENAME TO wallets;
+CREATE INDEX idx_wallets_deleted_at ON wallets (deleted_at);
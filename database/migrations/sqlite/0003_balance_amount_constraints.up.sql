@@ -0,0 +1,53 @@
+-- Инварианты, которые раньше проверялись только в коде:
+-- баланс кошелька не отрицателен, суммы транзакций положительны,
+-- а транзакции ссылаются на существующие кошельки.
+-- SQLite не умеет добавлять ограничения к существующей таблице,
+-- поэтому таблицы пересоздаются с переносом данных.
+
+CREATE TABLE wallets_new (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    address    text NOT NULL,
+    balance    integer NOT NULL DEFAULT 0,
+    currency   text NOT NULL DEFAULT 'RUB',
+    CONSTRAINT uni_wallets_address UNIQUE (address),
+    CONSTRAINT chk_wallets_balance CHECK (balance >= 0)
+);
+INSERT INTO wallets_new (id, created_at, updated_at, deleted_at, address, balance, currency)
+SELECT id, created_at, updated_at, deleted_at, address, COALESCE(balance, 0), currency FROM wallets;
+DROP TABLE wallets;
+ALTER TABLE wallets_new RENAME TO wallets;
+CREATE INDEX idx_wallets_deleted_at ON wallets (deleted_at);
+
+CREATE TABLE transactions_new (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    from_address    text,
+    to_address      text,
+    amount          integer,
+    "timestamp"     datetime,
+    uuid            text NOT NULL,
+    currency        text NOT NULL DEFAULT 'RUB',
+    to_amount       integer,
+    to_currency     text,
+    fx_rate         text,
+    fx_quoted_at    datetime,
+    idempotency_key text,
+    request_hash    text,
+    CONSTRAINT uni_transactions_uuid UNIQUE (uuid),
+    CONSTRAINT chk_transactions_amount CHECK (amount > 0),
+    CONSTRAINT chk_transactions_to_amount CHECK (to_amount > 0),
+    CONSTRAINT fk_transactions_from_address FOREIGN KEY (from_address) REFERENCES wallets (address),
+    CONSTRAINT fk_transactions_to_address FOREIGN KEY (to_address) REFERENCES wallets (address)
+);
+INSERT INTO transactions_new SELECT
+    id, from_address, to_address, amount, "timestamp", uuid, currency,
+    to_amount, to_currency, fx_rate, fx_quoted_at, idempotency_key, request_hash
+FROM transactions;
+DROP TABLE transactions;
+ALTER TABLE transactions_new RENAME TO transactions;
+CREATE INDEX idx_transactions_from_address ON transactions (from_address);
+CREATE INDEX idx_transactions_to_address ON transactions (to_address);
+CREATE INDEX idx_transactions_timestamp_id ON transactions ("timestamp", id);
+CREATE UNIQUE INDEX idx_transactions_idempotency_key ON transactions (idempotency_key);
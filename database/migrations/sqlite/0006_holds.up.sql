@@ -0,0 +1,25 @@
+-- Резервирование средств: зарезервированная сумма кошелька недоступна
+-- для переводов и не может превышать его баланс.
+ALTER TABLE wallets ADD COLUMN held integer NOT NULL DEFAULT 0
+    CONSTRAINT chk_wallets_held CHECK (held >= 0 AND held <= balance);
+
+CREATE TABLE holds (
+    id             integer PRIMARY KEY AUTOINCREMENT,
+    uuid           text NOT NULL,
+    wallet_address text NOT NULL REFERENCES wallets (address),
+    to_address     text NOT NULL REFERENCES wallets (address),
+    amount         integer NOT NULL,
+    captured       integer NOT NULL DEFAULT 0,
+    currency       text NOT NULL,
+    status         text NOT NULL,
+    expires_at     datetime NOT NULL,
+    created_at     datetime,
+    updated_at     datetime,
+    CONSTRAINT uni_holds_uuid UNIQUE (uuid),
+    CONSTRAINT chk_holds_amount CHECK (amount > 0),
+    CONSTRAINT chk_holds_captured CHECK (captured >= 0 AND captured <= amount),
+    CONSTRAINT chk_holds_status CHECK (status IN ('active', 'captured', 'voided', 'expired'))
+);
+CREATE INDEX idx_holds_wallet_address ON holds (wallet_address);
+-- Фоновая задача выбирает истёкшие активные резервирования.
+CREATE INDEX idx_holds_expiring ON holds (expires_at) WHERE status = 'active';
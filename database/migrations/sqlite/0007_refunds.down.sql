@@ -0,0 +1,3 @@
+DROP INDEX idx_transactions_refund_of;
+ALTER TABLE transactions DROP COLUMN refunded;
+ALTER TABLE transactions DROP COLUMN refund_of;
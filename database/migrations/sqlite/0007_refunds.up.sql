@@ -0,0 +1,7 @@
+-- Возвраты: возврат — перевод в обратном направлении со ссылкой на исходную
+-- транзакцию. Сумма возвратов транзакции не может превышать её сумму.
+ALTER TABLE transactions ADD COLUMN refund_of text
+    CONSTRAINT fk_transactions_refund_of REFERENCES transactions (uuid);
+ALTER TABLE transactions ADD COLUMN refunded integer NOT NULL DEFAULT 0
+    CONSTRAINT chk_transactions_refunded CHECK (refunded >= 0 AND refunded <= amount);
+CREATE INDEX idx_transactions_refund_of ON transactions (refund_of);
@@ -0,0 +1,4 @@
+-- Пакетные переводы: переводы пакета выполняются в одной транзакции
+-- и помечаются общим идентификатором пакета.
+ALTER TABLE transactions ADD COLUMN batch_id text;
+CREATE INDEX idx_transactions_batch_id ON transactions (batch_id);
@@ -0,0 +1,2 @@
+DROP TABLE standing_order_runs;
+DROP TABLE standing_orders;
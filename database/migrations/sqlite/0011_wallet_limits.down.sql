@@ -0,0 +1,2 @@
+DROP INDEX idx_transactions_from_address_timestamp;
+DROP TABLE wallet_limits;
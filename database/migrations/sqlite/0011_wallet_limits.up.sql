@@ -0,0 +1,21 @@
+-- Лимиты расходов кошельков, NULL — лимит не установлен.
+CREATE TABLE wallet_limits (
+    id             integer PRIMARY KEY AUTOINCREMENT,
+    wallet_address text NOT NULL REFERENCES wallets (address),
+    max_transfer   integer,
+    daily_total    integer,
+    monthly_total  integer,
+    hourly_count   integer,
+    created_at     datetime,
+    updated_at     datetime,
+    CONSTRAINT uni_wallet_limits_wallet_address UNIQUE (wallet_address),
+    CONSTRAINT chk_wallet_limits_positive CHECK (
+        (max_transfer IS NULL OR max_transfer > 0) AND
+        (daily_total IS NULL OR daily_total > 0) AND
+        (monthly_total IS NULL OR monthly_total > 0) AND
+        (hourly_count IS NULL OR hourly_count > 0)
+    )
+);
+
+-- Суммы и число переводов кошелька за период считаются при каждом переводе.
+CREATE INDEX idx_transactions_from_address_timestamp ON transactions (from_address, timestamp);
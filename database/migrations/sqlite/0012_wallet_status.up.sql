@@ -0,0 +1,21 @@
+-- Состояние кошелька: замороженный кошелёк не может отправлять переводы,
+-- а при block_incoming — и получать их.
+ALTER TABLE wallets ADD COLUMN status text NOT NULL DEFAULT 'active'
+    CONSTRAINT chk_wallets_status CHECK (status IN ('active', 'frozen', 'closed'));
+ALTER TABLE wallets ADD COLUMN block_incoming boolean NOT NULL DEFAULT false;
+
+-- Ранее закрытые кошельки
+UPDATE wallets SET status = 'closed' WHERE deleted_at IS NOT NULL;
+
+-- История изменений состояния кошельков с причиной и исполнителем.
+CREATE TABLE wallet_status_changes (
+    id             integer PRIMARY KEY AUTOINCREMENT,
+    wallet_address text NOT NULL REFERENCES wallets (address),
+    from_status    text NOT NULL,
+    to_status      text NOT NULL,
+    block_incoming boolean NOT NULL,
+    reason         text NOT NULL,
+    actor          text NOT NULL,
+    created_at     datetime
+);
+CREATE INDEX idx_wallet_status_changes_wallet_address ON wallet_status_changes (wallet_address);
@@ -0,0 +1,2 @@
+DROP INDEX idx_transactions_xid_id;
+ALTER TABLE transactions DROP COLUMN xid;
@@ -0,0 +1,5 @@
+-- Порядок записей для потока транзакций. Транзакции SQLite выполняются
+-- строго по очереди, поэтому порядок идентификаторов совпадает с порядком
+-- фиксации и xid всегда 0; колонка нужна для общего с PostgreSQL запроса.
+ALTER TABLE transactions ADD COLUMN xid integer NOT NULL DEFAULT 0;
+CREATE INDEX idx_transactions_xid_id ON transactions (xid, id);
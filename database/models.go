@@ -7,31 +7,80 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+
+	"payment_system_api/money"
+)
+
+// Состояния кошелька.
+const (
+	WalletActive = "active" // кошелёк участвует в переводах
+	WalletFrozen = "frozen" // исходящие переводы запрещены, входящие — если установлен BlockIncoming
+	WalletClosed = "closed" // кошелёк закрыт и помечен удалённым через DeletedAt
 )
 
 // Wallet представляет модель кошелёка в базе данных.
-// Содержит уникальный адрес и текущий баланс.
+// Содержит уникальный адрес, валюту, текущий баланс и состояние.
 type Wallet struct {
 	gorm.Model
-	Address string `gorm:"unique;not null"` // Address уникальный адрес кошелька, используемый при идентификации
-	Balance int64  //Balance текущий баланс кошелька
+	Address  string         `gorm:"unique;not null"` // Address уникальный адрес кошелька, используемый при идентификации
+	Balance  money.Amount   //Balance текущий баланс кошелька в минимальных единицах валюты
+	Held     money.Amount   `gorm:"not null;default:0"`            // Held сумма активных резервирований, недоступная для переводов
+	Currency money.Currency `gorm:"size:3;not null;default:'RUB'"` // Currency код валюты кошелька ISO 4217
+
+	Status        string `gorm:"not null;default:'active'"` // Status состояние кошелька: WalletActive, WalletFrozen или WalletClosed
+	BlockIncoming bool   `gorm:"not null;default:false"`    // BlockIncoming замороженный кошелёк не принимает и входящие переводы
+}
+
+// WalletStatusChange — запись об изменении состояния кошелька.
+type WalletStatusChange struct {
+	ID            uint      `gorm:"primaryKey"`
+	WalletAddress string    `gorm:"index;not null"` // адрес кошелька
+	FromStatus    string    `gorm:"not null"`       // прежнее состояние
+	ToStatus      string    `gorm:"not null"`       // новое состояние
+	BlockIncoming bool      `gorm:"not null"`       // блокировка входящих переводов после изменения
+	Reason        string    `gorm:"not null"`       // причина изменения
+	Actor         string    `gorm:"not null"`       // кто выполнил изменение
+	CreatedAt     time.Time // время изменения
 }
 
 // Transaction представляет собой модель транзакции в базе данных
 // Содержит адрес отправителя, адрес получателя, сумму, временную метку и UUID.
 type Transaction struct {
-	ID          uint      `gorm:"primaryKey" json:"-"`         // идентификатор записи
-	FromAddress string    `json:"from_address"`                // адрес отправителя
-	ToAddress   string    `json:"to_address"`                  // адрес получателя
-	Amount      int64     `json:"amount"`                      // сумма перевода
-	Timestamp   time.Time `json:"timestamp"`                   // время создания транзакции
-	UUID        string    `gorm:"unique;not null" json:"uuid"` // уникальный идентификатор транзакции
+	ID          uint         `gorm:"primaryKey;index:idx_transactions_timestamp_id,priority:2" json:"-"` // идентификатор записи
+	FromAddress string       `gorm:"index" json:"from_address"`                                          // адрес отправителя
+	ToAddress   string       `gorm:"index" json:"to_address"`                                            // адрес получателя
+	Amount      money.Amount `json:"amount"`                                                             // сумма перевода в минимальных единицах валюты
+	Timestamp   time.Time    `gorm:"index:idx_transactions_timestamp_id,priority:1" json:"timestamp"`    // время создания транзакции
+	UUID        string       `gorm:"unique;not null" json:"uuid"`                                        // уникальный идентификатор транзакции
+
+	Currency money.Currency `gorm:"size:3;not null;default:'RUB'" json:"currency"` // валюта перевода ISO 4217
+
+	// Поля конвертации. Для переводов в одной валюте ToAmount и ToCurrency
+	// совпадают с Amount и Currency, а FXRate и FXQuotedAt пустые.
+	ToAmount   money.Amount   `json:"to_amount"`                 // сумма зачисления в минимальных единицах валюты получателя
+	ToCurrency money.Currency `gorm:"size:3" json:"to_currency"` // валюта зачисления ISO 4217
+	FXRate     string         `json:"fx_rate,omitempty"`         // курс конвертации: единиц ToCurrency за единицу Currency
+	FXQuotedAt *time.Time     `json:"fx_quoted_at,omitempty"`    // момент котировки использованного курса
+
+	// Поля возвратов. Возврат — перевод в обратном направлении со ссылкой
+	// на исходную транзакцию; сумма возвратов не превышает её сумму.
+	RefundOf *string      `gorm:"index" json:"refund_of,omitempty"`   // UUID исходной транзакции, NULL для обычных переводов
+	Refunded money.Amount `gorm:"not null;default:0" json:"refunded"` // сумма возвратов в минимальных единицах валюты перевода
+
+	BatchID *string `gorm:"index" json:"batch_id,omitempty"` // идентификатор пакета переводов, NULL для одиночных переводов
+
+	IdempotencyKey *string `gorm:"uniqueIndex" json:"-"` // ключ идемпотентности запроса, NULL если не передан или истёк
+	RequestHash    string  `json:"-"`                    // хеш тела запроса, выполненного с ключом идемпотентности
+
+	// XID — номер транзакции PostgreSQL, в которой создана запись; заполняется
+	// базой данных, в SQLite и хранилище в памяти всегда 0 (см. CommitPosition).
+	XID uint64 `gorm:"column:xid;->" json:"-"`
 }
 
 // BeforeCreate - метод, который автоматически генерирует уникальный UUID и
 // устанавливает временную метку перед сохранением транзакции
 func (t *Transaction) BeforeCreate(tx *gorm.DB) (err error) {
 	t.UUID = uuid.New().String()
-	t.Timestamp = time.Now()
+	t.Timestamp = tx.NowFunc()
 	return
 }
@@ -0,0 +1,18 @@
+package database
+
+import "time"
+
+// OutboxEvent представляет событие в исходящей очереди (transactional outbox).
+//
+// Событие записывается в той же транзакции, что и изменение, о котором оно
+// сообщает, и публикуется позже фоновой задачей. Опубликованные события
+// остаются в таблице с заполненным PublishedAt.
+type OutboxEvent struct {
+	ID          uint       `gorm:"primaryKey"`
+	Type        string     `gorm:"not null"` // тип события, например "TransferCompleted"
+	Payload     string     `gorm:"not null"` // данные события в JSON
+	CreatedAt   time.Time  // время возникновения события
+	PublishedAt *time.Time // время публикации, NULL — событие ещё не опубликовано
+	Attempts    int        `gorm:"not null;default:0"` // число неудачных попыток публикации
+	LastError   string     // ошибка последней неудачной попытки
+}
@@ -7,43 +7,68 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log"
+	"time"
 
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 // DB глобальная переменная для подключения к базе данных
 var DB *gorm.DB
 
-// ConnectDB устанавливает соединение с базой данных Postgres.
+// ConnectDB устанавливает соединение с базой данных.
 //
-// Параметр dsn — строка подключения к базе данных.
+// Параметр dsn — строка подключения к базе данных. Драйвер выбирается
+// по её схеме: sqlite://<путь к файлу> — SQLite, остальные строки — PostgreSQL.
 // В случае ошибки завершает работу программы.
 func ConnectDB(dsn string) {
 	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = open(dsn)
 	if err != nil {
 		log.Fatalf("Не удалось подключиться к базе данных: %v", err)
 	}
 	log.Println("Подключение к базе данных выполнено успешно")
 }
 
-// Migrate выполняет  миграцию базы данных.
+// open подключается к базе данных dsn и настраивает пул соединений для её диалекта.
 //
-// Создает таблицы Wallet и Transaction, если они ещё не существуют.
-// При ошибке завершает работу программы.
-func Migrate() {
-	err := DB.AutoMigrate(&Wallet{}, &Transaction{})
+// Время сохраняется в UTC, чтобы моменты времени одинаково сравнивались в обеих СУБД.
+func open(dsn string) (*gorm.DB, error) {
+	dialect, driverDSN := parseDSN(dsn)
+	db, err := gorm.Open(dialect.open(driverDSN), &gorm.Config{
+		TranslateError: true,
+		NowFunc:        func() time.Time { return time.Now().UTC() },
+	})
 	if err != nil {
-		log.Fatalf("Миграция базы данных не удалась %v", err)
+		return nil, err
 	}
-	log.Println("Миграция базы данных успешна")
+	pool, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	dialect.configure(pool)
+	return db, nil
+}
+
+// IsRetryableError сообщает, можно ли повторить транзакцию, завершившуюся ошибкой err.
+//
+// Повторять имеет смысл транзакции, прерванные из-за ошибки сериализации
+// или взаимоблокировки в PostgreSQL и из-за занятости базы данных в SQLite.
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, d := range dialects {
+		if d.isRetryable(err) {
+			return true
+		}
+	}
+	return false
 }
 
-// generateWalletAddress генерирует уникальный адрес для кошелька.
+// GenerateWalletAddress генерирует уникальный адрес для кошелька.
 //
 // Возвращает строку в hex формате или ошибку при генерации.
-func generateWalletAddress() (string, error) {
+func GenerateWalletAddress() (string, error) {
 	bytes := make([]byte, 32)
 
 	_, err := rand.Read(bytes)
@@ -53,27 +78,30 @@ func generateWalletAddress() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-// InitialSetup создает начальные кошельки в базе данных.
+// InitialSetup создает начальные кошельки в хранилище store.
 //
-// Если кошельки уже существуют, выводит их количество.
+// Если кошельки уже существуют (в том числе закрытые), выводит их количество.
 // Если нет — создаёт 10 кошельков с балансом 10000 каждый.
-func InitialSetup() {
-	var count int64
-	DB.Model(&Wallet{}).Count(&count)
+func InitialSetup(store Store) {
+	count, err := store.Wallets().CountUnscoped()
+	if err != nil {
+		log.Fatalf("Не удалось посчитать кошельки: %v", err)
+	}
 	if count == 0 {
 		fmt.Println("Создание начальных кошельков")
-		wallets := make([]Wallet, 10)
-		for i := 0; i < 10; i++ {
-			address, err := generateWalletAddress()
-			if err != nil {
-				log.Fatalf("Не удалось сгенерировать адрес кошелька: %v", err)
-			}
-			wallets[i] = Wallet{
-				Address: address,
-				Balance: 10000,
+		err := store.InTransaction(func(tx Store) error {
+			for i := 0; i < 10; i++ {
+				address, err := GenerateWalletAddress()
+				if err != nil {
+					return err
+				}
+				if err := tx.Wallets().Create(&Wallet{Address: address, Balance: 10000}); err != nil {
+					return err
+				}
 			}
-		}
-		if err := DB.Create(&wallets).Error; err != nil {
+			return nil
+		})
+		if err != nil {
 			log.Fatalf("Не удалось создать начальные кошельки: %v", err)
 		}
 		fmt.Println("10 начальных кошельков созданы")
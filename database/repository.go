@@ -0,0 +1,346 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"payment_system_api/money"
+)
+
+// Ошибки хранилища, общие для всех реализаций репозиториев.
+var (
+	// ErrNotFound — запись не найдена.
+	ErrNotFound = errors.New("запись не найдена")
+	// ErrDuplicate — запись нарушает ограничение уникальности.
+	ErrDuplicate = errors.New("запись нарушает ограничение уникальности")
+	// ErrNegativeBalance — баланс кошелька стал бы отрицательным.
+	ErrNegativeBalance = errors.New("баланс кошелька не может быть отрицательным")
+	// ErrNonPositiveAmount — сумма транзакции не положительна.
+	ErrNonPositiveAmount = errors.New("сумма транзакции должна быть положительной")
+	// ErrUnknownWallet — транзакция ссылается на несуществующий кошелёк.
+	ErrUnknownWallet = errors.New("кошелёк транзакции не существует")
+	// ErrRefundExceedsAmount — сумма возвратов превысила бы сумму транзакции.
+	ErrRefundExceedsAmount = errors.New("сумма возвратов не может превышать сумму транзакции")
+)
+
+// Store — хранилище данных платёжной системы.
+//
+// Репозитории, полученные от Store вне транзакции, выполняют каждую операцию
+// отдельно. Операции, которые должны выполняться атомарно, выполняются
+// через InTransaction.
+type Store interface {
+	Wallets() WalletRepository           // репозиторий кошельков
+	Transactions() TransactionRepository // репозиторий транзакций
+	Ledger() LedgerRepository            // репозиторий главной книги
+	Outbox() OutboxRepository            // исходящая очередь событий
+	Webhooks() WebhookRepository         // репозиторий вебхуков и их доставок
+	Holds() HoldRepository               // репозиторий резервирований средств
+
+	ScheduledTransfers() ScheduledTransferRepository // репозиторий запланированных переводов
+	StandingOrders() StandingOrderRepository         // репозиторий регулярных платежей
+	Limits() WalletLimitRepository                   // репозиторий лимитов расходов кошельков
+
+	// InTransaction выполняет fn в транзакции хранилища.
+	//
+	// Все операции репозиториев tx выполняются в этой транзакции.
+	// Если fn возвращает ошибку, изменения откатываются и ошибка возвращается.
+	InTransaction(fn func(tx Store) error) error
+}
+
+// WalletRepository — хранилище кошельков.
+//
+// Методы поиска возвращают ErrNotFound, если кошелек не найден.
+// Закрытые кошельки не находятся, если не сказано иное.
+type WalletRepository interface {
+	// Create сохраняет новый кошелёк и заполняет его идентификатор.
+	// Если адрес уже занят — ErrDuplicate, если баланс отрицателен — ErrNegativeBalance.
+	Create(wallet *Wallet) error
+	// FindByAddress возвращает открытый кошелёк по адресу.
+	FindByAddress(address string) (*Wallet, error)
+	// FindByAddressUnscoped возвращает кошелёк по адресу, в том числе закрытый.
+	FindByAddressUnscoped(address string) (*Wallet, error)
+	// LockByAddress возвращает открытый кошелёк по адресу и блокирует его
+	// от изменения другими транзакциями до конца текущей.
+	LockByAddress(address string) (*Wallet, error)
+	// List возвращает не более limit открытых кошельков, начиная с offset,
+	// в порядке открытия.
+	List(offset, limit int) ([]Wallet, error)
+	// Count возвращает число открытых кошельков.
+	Count() (int64, error)
+	// CountUnscoped возвращает число кошельков, включая закрытые.
+	CountUnscoped() (int64, error)
+	// AddBalance изменяет баланс кошелька id на delta.
+	// Если баланс стал бы отрицательным или меньше зарезервированной
+	// суммы — ErrNegativeBalance.
+	AddBalance(id uint, delta money.Amount) error
+	// AddHeld изменяет зарезервированную сумму кошелька id на delta.
+	// Если она стала бы отрицательной или больше баланса — ErrNegativeBalance.
+	AddHeld(id uint, delta money.Amount) error
+	// Close закрывает кошелёк, сохраняя его историю.
+	Close(wallet *Wallet) error
+	// UpdateStatus сохраняет состояние кошелька и блокировку входящих переводов.
+	UpdateStatus(wallet *Wallet) error
+	// RecordStatusChange сохраняет запись об изменении состояния кошелька.
+	RecordStatusChange(change *WalletStatusChange) error
+	// FindStatusChanges возвращает изменения состояния кошелька address,
+	// в том числе закрытого, в порядке их записи.
+	FindStatusChanges(address string) ([]WalletStatusChange, error)
+}
+
+// TransactionRepository — хранилище транзакций.
+type TransactionRepository interface {
+	// Create сохраняет новую транзакцию, заполняя её идентификатор, UUID и время создания.
+	// Если ключ идемпотентности уже занят — ErrDuplicate, если сумма списания
+	// или зачисления не положительна — ErrNonPositiveAmount, если кошелька
+	// отправителя или получателя не существует — ErrUnknownWallet.
+	Create(transaction *Transaction) error
+	// FindByIdempotencyKey возвращает транзакцию с ключом идемпотентности key
+	// или ErrNotFound.
+	FindByIdempotencyKey(key string) (*Transaction, error)
+	// FindByUUID возвращает транзакцию по UUID или ErrNotFound.
+	FindByUUID(uuid string) (*Transaction, error)
+	// LockByUUID возвращает транзакцию по UUID или ErrNotFound и блокирует её
+	// от изменения другими транзакциями до конца текущей.
+	LockByUUID(uuid string) (*Transaction, error)
+	// AddRefunded изменяет сумму возвратов транзакции id на delta.
+	// Если она стала бы отрицательной или больше суммы транзакции — ErrRefundExceedsAmount.
+	AddRefunded(id uint, delta money.Amount) error
+	// FindRefunds возвращает возвраты транзакций с UUID из uuids
+	// в порядке возрастания идентификатора.
+	FindRefunds(uuids []string) ([]Transaction, error)
+	// SumOutgoing возвращает сумму в минимальных единицах валюты отправителя
+	// и число переводов с кошелька address, созданных не раньше since.
+	// Возвраты не учитываются.
+	SumOutgoing(address string, since time.Time) (money.Amount, int, error)
+	// ReleaseIdempotencyKey очищает ключ идемпотентности транзакции id.
+	ReleaseIdempotencyKey(id uint) error
+	// ReleaseIdempotencyKeys очищает ключи идемпотентности транзакций,
+	// созданных не позже cutoff, и возвращает их количество.
+	ReleaseIdempotencyKeys(cutoff time.Time) (int64, error)
+	// Find возвращает транзакции, выбранные query,
+	// в порядке убывания времени создания и идентификатора.
+	Find(query TransactionQuery) ([]Transaction, error)
+	// FindAfter возвращает не более limit зафиксированных транзакций
+	// с позицией фиксации больше after в порядке возрастания позиции.
+	// Если address не пуст — только входящие и исходящие переводы кошелька address.
+	// Транзакция, зафиксированная позже, никогда не получает позицию
+	// меньше уже возвращённых.
+	FindAfter(after CommitPosition, address string, limit int) ([]Transaction, error)
+	// LastCommitPosition возвращает позицию фиксации последней транзакции,
+	// которую вернул бы FindAfter, или нулевую позицию, если транзакций нет.
+	LastCommitPosition() (CommitPosition, error)
+}
+
+// LedgerRepository — хранилище главной книги.
+type LedgerRepository interface {
+	// FindWalletAccount возвращает счёт кошелька walletID или ErrNotFound.
+	FindWalletAccount(walletID uint) (*Account, error)
+	// CreateAccount сохраняет новый счёт.
+	CreateAccount(account *Account) error
+	// SystemAccount возвращает системный счёт вида kind в валюте currency,
+	// открывая его при необходимости.
+	SystemAccount(kind string, currency money.Currency) (*Account, error)
+	// CreateEntry сохраняет запись журнала entry вместе с её проводками postings.
+	// Поле JournalEntryID проводок заполняется автоматически.
+	CreateEntry(entry *JournalEntry, postings []Posting) error
+	// WalletsWithoutAccount возвращает открытые кошельки, у которых ещё нет счёта.
+	WalletsWithoutAccount() ([]Wallet, error)
+	// BalanceMismatches возвращает открытые кошельки, баланс которых
+	// не совпадает с суммой проводок их счёта.
+	BalanceMismatches() ([]BalanceMismatch, error)
+	// UnbalancedEntryIDs возвращает записи журнала, сумма проводок которых
+	// в какой-либо валюте не равна нулю.
+	UnbalancedEntryIDs() ([]uint, error)
+}
+
+// OutboxRepository — исходящая очередь событий.
+type OutboxRepository interface {
+	// Create сохраняет новое событие, заполняя его идентификатор и время создания.
+	Create(event *OutboxEvent) error
+	// TryLock захватывает право публиковать события до конца текущей транзакции.
+	// Возвращает false, если его уже удерживает другая транзакция; в этом случае
+	// TryLock не ждёт её завершения.
+	TryLock() (bool, error)
+	// Pending возвращает не более limit неопубликованных событий по возрастанию идентификатора.
+	Pending(limit int) ([]OutboxEvent, error)
+	// MarkPublished отмечает событие id опубликованным в момент at.
+	MarkPublished(id uint, at time.Time) error
+	// MarkFailed увеличивает число попыток публикации события id
+	// и сохраняет описание ошибки reason.
+	MarkFailed(id uint, reason string) error
+}
+
+// WebhookRepository — хранилище вебхуков и их доставок.
+//
+// Методы поиска возвращают ErrNotFound, если запись не найдена.
+type WebhookRepository interface {
+	// Create сохраняет новый вебхук.
+	Create(webhook *Webhook) error
+	// Find возвращает вебхук id.
+	Find(id uint) (*Webhook, error)
+	// List возвращает все вебхуки в порядке регистрации.
+	List() ([]Webhook, error)
+	// Delete удаляет вебхук id вместе с его доставками.
+	Delete(id uint) error
+	// Subscribers возвращает вебхуки кошелька address и вебхуки всех кошельков
+	// в порядке регистрации.
+	Subscribers(address string) ([]Webhook, error)
+
+	// CreateDelivery сохраняет новую доставку.
+	CreateDelivery(delivery *WebhookDelivery) error
+	// FindDelivery возвращает доставку id.
+	FindDelivery(id uint) (*WebhookDelivery, error)
+	// ListDeliveries возвращает не более limit доставок, выбранных query,
+	// начиная с offset, от новых к старым.
+	ListDeliveries(query DeliveryQuery, offset, limit int) ([]WebhookDelivery, error)
+	// CountDeliveries возвращает число доставок, выбранных query.
+	CountDeliveries(query DeliveryQuery) (int64, error)
+	// ClaimDeliveries выбирает не более limit ожидающих доставок, время попытки
+	// которых наступило к моменту now, и переносит их следующую попытку на now+lease,
+	// чтобы другие процессы не отправили их одновременно. Доставки, заблокированные
+	// другими транзакциями, пропускаются. Вызывается в транзакции.
+	ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error)
+	// UpdateDelivery сохраняет состояние доставки: статус, попытки, время следующей
+	// попытки, ошибку, код ответа и время доставки.
+	UpdateDelivery(delivery *WebhookDelivery) error
+	// DeleteDelivery удаляет доставку id.
+	DeleteDelivery(id uint) error
+}
+
+// DeliveryQuery задаёт выборку доставок вебхуков.
+// Нулевые значения полей означают отсутствие соответствующего условия.
+type DeliveryQuery struct {
+	WebhookID uint   // доставки вебхука
+	Status    string // доставки в состоянии Status
+}
+
+// HoldRepository — хранилище резервирований средств.
+//
+// Методы поиска возвращают ErrNotFound, если резервирование не найдено.
+type HoldRepository interface {
+	// Create сохраняет новое резервирование, заполняя его идентификатор, UUID
+	// и время создания. Если сумма не положительна — ErrNonPositiveAmount,
+	// если кошелька не существует — ErrUnknownWallet.
+	Create(hold *Hold) error
+	// FindByUUID возвращает резервирование по UUID.
+	FindByUUID(uuid string) (*Hold, error)
+	// LockByUUID возвращает резервирование по UUID и блокирует его
+	// от изменения другими транзакциями до конца текущей.
+	LockByUUID(uuid string) (*Hold, error)
+	// LockExpired возвращает не более limit активных резервирований, срок которых
+	// истёк к моменту now, в порядке возрастания идентификатора и блокирует их,
+	// пропуская заблокированные другими транзакциями.
+	LockExpired(now time.Time, limit int) ([]Hold, error)
+	// SumActive возвращает несписанный остаток активных резервирований
+	// кошелька address, созданных не раньше since.
+	SumActive(address string, since time.Time) (money.Amount, error)
+	// Update сохраняет списанную сумму и состояние резервирования.
+	Update(hold *Hold) error
+}
+
+// ScheduledTransferRepository — хранилище запланированных переводов.
+//
+// Методы поиска возвращают ErrNotFound, если перевод не найден.
+type ScheduledTransferRepository interface {
+	// Create сохраняет новый запланированный перевод, заполняя его идентификатор,
+	// UUID и время создания. Если сумма не положительна — ErrNonPositiveAmount,
+	// если кошелька не существует — ErrUnknownWallet.
+	Create(transfer *ScheduledTransfer) error
+	// FindByUUID возвращает запланированный перевод по UUID.
+	FindByUUID(uuid string) (*ScheduledTransfer, error)
+	// LockByUUID возвращает запланированный перевод по UUID и блокирует его
+	// от изменения другими транзакциями до конца текущей.
+	LockByUUID(uuid string) (*ScheduledTransfer, error)
+	// LockDue возвращает не более limit ожидающих переводов, время выполнения
+	// которых наступило к моменту now, и блокирует их, пропуская заблокированные
+	// другими транзакциями. Переводы упорядочены по числу прерванных попыток,
+	// затем по времени выполнения: перевод, который не удаётся выполнить,
+	// не задерживает остальные.
+	LockDue(now time.Time, limit int) ([]ScheduledTransfer, error)
+	// Update сохраняет состояние и результат выполнения перевода.
+	Update(transfer *ScheduledTransfer) error
+}
+
+// TransactionQuery задаёт выборку транзакций.
+// Нулевые значения полей означают отсутствие соответствующего условия.
+type TransactionQuery struct {
+	// Address ограничивает выборку переводами кошелька Address:
+	// исходящими, если задан Outgoing, и входящими, если задан Incoming.
+	// Пустая строка — все транзакции.
+	Address  string
+	Outgoing bool
+	Incoming bool
+
+	// Границы суммы, изменившей баланс кошелька Address:
+	// для исходящих переводов — Amount, для входящих — ToAmount.
+	MinAmount *money.Amount
+	MaxAmount *money.Amount
+
+	Since  *time.Time // транзакции не раньше этого момента
+	Until  *time.Time // транзакции раньше этого момента
+	Before *Position  // транзакции, идущие в порядке выборки после этой позиции
+	Limit  int        // максимальное число транзакций, 0 — без ограничения
+}
+
+// Position — позиция транзакции в порядке убывания (Timestamp, ID).
+type Position struct {
+	Timestamp time.Time
+	ID        uint
+}
+
+// CommitPosition — позиция транзакции в порядке фиксации (XID, ID).
+//
+// Идентификаторы выдаются при вставке, и в PostgreSQL конкурентные транзакции
+// могут зафиксироваться не в порядке идентификаторов. Поэтому позиция
+// начинается с номера транзакции базы данных; в SQLite и хранилище в памяти
+// транзакции выполняются по очереди, XID равен 0 и порядок задаёт ID.
+type CommitPosition struct {
+	XID uint64
+	ID  uint
+}
+
+// BalanceMismatch описывает кошелёк, баланс которого не совпадает с суммой проводок его счёта.
+type BalanceMismatch struct {
+	Address  string         // адрес кошелька
+	Currency money.Currency // валюта кошелька
+	Balance  money.Amount   // баланс, сохранённый в кошельке
+	Ledger   money.Amount   // сумма проводок счёта кошелька
+}
+
+// StandingOrderRepository — хранилище регулярных платежей и истории их выполнения.
+//
+// Методы поиска возвращают ErrNotFound, если платёж не найден.
+type StandingOrderRepository interface {
+	// Create сохраняет новый регулярный платёж, заполняя его идентификатор,
+	// UUID и время создания. Если сумма не положительна — ErrNonPositiveAmount,
+	// если кошелька не существует — ErrUnknownWallet.
+	Create(order *StandingOrder) error
+	// FindByUUID возвращает регулярный платёж по UUID.
+	FindByUUID(uuid string) (*StandingOrder, error)
+	// LockByUUID возвращает регулярный платёж по UUID и блокирует его
+	// от изменения другими транзакциями до конца текущей.
+	LockByUUID(uuid string) (*StandingOrder, error)
+	// LockDue возвращает не более limit активных платежей, время попытки
+	// которых наступило к моменту now, в порядке времени попытки и блокирует их,
+	// пропуская заблокированные другими транзакциями.
+	LockDue(now time.Time, limit int) ([]StandingOrder, error)
+	// Update сохраняет состояние платежа и расписание следующей попытки.
+	Update(order *StandingOrder) error
+	// CreateRun сохраняет запись о попытке выполнения платежа.
+	CreateRun(run *StandingOrderRun) error
+	// FindRuns возвращает историю попыток выполнения платежа orderID
+	// в порядке их выполнения.
+	FindRuns(orderID uint) ([]StandingOrderRun, error)
+}
+
+// WalletLimitRepository — хранилище лимитов расходов кошельков.
+type WalletLimitRepository interface {
+	// Find возвращает лимиты кошелька address или ErrNotFound, если они не установлены.
+	Find(address string) (*WalletLimit, error)
+	// Save создаёт или заменяет лимиты кошелька limit.WalletAddress.
+	// Если лимит не положителен — ErrNonPositiveAmount,
+	// если кошелька не существует — ErrUnknownWallet.
+	Save(limit *WalletLimit) error
+	// Delete удаляет лимиты кошелька address, если они установлены.
+	Delete(address string) error
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"payment_system_api/money"
+)
+
+// Состояния запланированного перевода.
+const (
+	ScheduledPending   = "pending"   // перевод ожидает времени выполнения
+	ScheduledExecuted  = "executed"  // перевод выполнен
+	ScheduledFailed    = "failed"    // перевод не выполнен, причина сохранена
+	ScheduledCancelled = "cancelled" // перевод отменён до выполнения
+)
+
+// ScheduledTransfer представляет перевод, который фоновая задача
+// выполнит в момент ExecuteAt.
+type ScheduledTransfer struct {
+	ID              uint           `gorm:"primaryKey"`
+	UUID            string         `gorm:"unique;not null"` // уникальный идентификатор запланированного перевода
+	FromAddress     string         `gorm:"index;not null"`  // адрес отправителя
+	ToAddress       string         `gorm:"not null"`        // адрес получателя
+	Amount          money.Amount   `gorm:"not null"`        // сумма в минимальных единицах валюты отправителя
+	Currency        money.Currency `gorm:"size:3;not null"` // валюта отправителя ISO 4217
+	ToCurrency      money.Currency `gorm:"size:3;not null"` // валюта зачисления ISO 4217
+	ExecuteAt       time.Time      `gorm:"not null"`        // момент, не раньше которого выполняется перевод
+	Status          string         `gorm:"not null"`        // состояние: ScheduledPending, ScheduledExecuted, ScheduledFailed или ScheduledCancelled
+	TransactionUUID *string        // UUID транзакции выполненного перевода
+	ErrorCode       string         `gorm:"not null;default:''"` // код бизнес-ошибки неуспешного перевода
+	Error           string         `gorm:"not null;default:''"` // описание ошибки неуспешного перевода
+	ExecutedAt      *time.Time     // момент выполнения или неуспешной попытки
+	Attempts        int            `gorm:"not null;default:0"` // число попыток, прерванных внутренними ошибками
+	CreatedAt       time.Time      // время создания
+	UpdatedAt       time.Time      // время последнего изменения
+}
+
+// BeforeCreate генерирует UUID запланированного перевода перед сохранением.
+func (t *ScheduledTransfer) BeforeCreate(*gorm.DB) error {
+	t.UUID = uuid.New().String()
+	return nil
+}
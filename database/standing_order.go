@@ -0,0 +1,70 @@
+package database
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"payment_system_api/money"
+)
+
+// Состояния регулярного платежа.
+const (
+	StandingOrderActive    = "active"    // платёж выполняется по расписанию
+	StandingOrderCompleted = "completed" // достигнута дата окончания или число выполнений
+	StandingOrderCancelled = "cancelled" // платёж отменён
+)
+
+// Результаты попытки выполнения регулярного платежа.
+const (
+	StandingOrderRunExecuted = "executed" // перевод выполнен
+	StandingOrderRunRetrying = "retrying" // перевод не выполнен, запланирована повторная попытка
+	StandingOrderRunFailed   = "failed"   // перевод не выполнен, выполнение пропущено
+)
+
+// StandingOrder представляет регулярный платёж: перевод, который фоновая задача
+// выполняет по cron-выражению Cron или с интервалом IntervalSeconds.
+type StandingOrder struct {
+	ID                   uint           `gorm:"primaryKey"`
+	UUID                 string         `gorm:"unique;not null"`     // уникальный идентификатор регулярного платежа
+	FromAddress          string         `gorm:"index;not null"`      // адрес отправителя
+	ToAddress            string         `gorm:"not null"`            // адрес получателя
+	Amount               money.Amount   `gorm:"not null"`            // сумма в минимальных единицах валюты отправителя
+	Currency             money.Currency `gorm:"size:3;not null"`     // валюта отправителя ISO 4217
+	ToCurrency           money.Currency `gorm:"size:3;not null"`     // валюта зачисления ISO 4217
+	Cron                 string         `gorm:"not null;default:''"` // cron-выражение, пустое — расписание по интервалу
+	IntervalSeconds      int64          `gorm:"not null;default:0"`  // интервал между выполнениями в секундах, если Cron пустое
+	StartAt              time.Time      `gorm:"not null"`            // начало расписания
+	EndAt                *time.Time     // дата окончания, nil — без ограничения
+	MaxRuns              int            `gorm:"not null;default:0"` // максимальное число выполнений, 0 — без ограничения
+	MaxRetries           int            `gorm:"not null;default:0"` // число повторных попыток при недостатке средств
+	RetryIntervalSeconds int64          `gorm:"not null;default:0"` // задержка перед повторной попыткой в секундах
+	Status               string         `gorm:"not null"`           // состояние: StandingOrderActive, StandingOrderCompleted или StandingOrderCancelled
+	Runs                 int            `gorm:"not null;default:0"` // число завершённых выполнений, включая пропущенные
+	Attempt              int            `gorm:"not null;default:0"` // число неуспешных попыток текущего выполнения
+	RunAt                time.Time      `gorm:"not null"`           // момент текущего выполнения по расписанию
+	NextAttemptAt        time.Time      `gorm:"not null"`           // момент следующей попытки: RunAt или время повторной попытки
+	CreatedAt            time.Time      // время создания
+	UpdatedAt            time.Time      // время последнего изменения
+}
+
+// BeforeCreate генерирует UUID регулярного платежа перед сохранением.
+func (o *StandingOrder) BeforeCreate(*gorm.DB) error {
+	o.UUID = uuid.New().String()
+	return nil
+}
+
+// StandingOrderRun — запись истории попыток выполнения регулярного платежа.
+type StandingOrderRun struct {
+	ID              uint      `gorm:"primaryKey"`
+	StandingOrderID uint      `gorm:"index;not null"` // идентификатор регулярного платежа
+	Run             int       `gorm:"not null"`       // номер выполнения, начиная с единицы
+	Attempt         int       `gorm:"not null"`       // номер попытки выполнения, начиная с единицы
+	ScheduledAt     time.Time `gorm:"not null"`       // момент выполнения по расписанию
+	Status          string    `gorm:"not null"`       // результат: StandingOrderRunExecuted, StandingOrderRunRetrying или StandingOrderRunFailed
+	TransactionUUID *string   // UUID транзакции выполненного перевода
+	ErrorCode       string    `gorm:"not null;default:''"` // код бизнес-ошибки неуспешной попытки
+	Error           string    `gorm:"not null;default:''"` // описание ошибки неуспешной попытки
+	CreatedAt       time.Time // момент попытки
+}
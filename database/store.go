@@ -0,0 +1,775 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"payment_system_api/money"
+)
+
+// gormStore — реализация Store поверх GORM.
+//
+// Поведение, зависящее от СУБД (блокировка строк, распознавание нарушений
+// уникальности), определяется диалектом подключения.
+type gormStore struct {
+	conn
+}
+
+// conn — подключение к базе данных вместе с его диалектом.
+type conn struct {
+	db      *gorm.DB
+	dialect dialect
+}
+
+// NewStore возвращает хранилище, работающее через подключение db
+// к PostgreSQL или SQLite.
+func NewStore(db *gorm.DB) Store {
+	return &gormStore{conn{db: db, dialect: dialectOf(db)}}
+}
+
+// Wallets возвращает репозиторий кошельков.
+func (s *gormStore) Wallets() WalletRepository {
+	return walletRepository{s.conn}
+}
+
+// Transactions возвращает репозиторий транзакций.
+func (s *gormStore) Transactions() TransactionRepository {
+	return transactionRepository{s.conn}
+}
+
+// Ledger возвращает репозиторий главной книги.
+func (s *gormStore) Ledger() LedgerRepository {
+	return ledgerRepository{s.conn}
+}
+
+// Outbox возвращает исходящую очередь событий.
+func (s *gormStore) Outbox() OutboxRepository {
+	return outboxRepository{s.conn}
+}
+
+// Webhooks возвращает репозиторий вебхуков.
+func (s *gormStore) Webhooks() WebhookRepository {
+	return webhookRepository{s.conn}
+}
+
+// Holds возвращает репозиторий резервирований средств.
+func (s *gormStore) Holds() HoldRepository {
+	return holdRepository{s.conn}
+}
+
+// ScheduledTransfers возвращает репозиторий запланированных переводов.
+func (s *gormStore) ScheduledTransfers() ScheduledTransferRepository {
+	return scheduledTransferRepository{s.conn}
+}
+
+// StandingOrders возвращает репозиторий регулярных платежей.
+func (s *gormStore) StandingOrders() StandingOrderRepository {
+	return standingOrderRepository{s.conn}
+}
+
+// Limits возвращает репозиторий лимитов расходов кошельков.
+func (s *gormStore) Limits() WalletLimitRepository {
+	return walletLimitRepository{s.conn}
+}
+
+// InTransaction выполняет fn в транзакции базы данных.
+func (s *gormStore) InTransaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{conn{db: tx, dialect: s.dialect}})
+	})
+}
+
+// with возвращает подключение с запросом db и тем же диалектом.
+func (c conn) with(db *gorm.DB) conn {
+	return conn{db: db, dialect: c.dialect}
+}
+
+// translateError заменяет ошибки GORM и драйвера общими ошибками хранилища.
+func (c conn) translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey), c.dialect.isUniqueViolation(err):
+		return ErrDuplicate
+	}
+	return err
+}
+
+// translateConstraintError работает как translateError, но нарушение ограничения
+// CHECK заменяет ошибкой check, а нарушение внешнего ключа — ошибкой foreignKey,
+// если они заданы.
+//
+// Имя нарушенного ограничения драйверы сообщают не всегда, поэтому ошибка
+// выбирается по таблице, в которую выполнялась запись.
+func (c conn) translateConstraintError(err, check, foreignKey error) error {
+	switch {
+	case err == nil:
+		return nil
+	case check != nil && (errors.Is(err, gorm.ErrCheckConstraintViolated) || c.dialect.isCheckViolation(err)):
+		return check
+	case foreignKey != nil && (errors.Is(err, gorm.ErrForeignKeyViolated) || c.dialect.isForeignKeyViolation(err)):
+		return foreignKey
+	}
+	return c.translateError(err)
+}
+
+// walletRepository — реализация WalletRepository поверх GORM.
+type walletRepository struct {
+	conn
+}
+
+// Create сохраняет новый кошелёк.
+func (r walletRepository) Create(wallet *Wallet) error {
+	return r.translateConstraintError(r.db.Create(wallet).Error, ErrNegativeBalance, nil)
+}
+
+// FindByAddress возвращает открытый кошелёк по адресу.
+func (r walletRepository) FindByAddress(address string) (*Wallet, error) {
+	var wallet Wallet
+	if err := r.db.Where("address = ?", address).First(&wallet).Error; err != nil {
+		return nil, r.translateError(err)
+	}
+	return &wallet, nil
+}
+
+// FindByAddressUnscoped возвращает кошелёк по адресу, в том числе закрытый.
+func (r walletRepository) FindByAddressUnscoped(address string) (*Wallet, error) {
+	return walletRepository{r.with(r.db.Unscoped())}.FindByAddress(address)
+}
+
+// LockByAddress возвращает открытый кошелёк по адресу
+// с блокировкой строки средствами диалекта.
+func (r walletRepository) LockByAddress(address string) (*Wallet, error) {
+	return walletRepository{r.with(r.dialect.lock(r.db))}.FindByAddress(address)
+}
+
+// List возвращает страницу открытых кошельков в порядке открытия.
+func (r walletRepository) List(offset, limit int) ([]Wallet, error) {
+	var wallets []Wallet
+	err := r.db.Order("id").Offset(offset).Limit(limit).Find(&wallets).Error
+	return wallets, err
+}
+
+// Count возвращает число открытых кошельков.
+func (r walletRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&Wallet{}).Count(&count).Error
+	return count, err
+}
+
+// CountUnscoped возвращает число кошельков, включая закрытые.
+func (r walletRepository) CountUnscoped() (int64, error) {
+	return walletRepository{r.with(r.db.Unscoped())}.Count()
+}
+
+// AddBalance изменяет баланс кошелька id на delta.
+func (r walletRepository) AddBalance(id uint, delta money.Amount) error {
+	err := r.db.Model(&Wallet{}).Where("id = ?", id).Update("balance", gorm.Expr("balance + ?", delta)).Error
+	return r.translateConstraintError(err, ErrNegativeBalance, nil)
+}
+
+// AddHeld изменяет зарезервированную сумму кошелька id на delta.
+func (r walletRepository) AddHeld(id uint, delta money.Amount) error {
+	err := r.db.Model(&Wallet{}).Where("id = ?", id).Update("held", gorm.Expr("held + ?", delta)).Error
+	return r.translateConstraintError(err, ErrNegativeBalance, nil)
+}
+
+// Close закрывает кошелёк, помечая его удалённым через DeletedAt.
+func (r walletRepository) Close(wallet *Wallet) error {
+	return r.db.Delete(wallet).Error
+}
+
+// UpdateStatus сохраняет состояние кошелька и блокировку входящих переводов.
+func (r walletRepository) UpdateStatus(wallet *Wallet) error {
+	err := r.db.Model(wallet).Select("status", "block_incoming", "updated_at").Updates(wallet).Error
+	return r.translateError(err)
+}
+
+// RecordStatusChange сохраняет запись об изменении состояния кошелька.
+func (r walletRepository) RecordStatusChange(change *WalletStatusChange) error {
+	return r.translateError(r.db.Create(change).Error)
+}
+
+// FindStatusChanges возвращает изменения состояния кошелька по возрастанию идентификатора.
+func (r walletRepository) FindStatusChanges(address string) ([]WalletStatusChange, error) {
+	var changes []WalletStatusChange
+	err := r.db.Where("wallet_address = ?", address).Order("id").Find(&changes).Error
+	return changes, err
+}
+
+// transactionRepository — реализация TransactionRepository поверх GORM.
+type transactionRepository struct {
+	conn
+}
+
+// Create сохраняет новую транзакцию.
+func (r transactionRepository) Create(transaction *Transaction) error {
+	return r.translateConstraintError(r.db.Create(transaction).Error, ErrNonPositiveAmount, ErrUnknownWallet)
+}
+
+// FindByIdempotencyKey возвращает транзакцию с ключом идемпотентности key.
+func (r transactionRepository) FindByIdempotencyKey(key string) (*Transaction, error) {
+	var transaction Transaction
+	if err := r.db.Where("idempotency_key = ?", key).First(&transaction).Error; err != nil {
+		return nil, r.translateError(err)
+	}
+	return &transaction, nil
+}
+
+// FindByUUID возвращает транзакцию по UUID.
+func (r transactionRepository) FindByUUID(uuid string) (*Transaction, error) {
+	var transaction Transaction
+	if err := r.db.Where("uuid = ?", uuid).First(&transaction).Error; err != nil {
+		return nil, r.translateError(err)
+	}
+	return &transaction, nil
+}
+
+// LockByUUID возвращает транзакцию по UUID с блокировкой строки средствами диалекта.
+func (r transactionRepository) LockByUUID(uuid string) (*Transaction, error) {
+	return transactionRepository{r.with(r.dialect.lock(r.db))}.FindByUUID(uuid)
+}
+
+// AddRefunded изменяет сумму возвратов транзакции id на delta.
+func (r transactionRepository) AddRefunded(id uint, delta money.Amount) error {
+	err := r.db.Model(&Transaction{}).Where("id = ?", id).Update("refunded", gorm.Expr("refunded + ?", delta)).Error
+	return r.translateConstraintError(err, ErrRefundExceedsAmount, nil)
+}
+
+// FindRefunds возвращает возвраты транзакций с UUID из uuids.
+func (r transactionRepository) FindRefunds(uuids []string) ([]Transaction, error) {
+	if len(uuids) == 0 {
+		return nil, nil
+	}
+	var refunds []Transaction
+	err := r.db.Where("refund_of IN ?", uuids).Order("id").Find(&refunds).Error
+	return refunds, err
+}
+
+// SumOutgoing возвращает сумму и число переводов с кошелька address, созданных не раньше since.
+func (r transactionRepository) SumOutgoing(address string, since time.Time) (money.Amount, int, error) {
+	var total, count int64
+	err := r.db.Model(&Transaction{}).Select("COALESCE(SUM(amount), 0), COUNT(*)").
+		Where("from_address = ? AND timestamp >= ? AND refund_of IS NULL", address, since.UTC()).
+		Row().Scan(&total, &count)
+	return money.Amount(total), int(count), err
+}
+
+// ReleaseIdempotencyKey очищает ключ идемпотентности транзакции id.
+func (r transactionRepository) ReleaseIdempotencyKey(id uint) error {
+	_, err := r.releaseIdempotencyKeys(r.db.Where("id = ?", id))
+	return err
+}
+
+// ReleaseIdempotencyKeys очищает ключи идемпотентности транзакций, созданных не позже cutoff.
+func (r transactionRepository) ReleaseIdempotencyKeys(cutoff time.Time) (int64, error) {
+	return r.releaseIdempotencyKeys(r.db.Where("idempotency_key IS NOT NULL AND timestamp <= ?", cutoff.UTC()))
+}
+
+// releaseIdempotencyKeys очищает ключи идемпотентности у транзакций, выбранных запросом scope.
+func (r transactionRepository) releaseIdempotencyKeys(scope *gorm.DB) (int64, error) {
+	result := scope.Model(&Transaction{}).
+		Updates(map[string]any{"idempotency_key": nil, "request_hash": ""})
+	return result.RowsAffected, result.Error
+}
+
+// Find возвращает транзакции, выбранные query.
+func (r transactionRepository) Find(query TransactionQuery) ([]Transaction, error) {
+	// Время передаётся в UTC: SQLite сравнивает моменты времени как строки,
+	// а все моменты сохраняются в UTC (см. ConnectDB)
+	db := r.db.Model(&Transaction{})
+	if query.Address != "" {
+		outgoing := r.db.Where("from_address = ?", query.Address)
+		incoming := r.db.Where("to_address = ?", query.Address)
+		if query.MinAmount != nil {
+			outgoing = outgoing.Where("amount >= ?", *query.MinAmount)
+			incoming = incoming.Where("to_amount >= ?", *query.MinAmount)
+		}
+		if query.MaxAmount != nil {
+			outgoing = outgoing.Where("amount <= ?", *query.MaxAmount)
+			incoming = incoming.Where("to_amount <= ?", *query.MaxAmount)
+		}
+
+		directions := r.db.Model(&Transaction{})
+		switch {
+		case query.Outgoing && query.Incoming:
+			directions = directions.Where(outgoing).Or(incoming)
+		case query.Outgoing:
+			directions = directions.Where(outgoing)
+		case query.Incoming:
+			directions = directions.Where(incoming)
+		default:
+			return nil, nil
+		}
+		// Остальные условия применяются к обоим направлениям сразу
+		db = db.Where(directions)
+	}
+	if query.Since != nil {
+		db = db.Where("timestamp >= ?", query.Since.UTC())
+	}
+	if query.Until != nil {
+		db = db.Where("timestamp < ?", query.Until.UTC())
+	}
+	if query.Before != nil {
+		db = db.Where("(timestamp, id) < (?, ?)", query.Before.Timestamp.UTC(), query.Before.ID)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var transactions []Transaction
+	err := db.Order("timestamp desc, id desc").Find(&transactions).Error
+	return transactions, err
+}
+
+// FindAfter возвращает зафиксированные транзакции с позицией больше after.
+func (r transactionRepository) FindAfter(after CommitPosition, address string, limit int) ([]Transaction, error) {
+	db := r.dialect.settled(r.db).Where("(xid, id) > (?, ?)", after.XID, after.ID)
+	if address != "" {
+		db = db.Where("from_address = ? OR to_address = ?", address, address)
+	}
+	var transactions []Transaction
+	err := db.Order("xid, id").Limit(limit).Find(&transactions).Error
+	return transactions, err
+}
+
+// LastCommitPosition возвращает позицию последней зафиксированной транзакции.
+func (r transactionRepository) LastCommitPosition() (CommitPosition, error) {
+	var transactions []Transaction
+	err := r.dialect.settled(r.db).Order("xid desc, id desc").Limit(1).Find(&transactions).Error
+	if err != nil || len(transactions) == 0 {
+		return CommitPosition{}, err
+	}
+	return CommitPosition{XID: transactions[0].XID, ID: transactions[0].ID}, nil
+}
+
+// ledgerRepository — реализация LedgerRepository поверх GORM.
+type ledgerRepository struct {
+	conn
+}
+
+// FindWalletAccount возвращает счёт кошелька walletID.
+func (r ledgerRepository) FindWalletAccount(walletID uint) (*Account, error) {
+	var account Account
+	if err := r.db.Where("wallet_id = ?", walletID).First(&account).Error; err != nil {
+		return nil, r.translateError(err)
+	}
+	return &account, nil
+}
+
+// CreateAccount сохраняет новый счёт.
+func (r ledgerRepository) CreateAccount(account *Account) error {
+	return r.translateError(r.db.Create(account).Error)
+}
+
+// SystemAccount возвращает системный счёт вида kind в валюте currency, открывая его при необходимости.
+func (r ledgerRepository) SystemAccount(kind string, currency money.Currency) (*Account, error) {
+	account := Account{Code: kind + ":" + string(currency), Kind: kind, Currency: currency}
+	err := r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).
+		Create(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("code = ?", account.Code).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CreateEntry сохраняет запись журнала вместе с её проводками.
+func (r ledgerRepository) CreateEntry(entry *JournalEntry, postings []Posting) error {
+	if err := r.db.Create(entry).Error; err != nil {
+		return err
+	}
+	for i := range postings {
+		postings[i].JournalEntryID = entry.ID
+	}
+	return r.db.Create(&postings).Error
+}
+
+// WalletsWithoutAccount возвращает открытые кошельки, у которых ещё нет счёта.
+func (r ledgerRepository) WalletsWithoutAccount() ([]Wallet, error) {
+	var wallets []Wallet
+	err := r.db.Where("id NOT IN (?)", r.db.Model(&Account{}).
+		Select("wallet_id").Where("wallet_id IS NOT NULL")).Find(&wallets).Error
+	return wallets, err
+}
+
+// BalanceMismatches возвращает открытые кошельки, баланс которых не совпадает с проводками.
+func (r ledgerRepository) BalanceMismatches() ([]BalanceMismatch, error) {
+	var mismatches []BalanceMismatch
+	err := r.db.Table("wallets").
+		Select("wallets.address, wallets.currency, wallets.balance, COALESCE(SUM(postings.amount), 0) AS ledger").
+		Joins("LEFT JOIN accounts ON accounts.wallet_id = wallets.id").
+		Joins("LEFT JOIN postings ON postings.account_id = accounts.id").
+		Where("wallets.deleted_at IS NULL").
+		Group("wallets.id, wallets.address, wallets.currency, wallets.balance").
+		Having("wallets.balance <> COALESCE(SUM(postings.amount), 0)").
+		Scan(&mismatches).Error
+	return mismatches, err
+}
+
+// UnbalancedEntryIDs возвращает записи журнала с ненулевой суммой проводок в какой-либо валюте.
+func (r ledgerRepository) UnbalancedEntryIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&Posting{}).
+		Distinct("journal_entry_id").
+		Group("journal_entry_id, currency").
+		Having("SUM(amount) <> 0").
+		Pluck("journal_entry_id", &ids).Error
+	return ids, err
+}
+
+// outboxRepository — реализация OutboxRepository поверх GORM.
+type outboxRepository struct {
+	conn
+}
+
+// Create сохраняет новое событие.
+func (r outboxRepository) Create(event *OutboxEvent) error {
+	return r.translateError(r.db.Create(event).Error)
+}
+
+// TryLock захватывает блокировку публикации событий средствами диалекта.
+func (r outboxRepository) TryLock() (bool, error) {
+	return r.dialect.tryLock(r.db, outboxLockKey)
+}
+
+// Pending возвращает неопубликованные события в порядке создания.
+func (r outboxRepository) Pending(limit int) ([]OutboxEvent, error) {
+	var events []OutboxEvent
+	err := r.db.Where("published_at IS NULL").Order("id").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// MarkPublished отмечает событие опубликованным.
+func (r outboxRepository) MarkPublished(id uint, at time.Time) error {
+	return r.db.Model(&OutboxEvent{}).Where("id = ?", id).Update("published_at", at.UTC()).Error
+}
+
+// MarkFailed сохраняет неудачную попытку публикации события.
+func (r outboxRepository) MarkFailed(id uint, reason string) error {
+	return r.db.Model(&OutboxEvent{}).Where("id = ?", id).
+		Updates(map[string]any{"attempts": gorm.Expr("attempts + 1"), "last_error": reason}).Error
+}
+
+// webhookRepository — реализация WebhookRepository поверх GORM.
+type webhookRepository struct {
+	conn
+}
+
+// Create сохраняет новый вебхук.
+func (r webhookRepository) Create(webhook *Webhook) error {
+	return r.translateConstraintError(r.db.Create(webhook).Error, nil, ErrUnknownWallet)
+}
+
+// Find возвращает вебхук по идентификатору.
+func (r webhookRepository) Find(id uint) (*Webhook, error) {
+	var webhook Webhook
+	if err := r.db.First(&webhook, id).Error; err != nil {
+		return nil, r.translateError(err)
+	}
+	return &webhook, nil
+}
+
+// List возвращает все вебхуки в порядке регистрации.
+func (r webhookRepository) List() ([]Webhook, error) {
+	var webhooks []Webhook
+	err := r.db.Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// Delete удаляет вебхук; его доставки удаляются каскадно внешним ключом.
+func (r webhookRepository) Delete(id uint) error {
+	return r.deleted(r.db.Delete(&Webhook{}, id))
+}
+
+// Subscribers возвращает вебхуки кошелька и вебхуки всех кошельков.
+func (r webhookRepository) Subscribers(address string) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := r.db.Where("wallet_address = ? OR wallet_address IS NULL", address).Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// CreateDelivery сохраняет новую доставку.
+func (r webhookRepository) CreateDelivery(delivery *WebhookDelivery) error {
+	// Время попытки сравнивается при выборке, поэтому хранится в UTC (см. ConnectDB)
+	delivery.NextAttemptAt = delivery.NextAttemptAt.UTC()
+	return r.translateError(r.db.Create(delivery).Error)
+}
+
+// FindDelivery возвращает доставку по идентификатору.
+func (r webhookRepository) FindDelivery(id uint) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	if err := r.db.First(&delivery, id).Error; err != nil {
+		return nil, r.translateError(err)
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries возвращает страницу доставок от новых к старым.
+func (r webhookRepository) ListDeliveries(query DeliveryQuery, offset, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := r.deliveries(query).Order("id desc").Offset(offset).Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// CountDeliveries возвращает число доставок, выбранных query.
+func (r webhookRepository) CountDeliveries(query DeliveryQuery) (int64, error) {
+	var count int64
+	err := r.deliveries(query).Count(&count).Error
+	return count, err
+}
+
+// deliveries возвращает запрос доставок, выбранных query.
+func (r webhookRepository) deliveries(query DeliveryQuery) *gorm.DB {
+	db := r.db.Model(&WebhookDelivery{})
+	if query.WebhookID != 0 {
+		db = db.Where("webhook_id = ?", query.WebhookID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	return db
+}
+
+// ClaimDeliveries выбирает наступившие доставки с блокировкой, пропуская
+// заблокированные другими транзакциями, и переносит их следующую попытку.
+func (r webhookRepository) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := r.dialect.lockSkipLocked(r.db).
+		Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now.UTC()).
+		Order("next_attempt_at, id").Limit(limit).Find(&deliveries).Error
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(deliveries))
+	for i := range deliveries {
+		deliveries[i].NextAttemptAt = now.Add(lease).UTC()
+		ids = append(ids, deliveries[i].ID)
+	}
+	err = r.db.Model(&WebhookDelivery{}).Where("id IN ?", ids).
+		Update("next_attempt_at", now.Add(lease).UTC()).Error
+	return deliveries, err
+}
+
+// UpdateDelivery сохраняет состояние доставки.
+func (r webhookRepository) UpdateDelivery(delivery *WebhookDelivery) error {
+	var deliveredAt *time.Time
+	if delivery.DeliveredAt != nil {
+		at := delivery.DeliveredAt.UTC()
+		deliveredAt = &at
+	}
+	return r.db.Model(&WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]any{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": delivery.NextAttemptAt.UTC(),
+		"last_error":      delivery.LastError,
+		"response_status": delivery.ResponseStatus,
+		"delivered_at":    deliveredAt,
+	}).Error
+}
+
+// DeleteDelivery удаляет доставку.
+func (r webhookRepository) DeleteDelivery(id uint) error {
+	return r.deleted(r.db.Delete(&WebhookDelivery{}, id))
+}
+
+// deleted возвращает ошибку удаления result или ErrNotFound, если ничего не удалено.
+func (r webhookRepository) deleted(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// holdRepository — реализация HoldRepository поверх GORM.
+type holdRepository struct {
+	conn
+}
+
+// Create сохраняет новое резервирование.
+func (r holdRepository) Create(hold *Hold) error {
+	// Срок сравнивается при выборке истёкших, поэтому хранится в UTC (см. ConnectDB)
+	hold.ExpiresAt = hold.ExpiresAt.UTC()
+	return r.translateConstraintError(r.db.Create(hold).Error, ErrNonPositiveAmount, ErrUnknownWallet)
+}
+
+// FindByUUID возвращает резервирование по UUID.
+func (r holdRepository) FindByUUID(uuid string) (*Hold, error) {
+	var hold Hold
+	if err := r.db.Where("uuid = ?", uuid).First(&hold).Error; err != nil {
+		return nil, r.translateError(err)
+	}
+	return &hold, nil
+}
+
+// LockByUUID возвращает резервирование по UUID с блокировкой строки средствами диалекта.
+func (r holdRepository) LockByUUID(uuid string) (*Hold, error) {
+	return holdRepository{r.with(r.dialect.lock(r.db))}.FindByUUID(uuid)
+}
+
+// LockExpired выбирает истёкшие активные резервирования с блокировкой,
+// пропуская заблокированные другими транзакциями.
+func (r holdRepository) LockExpired(now time.Time, limit int) ([]Hold, error) {
+	var holds []Hold
+	err := r.dialect.lockSkipLocked(r.db).
+		Where("status = ? AND expires_at <= ?", HoldActive, now.UTC()).
+		Order("id").Limit(limit).Find(&holds).Error
+	return holds, err
+}
+
+// SumActive возвращает несписанный остаток активных резервирований кошелька address,
+// созданных не раньше since.
+func (r holdRepository) SumActive(address string, since time.Time) (money.Amount, error) {
+	var total int64
+	err := r.db.Model(&Hold{}).Select("COALESCE(SUM(amount - captured), 0)").
+		Where("wallet_address = ? AND status = ? AND created_at >= ?", address, HoldActive, since.UTC()).
+		Row().Scan(&total)
+	return money.Amount(total), err
+}
+
+// Update сохраняет списанную сумму и состояние резервирования.
+func (r holdRepository) Update(hold *Hold) error {
+	err := r.db.Model(hold).Select("captured", "status", "updated_at").Updates(hold).Error
+	return r.translateError(err)
+}
+
+// scheduledTransferRepository — реализация ScheduledTransferRepository поверх GORM.
+type scheduledTransferRepository struct {
+	conn
+}
+
+// Create сохраняет новый запланированный перевод.
+func (r scheduledTransferRepository) Create(transfer *ScheduledTransfer) error {
+	// Время выполнения сравнивается при выборке наступивших, поэтому хранится в UTC (см. ConnectDB)
+	transfer.ExecuteAt = transfer.ExecuteAt.UTC()
+	return r.translateConstraintError(r.db.Create(transfer).Error, ErrNonPositiveAmount, ErrUnknownWallet)
+}
+
+// FindByUUID возвращает запланированный перевод по UUID.
+func (r scheduledTransferRepository) FindByUUID(uuid string) (*ScheduledTransfer, error) {
+	var transfer ScheduledTransfer
+	if err := r.db.Where("uuid = ?", uuid).First(&transfer).Error; err != nil {
+		return nil, r.translateError(err)
+	}
+	return &transfer, nil
+}
+
+// LockByUUID возвращает запланированный перевод по UUID с блокировкой строки средствами диалекта.
+func (r scheduledTransferRepository) LockByUUID(uuid string) (*ScheduledTransfer, error) {
+	return scheduledTransferRepository{r.with(r.dialect.lock(r.db))}.FindByUUID(uuid)
+}
+
+// LockDue выбирает наступившие ожидающие переводы с блокировкой,
+// пропуская заблокированные другими транзакциями.
+func (r scheduledTransferRepository) LockDue(now time.Time, limit int) ([]ScheduledTransfer, error) {
+	var transfers []ScheduledTransfer
+	err := r.dialect.lockSkipLocked(r.db).
+		Where("status = ? AND execute_at <= ?", ScheduledPending, now.UTC()).
+		Order("attempts, execute_at, id").Limit(limit).Find(&transfers).Error
+	return transfers, err
+}
+
+// Update сохраняет состояние и результат выполнения перевода.
+func (r scheduledTransferRepository) Update(transfer *ScheduledTransfer) error {
+	err := r.db.Model(transfer).
+		Select("status", "transaction_uuid", "error_code", "error", "executed_at", "attempts", "updated_at").
+		Updates(transfer).Error
+	return r.translateError(err)
+}
+
+// standingOrderRepository — реализация StandingOrderRepository поверх GORM.
+type standingOrderRepository struct {
+	conn
+}
+
+// Create сохраняет новый регулярный платёж.
+func (r standingOrderRepository) Create(order *StandingOrder) error {
+	// Моменты попыток сравниваются при выборке наступивших, поэтому хранятся в UTC (см. ConnectDB)
+	order.StartAt = order.StartAt.UTC()
+	order.RunAt = order.RunAt.UTC()
+	order.NextAttemptAt = order.NextAttemptAt.UTC()
+	return r.translateConstraintError(r.db.Create(order).Error, ErrNonPositiveAmount, ErrUnknownWallet)
+}
+
+// FindByUUID возвращает регулярный платёж по UUID.
+func (r standingOrderRepository) FindByUUID(uuid string) (*StandingOrder, error) {
+	var order StandingOrder
+	if err := r.db.Where("uuid = ?", uuid).First(&order).Error; err != nil {
+		return nil, r.translateError(err)
+	}
+	return &order, nil
+}
+
+// LockByUUID возвращает регулярный платёж по UUID с блокировкой строки средствами диалекта.
+func (r standingOrderRepository) LockByUUID(uuid string) (*StandingOrder, error) {
+	return standingOrderRepository{r.with(r.dialect.lock(r.db))}.FindByUUID(uuid)
+}
+
+// LockDue выбирает активные платежи, время попытки которых наступило, с блокировкой,
+// пропуская заблокированные другими транзакциями.
+func (r standingOrderRepository) LockDue(now time.Time, limit int) ([]StandingOrder, error) {
+	var orders []StandingOrder
+	err := r.dialect.lockSkipLocked(r.db).
+		Where("status = ? AND next_attempt_at <= ?", StandingOrderActive, now.UTC()).
+		Order("next_attempt_at, id").Limit(limit).Find(&orders).Error
+	return orders, err
+}
+
+// Update сохраняет состояние платежа и расписание следующей попытки.
+func (r standingOrderRepository) Update(order *StandingOrder) error {
+	order.RunAt = order.RunAt.UTC()
+	order.NextAttemptAt = order.NextAttemptAt.UTC()
+	err := r.db.Model(order).
+		Select("status", "runs", "attempt", "run_at", "next_attempt_at", "updated_at").
+		Updates(order).Error
+	return r.translateError(err)
+}
+
+// CreateRun сохраняет запись о попытке выполнения платежа.
+func (r standingOrderRepository) CreateRun(run *StandingOrderRun) error {
+	return r.translateError(r.db.Create(run).Error)
+}
+
+// FindRuns возвращает историю попыток выполнения платежа по возрастанию идентификатора.
+func (r standingOrderRepository) FindRuns(orderID uint) ([]StandingOrderRun, error) {
+	var runs []StandingOrderRun
+	err := r.db.Where("standing_order_id = ?", orderID).Order("id").Find(&runs).Error
+	return runs, err
+}
+
+// walletLimitRepository — реализация WalletLimitRepository поверх GORM.
+type walletLimitRepository struct {
+	conn
+}
+
+// Find возвращает лимиты кошелька address.
+func (r walletLimitRepository) Find(address string) (*WalletLimit, error) {
+	var limit WalletLimit
+	if err := r.db.Where("wallet_address = ?", address).First(&limit).Error; err != nil {
+		return nil, r.translateError(err)
+	}
+	return &limit, nil
+}
+
+// Save создаёт или заменяет лимиты кошелька.
+func (r walletLimitRepository) Save(limit *WalletLimit) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "wallet_address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_transfer", "daily_total", "monthly_total", "hourly_count", "updated_at"}),
+	}).Create(limit).Error
+	return r.translateConstraintError(err, ErrNonPositiveAmount, ErrUnknownWallet)
+}
+
+// Delete удаляет лимиты кошелька address.
+func (r walletLimitRepository) Delete(address string) error {
+	return r.translateError(r.db.Where("wallet_address = ?", address).Delete(&WalletLimit{}).Error)
+}
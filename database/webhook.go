@@ -0,0 +1,35 @@
+package database
+
+import "time"
+
+// Состояния доставки вебхука.
+const (
+	DeliveryPending   = "pending"   // ожидает отправки или повторной попытки
+	DeliveryDelivered = "delivered" // получатель подтвердил получение
+	DeliveryDead      = "dead"      // попытки исчерпаны, доставка отложена до ручного повтора
+)
+
+// Webhook представляет подписку внешнего сервиса на события кошельков.
+type Webhook struct {
+	ID            uint      `gorm:"primaryKey"`
+	URL           string    `gorm:"not null"` // адрес, на который отправляются события
+	WalletAddress *string   `gorm:"index"`    // кошелёк подписки, NULL — все кошельки
+	Secret        string    `gorm:"not null"` // ключ подписи HMAC-SHA256
+	CreatedAt     time.Time // время регистрации
+}
+
+// WebhookDelivery представляет доставку одного события по вебхуку.
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey"`
+	WebhookID      uint       `gorm:"index;not null"` // вебхук, по которому доставляется событие
+	Event          string     `gorm:"not null"`       // тип события, например "wallet.debited"
+	WalletAddress  string     `gorm:"not null"`       // кошелёк, с которым произошло событие
+	Payload        string     `gorm:"not null"`       // тело запроса в JSON
+	Status         string     `gorm:"not null"`       // состояние: DeliveryPending, DeliveryDelivered или DeliveryDead
+	Attempts       int        `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  // время следующей попытки отправки
+	LastError      string     // ошибка последней неудачной попытки
+	ResponseStatus int        // HTTP-код ответа на последнюю попытку, 0 — ответа не было
+	CreatedAt      time.Time  // время возникновения события
+	DeliveredAt    *time.Time // время успешной доставки
+}
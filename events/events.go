@@ -0,0 +1,35 @@
+// Package events описывает события платёжной системы, которые получают другие сервисы.
+//
+// События публикуются через интерфейс Publisher. Для тестов и простых
+// развёртываний есть WriterPublisher, записывающий события строками JSON
+// в стандартный вывод или файл; адаптеры брокеров сообщений подключаются
+// реализацией того же интерфейса.
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TypeTransferCompleted — тип события о выполненном переводе.
+// Данные события — перевод в формате ответа API.
+const TypeTransferCompleted = "TransferCompleted"
+
+// Event — событие платёжной системы.
+//
+// Доставка выполняется не менее одного раза: после сбоя событие может быть
+// опубликовано повторно с тем же ID, получатели должны учитывать это.
+// События одного кошелька публикуются в порядке их возникновения.
+type Event struct {
+	ID        uint            `json:"id"`         // идентификатор события, уникален и не меняется при повторной публикации
+	Type      string          `json:"type"`       // тип события, например TypeTransferCompleted
+	Payload   json.RawMessage `json:"payload"`    // данные события в JSON
+	CreatedAt time.Time       `json:"created_at"` // момент возникновения события
+}
+
+// Publisher — получатель событий, например брокер сообщений.
+type Publisher interface {
+	// Publish публикует событие event. Если публикация не удалась,
+	// событие будет опубликовано повторно позже.
+	Publish(event Event) error
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterPublisher публикует события строками JSON в io.Writer — по одному событию на строку.
+type WriterPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterPublisher возвращает издателя, записывающего события в w.
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+// OpenFilePublisher возвращает издателя, дописывающего события в файл path.
+// Если файла нет, он создаётся. Файл закрывается методом Close.
+func OpenFilePublisher(path string) (*WriterPublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterPublisher(file), nil
+}
+
+// Publish записывает событие event одной строкой JSON.
+//
+// Если w — файл, запись сбрасывается на диск до возврата,
+// чтобы событие не было потеряно после подтверждения публикации.
+func (p *WriterPublisher) Publish(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if file, ok := p.w.(*os.File); ok && file != os.Stdout && file != os.Stderr {
+		return file.Sync()
+	}
+	return nil
+}
+
+// Close закрывает w, если его можно закрыть. Стандартный вывод не закрывается.
+func (p *WriterPublisher) Close() error {
+	if p.w == os.Stdout || p.w == os.Stderr {
+		return nil
+	}
+	if closer, ok := p.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFilePublisher проверяет, что события дописываются в файл
+// строками JSON в порядке публикации и сохраняются между открытиями файла.
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	createdAt := time.Date(2025, 8, 25, 12, 0, 0, 0, time.UTC)
+
+	for id := uint(1); id <= 2; id++ {
+		publisher, err := OpenFilePublisher(path)
+		if err != nil {
+			t.Fatalf("Не удалось открыть файл событий: %v", err)
+		}
+		event := Event{ID: id, Type: TypeTransferCompleted, Payload: json.RawMessage(`{"uuid":"u"}`), CreatedAt: createdAt}
+		if err := publisher.Publish(event); err != nil {
+			t.Fatalf("Не удалось опубликовать событие: %v", err)
+		}
+		if err := publisher.Close(); err != nil {
+			t.Fatalf("Не удалось закрыть файл событий: %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Не удалось открыть файл событий: %v", err)
+	}
+	defer file.Close()
+	var ids []uint
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Строка %q не является событием: %v", scanner.Text(), err)
+		}
+		if event.Type != TypeTransferCompleted || !event.CreatedAt.Equal(createdAt) || string(event.Payload) != `{"uuid":"u"}` {
+			t.Errorf("Неожиданное событие %+v", event)
+		}
+		ids = append(ids, event.ID)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("Ожидались события 1 и 2, получено %v", ids)
+	}
+}
@@ -0,0 +1,41 @@
+// Package fx предоставляет курсы обмена валют для переводов с конвертацией.
+//
+// Источник курсов подключается через интерфейс RateProvider,
+// для тестов и простых развёртываний есть StaticProvider, читающий курсы из файла.
+package fx
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"payment_system_api/money"
+)
+
+// ErrRateNotFound — курс для указанной пары валют неизвестен.
+var ErrRateNotFound = errors.New("курс для пары валют не найден")
+
+// Quote — котировка курса обмена одной валюты на другую.
+type Quote struct {
+	From     money.Currency // валюта списания
+	To       money.Currency // валюта зачисления
+	Rate     *big.Rat       // количество единиц To за одну единицу From
+	QuotedAt time.Time      // момент, на который получен курс
+}
+
+// RateProvider — источник курсов обмена валют.
+type RateProvider interface {
+	// Quote возвращает текущую котировку обмена from на to
+	// или ErrRateNotFound, если курс неизвестен.
+	Quote(from, to money.Currency) (Quote, error)
+}
+
+// Expired сообщает, истекла ли котировка к моменту now при сроке жизни ttl.
+func (q Quote) Expired(now time.Time, ttl time.Duration) bool {
+	return now.Sub(q.QuotedAt) > ttl
+}
+
+// RateString возвращает курс в виде десятичной строки с точностью до 10 знаков после точки.
+func (q Quote) RateString() string {
+	return q.Rate.FloatString(10)
+}
@@ -0,0 +1,77 @@
+package fx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"payment_system_api/money"
+)
+
+// StaticProvider — источник курсов с фиксированной таблицей, загруженной из файла.
+//
+// Если прямой курс пары не задан, используется обратный к курсу противоположной пары.
+type StaticProvider struct {
+	rates    map[[2]money.Currency]*big.Rat
+	quotedAt time.Time // момент котировки из файла; нулевой — котировки всегда актуальны
+}
+
+// staticFile описывает формат файла курсов:
+//
+//	{"quoted_at": "2025-08-25T12:00:00Z", "rates": {"USD/RUB": "92.5", "EUR/RUB": "100.1"}}
+//
+// Поле quoted_at необязательное.
+type staticFile struct {
+	QuotedAt *time.Time        `json:"quoted_at"`
+	Rates    map[string]string `json:"rates"`
+}
+
+// LoadStaticProvider загружает таблицу курсов из JSON-файла path.
+func LoadStaticProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл курсов: %w", err)
+	}
+	var file staticFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать файл курсов: %w", err)
+	}
+
+	provider := &StaticProvider{rates: make(map[[2]money.Currency]*big.Rat, len(file.Rates))}
+	if file.QuotedAt != nil {
+		provider.quotedAt = *file.QuotedAt
+	}
+	for pair, value := range file.Rates {
+		fromCode, toCode, ok := strings.Cut(pair, "/")
+		from, fromErr := money.ParseCurrency(fromCode)
+		to, toErr := money.ParseCurrency(toCode)
+		if !ok || fromErr != nil || toErr != nil {
+			return nil, fmt.Errorf("некорректная пара валют %q в файле курсов", pair)
+		}
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("некорректный курс %q для пары %s", value, pair)
+		}
+		provider.rates[[2]money.Currency{from, to}] = rate
+	}
+	return provider, nil
+}
+
+// Quote возвращает котировку обмена from на to.
+func (p *StaticProvider) Quote(from, to money.Currency) (Quote, error) {
+	quotedAt := p.quotedAt
+	if quotedAt.IsZero() {
+		quotedAt = time.Now()
+	}
+
+	if rate, ok := p.rates[[2]money.Currency{from, to}]; ok {
+		return Quote{From: from, To: to, Rate: rate, QuotedAt: quotedAt}, nil
+	}
+	if rate, ok := p.rates[[2]money.Currency{to, from}]; ok {
+		return Quote{From: from, To: to, Rate: new(big.Rat).Inv(rate), QuotedAt: quotedAt}, nil
+	}
+	return Quote{}, ErrRateNotFound
+}
@@ -0,0 +1,70 @@
+package fx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeRatesFile записывает содержимое файла курсов во временный каталог теста.
+func writeRatesFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rates.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Не удалось записать файл курсов: %v", err)
+	}
+	return path
+}
+
+// TestStaticProvider проверяет загрузку курсов из файла,
+// получение прямого и обратного курса и отсутствие неизвестной пары.
+func TestStaticProvider(t *testing.T) {
+	path := writeRatesFile(t, `{"quoted_at": "2025-08-25T12:00:00Z", "rates": {"USD/RUB": "92.5"}}`)
+	provider, err := LoadStaticProvider(path)
+	if err != nil {
+		t.Fatalf("Не удалось загрузить курсы: %v", err)
+	}
+
+	quote, err := provider.Quote("USD", "RUB")
+	if err != nil {
+		t.Fatalf("Не удалось получить прямой курс: %v", err)
+	}
+	if quote.RateString() != "92.5000000000" {
+		t.Errorf("Ожидался курс 92.5, получен %s", quote.RateString())
+	}
+	if !quote.QuotedAt.Equal(time.Date(2025, 8, 25, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Неверный момент котировки: %v", quote.QuotedAt)
+	}
+	if !quote.Expired(quote.QuotedAt.Add(2*time.Minute), time.Minute) {
+		t.Errorf("Котировка должна истечь через минуту")
+	}
+
+	inverse, err := provider.Quote("RUB", "USD")
+	if err != nil {
+		t.Fatalf("Не удалось получить обратный курс: %v", err)
+	}
+	if inverse.Rate.Inv(inverse.Rate).Cmp(quote.Rate) != 0 {
+		t.Errorf("Обратный курс не соответствует прямому")
+	}
+
+	if _, err := provider.Quote("USD", "EUR"); !errors.Is(err, ErrRateNotFound) {
+		t.Errorf("Ожидалась ошибка ErrRateNotFound, получена %v", err)
+	}
+}
+
+// TestLoadStaticProviderInvalid проверяет отказ от некорректных файлов курсов.
+func TestLoadStaticProviderInvalid(t *testing.T) {
+	for _, content := range []string{
+		`{"rates": {"USD-RUB": "92.5"}}`,
+		`{"rates": {"USD/XXX": "92.5"}}`,
+		`{"rates": {"USD/RUB": "-1"}}`,
+		`{"rates": {"USD/RUB": "abc"}}`,
+		`not json`,
+	} {
+		if _, err := LoadStaticProvider(writeRatesFile(t, content)); err == nil {
+			t.Errorf("Ожидалась ошибка для файла %s", content)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminPrincipalKey — ключ контекста Gin, под которым AdminAuth сохраняет
+// имя администратора, выполняющего запрос.
+const adminPrincipalKey = "admin_principal"
+
+// AdminAuth возвращает промежуточный обработчик административных эндпоинтов.
+//
+// Запрос должен содержать заголовок "Authorization: Bearer <токен>" с одним
+// из токенов tokens (токен → имя администратора); имя сохраняется в контексте
+// запроса. Иначе запрос отклоняется с 401 Unauthorized и кодом UNAUTHORIZED.
+// Если tokens пуст, отклоняются все запросы.
+func AdminAuth(tokens map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		principal := ""
+		if ok && token != "" {
+			// Сравниваются все токены за постоянное время, чтобы время ответа
+			// не выдавало совпадение префикса
+			for candidate, name := range tokens {
+				if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+					principal = name
+				}
+			}
+		}
+		if principal == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			writeError(c, http.StatusUnauthorized, CodeUnauthorized, "Требуется токен администратора", "")
+			c.Abort()
+			return
+		}
+		c.Set(adminPrincipalKey, principal)
+		c.Next()
+	}
+}
+
+// adminPrincipal возвращает имя администратора, проверенного AdminAuth,
+// или пустую строку, если запрос прошёл без неё.
+func adminPrincipal(c *gin.Context) string {
+	return c.GetString(adminPrincipalKey)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestAdminAuth проверяет, что AdminAuth пропускает только запросы
+// с известным токеном и сохраняет имя администратора в контексте.
+func TestAdminAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(tokens map[string]string, authorization string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/api/admin/whoami", AdminAuth(tokens), func(c *gin.Context) {
+			c.String(http.StatusOK, c.GetString(adminPrincipalKey))
+		})
+		request := httptest.NewRequest("GET", "/api/admin/whoami", nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	tokens := map[string]string{"secret-1": "alice", "secret-2": "bob"}
+	if recorder := serve(tokens, "Bearer secret-2"); recorder.Code != http.StatusOK || recorder.Body.String() != "bob" {
+		t.Errorf("Ожидался статус 200 от имени bob, получен %d: %s", recorder.Code, recorder.Body)
+	}
+
+	for _, test := range []struct {
+		name          string
+		tokens        map[string]string
+		authorization string
+	}{
+		{"без заголовка", tokens, ""},
+		{"неизвестный токен", tokens, "Bearer secret-3"},
+		{"префикс токена", tokens, "Bearer secret"},
+		{"другая схема", tokens, "Basic secret-1"},
+		{"пустой токен", tokens, "Bearer "},
+		{"токены не заданы", nil, "Bearer secret-1"},
+	} {
+		recorder := serve(test.tokens, test.authorization)
+		var resp ErrorResponse
+		if recorder.Code != http.StatusUnauthorized || json.Unmarshal(recorder.Body.Bytes(), &resp) != nil || resp.Code != CodeUnauthorized {
+			t.Errorf("%s: ожидался статус 401 с кодом %s, получен %d: %s", test.name, CodeUnauthorized, recorder.Code, recorder.Body)
+		}
+	}
+}
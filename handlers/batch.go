@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+	"payment_system_api/money"
+)
+
+// SendBatchRequest представляет тело запроса для POST /api/send/batch.
+type SendBatchRequest struct {
+	From      string                 `json:"from" binding:"required"`      // кошелёк отправителя всех переводов
+	Currency  money.Currency         `json:"currency"`                     // необязательная валюта переводов ISO 4217
+	Transfers []BatchTransferRequest `json:"transfers" binding:"required"` // переводы пакета
+}
+
+// BatchTransferRequest описывает один перевод в теле запроса POST /api/send/batch.
+type BatchTransferRequest struct {
+	To         string         `json:"to"`          // кошелёк получателя
+	Amount     money.Decimal  `json:"amount"`      // сумма десятичной строкой, например "12.34"
+	ToCurrency money.Currency `json:"to_currency"` // валюта зачисления для перевода с конвертацией
+}
+
+// BatchErrorResponse — ответ с ошибкой невыполненного пакета переводов:
+// ErrorResponse с результатами каждого перевода.
+type BatchErrorResponse struct {
+	ErrorResponse
+	Batch *business.BatchResponse `json:"пакет"` // результаты переводов с причинами ошибок
+}
+
+// SendBatchHandler обрабатывает POST /api/send/batch.
+//
+// Выполняет пакет переводов с одного кошелька атомарно: либо все переводы,
+// либо ни одного. Каждый перевод проверяется так же, как в POST /api/send.
+// Возвращает:
+// - 200 OK с идентификатором пакета и транзакцией каждого перевода
+// - 400 Bad Request, если тело запроса неверное или пакет пуст либо слишком велик
+// - 422 Unprocessable Entity, если хотя бы один перевод неуспешен: ответ содержит
+// результат каждого перевода и причину ошибки неуспешных
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) SendBatchHandler(c *gin.Context) {
+	var req SendBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+
+	batch := business.BatchRequest{FromAddress: req.From, Currency: req.Currency}
+	for _, transfer := range req.Transfers {
+		batch.Transfers = append(batch.Transfers, business.BatchTransfer{
+			ToAddress:  transfer.To,
+			Amount:     transfer.Amount,
+			ToCurrency: transfer.ToCurrency,
+		})
+	}
+
+	result, err := h.service.SendBatch(batch)
+	if errors.Is(err, business.ErrBatchFailed) {
+		c.JSON(http.StatusUnprocessableEntity, BatchErrorResponse{
+			ErrorResponse: ErrorResponse{Code: business.ErrBatchFailed.Code, Message: business.ErrBatchFailed.Message},
+			Batch:         result,
+		})
+		return
+	}
+	if err != nil {
+		writeBusinessError(c, err, "Пакет переводов неуспешен")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+)
+
+// Коды ошибок уровня HTTP, не относящиеся к бизнес-логике.
+const (
+	// CodeInvalidRequest — тело или параметры запроса не прошли проверку.
+	CodeInvalidRequest = "INVALID_REQUEST"
+	// CodeInternal — непредвиденная внутренняя ошибка сервера.
+	CodeInternal = "INTERNAL_ERROR"
+	// CodeUnauthorized — запрос к административному эндпоинту без действующего токена.
+	CodeUnauthorized = "UNAUTHORIZED"
+)
+
+// ErrorResponse — единый формат ответа с ошибкой для всех эндпоинтов API.
+//
+// Клиенты должны опираться на поле Code: в отличие от текста ошибки
+// оно стабильно между версиями API.
+type ErrorResponse struct {
+	Code    string `json:"код"`              // машиночитаемый код ошибки
+	Message string `json:"ошибка"`           // описание ошибки
+	Details string `json:"детали,omitempty"` // дополнительные подробности, если есть
+}
+
+// businessErrorStatus — таблица соответствий кодов бизнес-ошибок HTTP-кодам.
+var businessErrorStatus = map[string]int{
+	business.ErrSenderNotFound.Code:              http.StatusNotFound,
+	business.ErrRecipientNotFound.Code:           http.StatusNotFound,
+	business.ErrWalletNotFound.Code:              http.StatusNotFound,
+	business.ErrWalletNotEmpty.Code:              http.StatusConflict,
+	business.ErrInsufficientFunds.Code:           http.StatusPaymentRequired,
+	business.ErrInvalidAmount.Code:               http.StatusBadRequest,
+	business.ErrAmountPrecision.Code:             http.StatusBadRequest,
+	business.ErrCurrencyMismatch.Code:            http.StatusUnprocessableEntity,
+	business.ErrFXUnavailable.Code:               http.StatusServiceUnavailable,
+	business.ErrRateNotFound.Code:                http.StatusUnprocessableEntity,
+	business.ErrQuoteExpired.Code:                http.StatusServiceUnavailable,
+	business.ErrSameAddress.Code:                 http.StatusBadRequest,
+	business.ErrInvalidCursor.Code:               http.StatusBadRequest,
+	business.ErrIdempotencyKeyConflict.Code:      http.StatusConflict,
+	business.ErrConcurrentUpdate.Code:            http.StatusServiceUnavailable,
+	business.ErrHoldNotFound.Code:                http.StatusNotFound,
+	business.ErrHoldNotActive.Code:               http.StatusConflict,
+	business.ErrHoldExpired.Code:                 http.StatusConflict,
+	business.ErrCaptureExceedsHold.Code:          http.StatusUnprocessableEntity,
+	business.ErrInvalidHoldTTL.Code:              http.StatusBadRequest,
+	business.ErrInvalidBatch.Code:                http.StatusBadRequest,
+	business.ErrBatchFailed.Code:                 http.StatusUnprocessableEntity,
+	business.ErrInvalidExecuteAt.Code:            http.StatusBadRequest,
+	business.ErrScheduledTransferNotFound.Code:   http.StatusNotFound,
+	business.ErrScheduledTransferNotPending.Code: http.StatusConflict,
+	business.ErrInvalidSchedule.Code:             http.StatusBadRequest,
+	business.ErrInvalidLimit.Code:                http.StatusBadRequest,
+	business.ErrSenderFrozen.Code:                http.StatusForbidden,
+	business.ErrRecipientFrozen.Code:             http.StatusForbidden,
+	business.ErrWalletNotFrozen.Code:             http.StatusConflict,
+	business.ErrInvalidStatusChange.Code:         http.StatusBadRequest,
+	business.ErrTransferLimitExceeded.Code:       http.StatusUnprocessableEntity, // общий код всех лимитов расходов
+	business.ErrStandingOrderNotFound.Code:       http.StatusNotFound,
+	business.ErrStandingOrderNotActive.Code:      http.StatusConflict,
+	business.ErrTransactionNotFound.Code:         http.StatusNotFound,
+	business.ErrNotRefundable.Code:               http.StatusConflict,
+	business.ErrRefundExceedsAmount.Code:         http.StatusUnprocessableEntity,
+	business.ErrRefundInsufficientFunds.Code:     http.StatusConflict,
+	business.ErrWebhookNotFound.Code:             http.StatusNotFound,
+	business.ErrDeliveryNotFound.Code:            http.StatusNotFound,
+	business.ErrInvalidWebhookURL.Code:           http.StatusBadRequest,
+}
+
+// writeError отправляет ответ с ошибкой в едином формате ErrorResponse.
+func writeError(c *gin.Context, status int, code, message, details string) {
+	c.JSON(status, ErrorResponse{Code: code, Message: message, Details: details})
+}
+
+// writeBusinessError преобразует ошибку бизнес-логики в HTTP-ответ.
+//
+// Известные ошибки каталога business отдаются с соответствующим HTTP-кодом,
+// всё остальное — 500 Internal Server Error с текстом fallback.
+func writeBusinessError(c *gin.Context, err error, fallback string) {
+	var bizErr *business.Error
+	if errors.As(err, &bizErr) {
+		if status, ok := businessErrorStatus[bizErr.Code]; ok {
+			writeError(c, status, bizErr.Code, bizErr.Message, "")
+			return
+		}
+	}
+	writeError(c, http.StatusInternalServerError, CodeInternal, fallback, err.Error())
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+)
+
+// TestWriteBusinessError проверяет преобразование бизнес-ошибок в HTTP-ответы.
+//
+// Для каждой ошибки проверяются HTTP-код и машиночитаемый код в теле ответа,
+// в том числе для ошибок, обёрнутых через fmt.Errorf.
+func TestWriteBusinessError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{business.ErrSenderNotFound, http.StatusNotFound, "SENDER_NOT_FOUND"},
+		{business.ErrRecipientNotFound, http.StatusNotFound, "RECIPIENT_NOT_FOUND"},
+		{business.ErrWalletNotFound, http.StatusNotFound, "WALLET_NOT_FOUND"},
+		{business.ErrInsufficientFunds, http.StatusPaymentRequired, "INSUFFICIENT_FUNDS"},
+		{business.ErrInvalidAmount, http.StatusBadRequest, "INVALID_AMOUNT"},
+		{business.ErrSameAddress, http.StatusBadRequest, "SAME_ADDRESS"},
+		{fmt.Errorf("перевод: %w", business.ErrInsufficientFunds), http.StatusPaymentRequired, "INSUFFICIENT_FUNDS"},
+		{errors.New("соединение потеряно"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		writeBusinessError(c, tc.err, "Транзакция неуспешна")
+
+		if w.Code != tc.wantStatus {
+			t.Errorf("%v: ожидался статус %d, получен %d", tc.err, tc.wantStatus, w.Code)
+		}
+		var resp ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Не удалось разобрать ответ: %v", err)
+		}
+		if resp.Code != tc.wantCode {
+			t.Errorf("%v: ожидался код %q, получен %q", tc.err, tc.wantCode, resp.Code)
+		}
+	}
+}
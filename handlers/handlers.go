@@ -3,63 +3,100 @@
 package handlers
 
 import (
-	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 
 	"payment_system_api/business"
+	"payment_system_api/money"
 )
 
+// IdempotencyKeyHeader — заголовок запроса с ключом идемпотентности для POST /api/send.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength — максимальная длина ключа идемпотентности.
+const maxIdempotencyKeyLength = 255
+
+// Handler содержит HTTP-обработчики API, выполняющие операции через сервис бизнес-логики.
+type Handler struct {
+	service *business.Service
+}
+
+// NewHandler возвращает обработчики, работающие через сервис service.
+func NewHandler(service *business.Service) *Handler {
+	return &Handler{service: service}
+}
+
 // SendRequest представляет тело запроса для POST /api/send.
 type SendRequest struct {
-	From   string  `json:"from" binding:"required"`
-	To     string  `json:"to" binding:"required"`
-	Amount float64 `json:"amount"`
+	From     string         `json:"from" binding:"required"`
+	To       string         `json:"to" binding:"required"`
+	Amount   money.Decimal  `json:"amount" binding:"required"` // сумма десятичной строкой, например "12.34"
+	Currency money.Currency `json:"currency"`                  // необязательная валюта перевода ISO 4217
+
+	ToCurrency money.Currency `json:"to_currency"` // валюта зачисления для перевода с конвертацией
 }
 
 // SendHandler обрабатывает POST /api/send.
 //
 // Принимает JSON с From, To и Amount и выполняет транзакцию через бизнес-логику.
+// Если передан заголовок Idempotency-Key, повторный запрос с тем же ключом и телом
+// не выполняет перевод повторно, а возвращает исходную транзакцию
+// с заголовком Idempotent-Replayed: true.
+// Ошибки возвращаются в формате ErrorResponse.
 // Возвращает:
 // - 200 OK при успешной транзакции
+// - 409 Conflict, если ключ идемпотентности использован с другим телом запроса
+// - 422 Unprocessable Entity, если валюты кошельков или перевода не совпадают
+// или перевод нарушает лимиты расходов отправителя (код LIMIT_EXCEEDED)
+// - 503 Service Unavailable, если курс для конвертации недоступен или устарел
 // - 402 Payment Required, если недостаточно средств
+// - 403 Forbidden, если кошелек отправителя или получателя заморожен
 // - 404 Not Found, если кошелек не найден
 // - 400 Bad Request, если тело запроса неверное
 // - 500 Internal Server Error при других ошибках
-func SendHandler(c *gin.Context) {
+func (h *Handler) SendHandler(c *gin.Context) {
 	var req SendRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"ошибка": "Неверное тело запроса", "подробно": err.Error()})
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
 		return
 	}
 
-	err := business.SendMoney(req.From, req.To, req.Amount)
-	if err != nil {
-		// таблица соответствий бизнес-ошибок к HTTP-кодам
-		errorMap := map[string]int{
-			"недостаточно средств":                     http.StatusPaymentRequired,
-			"кошелек отправителя не найден":            http.StatusNotFound,
-			"кошелек получаетеля не найден":            http.StatusNotFound,
-			"сумма должна быть положительной":          http.StatusBadRequest,
-			"нельзя отправлять деньги на тот же адрес": http.StatusBadRequest,
-		}
-
-		if code, ok := errorMap[err.Error()]; ok {
-			c.JSON(code, gin.H{"ошибка": err.Error()})
-			return
-		}
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if len(key) > maxIdempotencyKeyLength {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Слишком длинный ключ идемпотентности", "")
+		return
+	}
 
-		// всё остальное — внутренняя ошибка
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"ошибка": "Транзакция неуспешна", "детали": err.Error()})
+	var (
+		transaction *business.TransactionResponse
+		replayed    bool
+		err         error
+	)
+	transfer := business.TransferRequest{
+		FromAddress: req.From,
+		ToAddress:   req.To,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		ToCurrency:  req.ToCurrency,
+	}
+	if key != "" {
+		transaction, replayed, err = h.service.SendMoneyIdempotent(key, transfer)
+	} else {
+		transaction, err = h.service.SendMoney(transfer)
+	}
+	if err != nil {
+		writeBusinessError(c, err, "Транзакция неуспешна")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"сообщение": "Транзакция успешна"})
+	if replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	c.JSON(http.StatusOK, gin.H{"сообщение": "Транзакция успешна", "транзакция": transaction})
 }
 
 // GetBalanceHandler обрабатывает GET /api/wallet/{address}/balance.
@@ -67,38 +104,152 @@ func SendHandler(c *gin.Context) {
 // Возвращает баланс указанного кошелька.
 // Если кошелек не найден — 404 Not Found.
 // При внутренних ошибках — 500 Internal Server Error.
-func GetBalanceHandler(c *gin.Context) {
+func (h *Handler) GetBalanceHandler(c *gin.Context) {
 	address := c.Param("address")
 
-	balance, err := business.GetWalletBalance(address)
+	balance, err := h.service.GetWalletBalance(address)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"ошибка": "Кошелек не найден"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"ошибка": "Не удалось получить баланс", "детали": err.Error()})
+		writeBusinessError(c, err, "Не удалось получить баланс")
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
+
+// GetWalletTransactionsHandler обрабатывает GET /api/wallet/{address}/transactions.
+//
+// Возвращает входящие и исходящие транзакции кошелька, отсортированные по времени
+// в порядке убывания. Необязательные параметры запроса:
+// - direction — in или out
+// - since, until — границы периода в формате RFC 3339 (until не включается)
+// - min_amount, max_amount — границы суммы в валюте кошелька
+// - count — размер страницы, по умолчанию 10, не больше business.MaxTransactionPageSize
+// - cursor — курсор next_cursor предыдущей страницы
+// Если параметры или курсор некорректны — 400 Bad Request.
+// Если кошелек не найден — 404 Not Found.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) GetWalletTransactionsHandler(c *gin.Context) {
+	filter, err := parseTransactionFilter(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверные параметры запроса", err.Error())
+		return
+	}
+
+	transactions, err := h.service.GetWalletTransactions(c.Param("address"), filter)
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить транзакции")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"адрес": address, "баланс": balance})
+	c.JSON(http.StatusOK, transactions)
+}
+
+// parseTransactionFilter разбирает параметры фильтра истории транзакций из запроса.
+func parseTransactionFilter(c *gin.Context) (business.TransactionFilter, error) {
+	var filter business.TransactionFilter
+
+	switch direction := c.Query("direction"); direction {
+	case "", business.DirectionIn, business.DirectionOut:
+		filter.Direction = direction
+	default:
+		return filter, fmt.Errorf("параметр 'direction' должен быть in или out")
+	}
+
+	for name, target := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := c.Query(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("параметр '%s' должен быть в формате RFC 3339", name)
+			}
+			*target = &parsed
+		}
+	}
+
+	for name, target := range map[string]*money.Decimal{"min_amount": &filter.MinAmount, "max_amount": &filter.MaxAmount} {
+		if value := c.Query(name); value != "" {
+			parsed, err := money.ParseDecimal(value)
+			if err != nil {
+				return filter, fmt.Errorf("параметр '%s': %w", name, err)
+			}
+			*target = parsed
+		}
+	}
+
+	count, err := parseCount(c)
+	if err != nil {
+		return filter, err
+	}
+	filter.Limit = count
+	filter.Cursor = c.Query("cursor")
+	return filter, nil
+}
+
+// parseCount разбирает размер страницы транзакций из параметра запроса count.
+func parseCount(c *gin.Context) (int, error) {
+	count, err := strconv.Atoi(c.DefaultQuery("count", "10"))
+	if err != nil || count <= 0 || count > business.MaxTransactionPageSize {
+		return 0, fmt.Errorf("параметр 'count' должен быть числом от 1 до %d", business.MaxTransactionPageSize)
+	}
+	return count, nil
 }
 
-// GetLastTransactionsHandler обрабатывает GET /api/transactions?count=N.
+// GetLastTransactionsHandler обрабатывает GET /api/transactions?count=N&cursor=C.
 //
-// Возвращает последние N транзакций.
-// Если параметр count некорректный — 400 Bad Request.
+// Возвращает страницу из N последних транзакций, по умолчанию 10,
+// N не может превышать business.MaxTransactionPageSize.
+// Следующая страница запрашивается с cursor, равным next_cursor текущей.
+// Если параметр count или курсор некорректны — 400 Bad Request.
 // При внутренних ошибках — 500 Internal Server Error.
-func GetLastTransactionsHandler(c *gin.Context) {
-	countStr := c.DefaultQuery("count", "10")
-	count, err := strconv.Atoi(countStr)
-	if err != nil || count <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"ошибка": "Неверный 'count' параметр"})
+func (h *Handler) GetLastTransactionsHandler(c *gin.Context) {
+	count, err := parseCount(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверный 'count' параметр", err.Error())
 		return
 	}
 
-	transactions, err := business.GetLastTransactions(count)
+	transactions, err := h.service.GetLastTransactions(count, c.Query("cursor"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"ошибка": "Не удалось получить транзакции"})
+		writeBusinessError(c, err, "Не удалось получить транзакции")
 		return
 	}
 	c.JSON(http.StatusOK, transactions)
 }
+
+// GetQuoteHandler обрабатывает GET /api/fx/quote?from=USD&to=RUB.
+//
+// Возвращает актуальную котировку обмена валюты from на to.
+// Если коды валют некорректны — 400 Bad Request.
+// Если курс неизвестен — 422 Unprocessable Entity.
+// Если конвертация не настроена или курс устарел — 503 Service Unavailable.
+func (h *Handler) GetQuoteHandler(c *gin.Context) {
+	from, fromErr := money.ParseCurrency(c.Query("from"))
+	to, toErr := money.ParseCurrency(c.Query("to"))
+	if fromErr != nil || toErr != nil {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверные параметры 'from' или 'to'", "")
+		return
+	}
+
+	quote, err := h.service.GetQuote(from, to)
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить котировку")
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
+
+// VerifyLedgerHandler обрабатывает GET /api/ledger/verify.
+//
+// Сверяет балансы кошельков с проводками главной книги и проверяет
+// сбалансированность записей журнала.
+// Возвращает 200 OK, если расхождений нет, и 409 Conflict с их списком, если они найдены.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) VerifyLedgerHandler(c *gin.Context) {
+	report, err := h.service.VerifyLedger()
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось сверить главную книгу")
+		return
+	}
+	if !report.Balanced {
+		c.JSON(http.StatusConflict, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,577 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+	"payment_system_api/database"
+	"payment_system_api/memory"
+)
+
+// TestParseTransactionFilter проверяет разбор параметров фильтра истории транзакций.
+func TestParseTransactionFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(query string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/api/wallet/abc/transactions?"+query, nil)
+		return c
+	}
+
+	filter, err := parseTransactionFilter(newContext(
+		"direction=in&since=2025-08-01T00:00:00Z&until=2025-09-01T00:00:00Z&min_amount=1.50&max_amount=100&count=5"))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if filter.Direction != "in" || filter.Since == nil || filter.Until == nil ||
+		filter.MinAmount != "1.50" || filter.MaxAmount != "100" || filter.Limit != 5 {
+		t.Errorf("Неверно разобран фильтр: %+v", filter)
+	}
+
+	filter, err = parseTransactionFilter(newContext(""))
+	if err != nil || filter.Direction != "" || filter.Since != nil || filter.Limit != 10 {
+		t.Errorf("Неверный фильтр по умолчанию: %+v, %v", filter, err)
+	}
+
+	for _, query := range []string{
+		"direction=both",
+		"since=yesterday",
+		"min_amount=1e3",
+		"max_amount=abc",
+		"count=0",
+		"count=x",
+		"count=101",
+	} {
+		if _, err := parseTransactionFilter(newContext(query)); err == nil {
+			t.Errorf("%s: ожидалась ошибка", query)
+		}
+	}
+}
+
+// TestWalletHandlers проверяет открытие и получение кошелька через обработчики,
+// созданные над сервисом с хранилищем в памяти.
+func TestWalletHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(business.NewService(memory.New(), business.Options{}))
+	router := gin.New()
+	router.POST("/api/wallets", h.CreateWalletHandler)
+	router.GET("/api/wallets/:address", h.GetWalletHandler)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/wallets", strings.NewReader(`{"currency":"USD"}`)))
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Ожидался статус 201, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	var created business.WalletResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &created); err != nil || created.Currency != "USD" {
+		t.Fatalf("Неверный ответ: %s, %v", recorder.Body, err)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/wallets/"+created.Address, nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/wallets/unknown", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+// TestWebhookHandlers проверяет регистрацию вебхука и работу с его доставками
+// через обработчики, созданные над сервисом с хранилищем в памяти.
+func TestWebhookHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := business.NewService(memory.New(), business.Options{})
+	h := NewHandler(service)
+	router := gin.New()
+	admin := router.Group("/api/admin", AdminAuth(map[string]string{"webhooks-token": "operator"}))
+	admin.POST("/webhooks", h.CreateWebhookHandler)
+	admin.GET("/webhooks", h.ListWebhooksHandler)
+	admin.DELETE("/webhooks/:id", h.DeleteWebhookHandler)
+	admin.GET("/webhooks/deliveries", h.ListWebhookDeliveriesHandler)
+	admin.POST("/webhooks/deliveries/:id/replay", h.ReplayWebhookDeliveryHandler)
+	admin.DELETE("/webhooks/deliveries/:id", h.DeleteWebhookDeliveryHandler)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		request.Header.Set("Authorization", "Bearer webhooks-token")
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/admin/webhooks", nil))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Вебхуки доступны без токена: статус %d", recorder.Code)
+	}
+
+	recorder = serve("POST", "/api/admin/webhooks", `{"url":"not a url"}`)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус 400, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	recorder = serve("POST", "/api/admin/webhooks", `{"url":"https://example.com/hook"}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Ожидался статус 201, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	var created business.WebhookResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &created); err != nil || created.Secret == "" {
+		t.Fatalf("Неверный ответ: %s, %v", recorder.Body, err)
+	}
+
+	recorder = serve("GET", "/api/admin/webhooks", "")
+	var listed []business.WebhookResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &listed); err != nil || len(listed) != 1 || listed[0].Secret != "" {
+		t.Errorf("Список вебхуков не должен содержать ключи: %s, %v", recorder.Body, err)
+	}
+
+	for _, query := range []string{"status=unknown", "webhook_id=x", "page=0"} {
+		if recorder := serve("GET", "/api/admin/webhooks/deliveries?"+query, ""); recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s: ожидался статус 400, получен %d", query, recorder.Code)
+		}
+	}
+	recorder = serve("GET", "/api/admin/webhooks/deliveries?status=pending", "")
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+
+	if recorder := serve("POST", "/api/admin/webhooks/deliveries/x/replay", ""); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус 400, получен %d", recorder.Code)
+	}
+	if recorder := serve("POST", "/api/admin/webhooks/deliveries/1/replay", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d", recorder.Code)
+	}
+	if recorder := serve("DELETE", "/api/admin/webhooks/deliveries/1", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d", recorder.Code)
+	}
+
+	target := "/api/admin/webhooks/" + strconv.FormatUint(uint64(created.ID), 10)
+	if recorder := serve("DELETE", target, ""); recorder.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("DELETE", target, ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d", recorder.Code)
+	}
+}
+
+// TestHoldHandlers проверяет резервирование, списание и отмену через обработчики,
+// созданные над сервисом с хранилищем в памяти.
+func TestHoldHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.New()
+	for _, address := range []string{"hold-a", "hold-b"} {
+		if err := store.Wallets().Create(&database.Wallet{Address: address, Balance: 10000}); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+	h := NewHandler(business.NewService(store, business.Options{}))
+	router := gin.New()
+	router.POST("/api/holds", h.CreateHoldHandler)
+	router.GET("/api/holds/:id", h.GetHoldHandler)
+	router.POST("/api/holds/:id/capture", h.CaptureHoldHandler)
+	router.POST("/api/holds/:id/void", h.VoidHoldHandler)
+	router.GET("/api/wallet/:address/balance", h.GetBalanceHandler)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	if recorder := serve("POST", "/api/holds", `{"wallet":"hold-a","to":"hold-b","amount":"10","expires_in":-1}`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус 400, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/holds", `{"wallet":"hold-a","to":"hold-b","amount":"1000"}`); recorder.Code != http.StatusPaymentRequired {
+		t.Errorf("Ожидался статус 402, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	recorder := serve("POST", "/api/holds", `{"wallet":"hold-a","to":"hold-b","amount":"30","expires_in":600}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Ожидался статус 201, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	var hold business.HoldResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &hold); err != nil || hold.ID == "" {
+		t.Fatalf("Неверный ответ: %s, %v", recorder.Body, err)
+	}
+
+	var balance business.BalanceResponse
+	recorder = serve("GET", "/api/wallet/hold-a/balance", "")
+	if err := json.Unmarshal(recorder.Body.Bytes(), &balance); err != nil || balance.Balance != "100.00" || balance.Available != "70.00" {
+		t.Errorf("Неверный баланс: %s, %v", recorder.Body, err)
+	}
+
+	if recorder := serve("POST", "/api/holds/"+hold.ID+"/capture", `{"amount":"31"}`); recorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Ожидался статус 422, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/holds/"+hold.ID+"/capture", `{"amount":"10"}`); recorder.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/holds/"+hold.ID+"/void", ""); recorder.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/holds/"+hold.ID+"/capture", ""); recorder.Code != http.StatusConflict {
+		t.Errorf("Ожидался статус 409, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("GET", "/api/holds/unknown", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+// TestRefundHandlers проверяет коды ответов эндпоинта возвратов.
+func TestRefundHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.New()
+	for _, address := range []string{"refund-a", "refund-b"} {
+		if err := store.Wallets().Create(&database.Wallet{Address: address, Balance: 10000}); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+	service := business.NewService(store, business.Options{})
+	payment, err := service.SendMoney(business.TransferRequest{FromAddress: "refund-a", ToAddress: "refund-b", Amount: "30"})
+	if err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	h := NewHandler(service)
+	router := gin.New()
+	router.POST("/api/transactions/:uuid/refund", h.RefundTransactionHandler)
+
+	serve := func(target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("POST", target, strings.NewReader(body)))
+		return recorder
+	}
+
+	target := "/api/transactions/" + payment.UUID + "/refund"
+	if recorder := serve(target, `{"amount":`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус 400, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve(target, `{"amount":"30.01"}`); recorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Ожидался статус 422, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	recorder := serve(target, `{"amount":"10"}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Ожидался статус 201, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	var refund business.TransactionResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &refund); err != nil || refund.RefundOf != payment.UUID {
+		t.Fatalf("Неверный ответ: %s, %v", recorder.Body, err)
+	}
+	if recorder := serve("/api/transactions/"+refund.UUID+"/refund", ""); recorder.Code != http.StatusConflict {
+		t.Errorf("Ожидался статус 409, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve(target, ""); recorder.Code != http.StatusCreated {
+		t.Errorf("Ожидался статус 201, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("/api/transactions/unknown/refund", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+// TestSendBatchHandler проверяет коды ответов и формат ошибки пакетных переводов.
+func TestSendBatchHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.New()
+	for _, address := range []string{"batch-a", "batch-b"} {
+		if err := store.Wallets().Create(&database.Wallet{Address: address, Balance: 10000}); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+	h := NewHandler(business.NewService(store, business.Options{}))
+	router := gin.New()
+	router.POST("/api/send/batch", h.SendBatchHandler)
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/send/batch", strings.NewReader(body)))
+		return recorder
+	}
+
+	if recorder := serve(`{"from":"batch-a","transfers":[]}`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус 400, получен %d: %s", recorder.Code, recorder.Body)
+	}
+
+	recorder := serve(`{"from":"batch-a","transfers":[{"to":"batch-b","amount":"1"},{"to":"batch-b","amount":"1000"}]}`)
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Ожидался статус 422, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	var failed BatchErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &failed); err != nil {
+		t.Fatalf("Не удалось разобрать ответ: %v", err)
+	}
+	if failed.Code != business.ErrBatchFailed.Code || failed.Batch == nil || len(failed.Batch.Lines) != 2 ||
+		failed.Batch.Lines[1].ErrorCode != business.ErrInsufficientFunds.Code ||
+		!strings.Contains(recorder.Body.String(), `"error_code":"INSUFFICIENT_FUNDS"`) {
+		t.Errorf("Неверный ответ: %s", recorder.Body)
+	}
+
+	recorder = serve(`{"from":"batch-a","transfers":[{"to":"batch-b","amount":"1"},{"to":"batch-b","amount":"2"}]}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	var batch business.BatchResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &batch); err != nil || batch.ID == "" || batch.Total != "3.00" {
+		t.Errorf("Неверный ответ: %s, %v", recorder.Body, err)
+	}
+}
+
+// TestScheduledTransferHandlers проверяет коды ответов эндпоинтов запланированных переводов.
+func TestScheduledTransferHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.New()
+	for _, address := range []string{"scheduled-a", "scheduled-b"} {
+		if err := store.Wallets().Create(&database.Wallet{Address: address, Balance: 10000}); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+	h := NewHandler(business.NewService(store, business.Options{}))
+	router := gin.New()
+	router.POST("/api/scheduled-transfers", h.ScheduleTransferHandler)
+	router.GET("/api/scheduled-transfers/:id", h.GetScheduledTransferHandler)
+	router.POST("/api/scheduled-transfers/:id/cancel", h.CancelScheduledTransferHandler)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	if recorder := serve("POST", "/api/scheduled-transfers", `{"from":"scheduled-a","to":"scheduled-b","amount":"10","execute_at":"2000-01-01T00:00:00Z"}`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус 400, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	executeAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if recorder := serve("POST", "/api/scheduled-transfers", `{"from":"scheduled-a","to":"scheduled-unknown","amount":"10","execute_at":"`+executeAt+`"}`); recorder.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	recorder := serve("POST", "/api/scheduled-transfers", `{"from":"scheduled-a","to":"scheduled-b","amount":"10","execute_at":"`+executeAt+`"}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Ожидался статус 201, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	var scheduled business.ScheduledTransferResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &scheduled); err != nil || scheduled.ID == "" || scheduled.Status != database.ScheduledPending {
+		t.Fatalf("Неверный ответ: %s, %v", recorder.Body, err)
+	}
+
+	if recorder := serve("GET", "/api/scheduled-transfers/"+scheduled.ID, ""); recorder.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/scheduled-transfers/"+scheduled.ID+"/cancel", ""); recorder.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/scheduled-transfers/"+scheduled.ID+"/cancel", ""); recorder.Code != http.StatusConflict {
+		t.Errorf("Ожидался статус 409, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("GET", "/api/scheduled-transfers/unknown", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+// TestStandingOrderHandlers проверяет коды ответов эндпоинтов регулярных платежей.
+func TestStandingOrderHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.New()
+	for _, address := range []string{"standing-a", "standing-b"} {
+		if err := store.Wallets().Create(&database.Wallet{Address: address, Balance: 10000}); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+	service := business.NewService(store, business.Options{})
+	h := NewHandler(service)
+	router := gin.New()
+	router.POST("/api/standing-orders", h.CreateStandingOrderHandler)
+	router.GET("/api/standing-orders/:id", h.GetStandingOrderHandler)
+	router.GET("/api/standing-orders/:id/runs", h.GetStandingOrderRunsHandler)
+	router.POST("/api/standing-orders/:id/cancel", h.CancelStandingOrderHandler)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	if recorder := serve("POST", "/api/standing-orders", `{"from":"standing-a","to":"standing-b","amount":"10","cron":"0 9 * * *","interval":3600}`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус 400, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	recorder := serve("POST", "/api/standing-orders", `{"from":"standing-a","to":"standing-b","amount":"10","interval":3600,"max_runs":12}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Ожидался статус 201, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	var order business.StandingOrderResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &order); err != nil || order.ID == "" || order.Status != database.StandingOrderActive {
+		t.Fatalf("Неверный ответ: %s, %v", recorder.Body, err)
+	}
+
+	if _, err := service.ExecuteStandingOrders(10); err != nil {
+		t.Fatalf("Не удалось выполнить регулярные платежи: %v", err)
+	}
+	recorder = serve("GET", "/api/standing-orders/"+order.ID+"/runs", "")
+	var runs []business.StandingOrderRunResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &runs); err != nil || len(runs) != 1 || runs[0].Status != database.StandingOrderRunExecuted {
+		t.Errorf("Неверная история: %s, %v", recorder.Body, err)
+	}
+
+	if recorder := serve("GET", "/api/standing-orders/"+order.ID, ""); recorder.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/standing-orders/"+order.ID+"/cancel", ""); recorder.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/standing-orders/"+order.ID+"/cancel", ""); recorder.Code != http.StatusConflict {
+		t.Errorf("Ожидался статус 409, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("GET", "/api/standing-orders/unknown/runs", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+// TestWalletLimitsHandlers проверяет коды ответов эндпоинтов лимитов расходов
+// и ответ перевода, нарушающего лимит.
+func TestWalletLimitsHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.New()
+	for _, address := range []string{"limits-a", "limits-b"} {
+		if err := store.Wallets().Create(&database.Wallet{Address: address, Balance: 10000}); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+	h := NewHandler(business.NewService(store, business.Options{}))
+	router := gin.New()
+	router.POST("/api/send", h.SendHandler)
+	admin := router.Group("/api/admin", AdminAuth(map[string]string{"limits-token": "operator"}))
+	admin.GET("/wallets/:address/limits", h.GetWalletLimitsHandler)
+	admin.PUT("/wallets/:address/limits", h.SetWalletLimitsHandler)
+	admin.DELETE("/wallets/:address/limits", h.DeleteWalletLimitsHandler)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		request.Header.Set("Authorization", "Bearer limits-token")
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if recorder := serve("PUT", "/api/admin/wallets/limits-a/limits", `{"max_transfer":"-1"}`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус 400, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("PUT", "/api/admin/wallets/limits-unknown/limits", `{}`); recorder.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("PUT", "/api/admin/wallets/limits-a/limits", `{"max_transfer":"10","hourly_count":5}`); recorder.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+
+	recorder := serve("POST", "/api/send", `{"from":"limits-a","to":"limits-b","amount":"10.01"}`)
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Ожидался статус 422, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	var resp ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil || resp.Code != "LIMIT_EXCEEDED" {
+		t.Errorf("Неверный ответ: %s, %v", recorder.Body, err)
+	}
+
+	recorder = serve("GET", "/api/admin/wallets/limits-a/limits", "")
+	var limits business.WalletLimitsResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &limits); err != nil || limits.MaxTransfer == nil || *limits.MaxTransfer != "10.00" || limits.DailyTotal != nil {
+		t.Errorf("Неверные лимиты: %s, %v", recorder.Body, err)
+	}
+
+	if recorder := serve("DELETE", "/api/admin/wallets/limits-a/limits", ""); recorder.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/send", `{"from":"limits-a","to":"limits-b","amount":"10.01"}`); recorder.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+// TestWalletStatusHandlers проверяет заморозку и разморозку кошелька через API:
+// коды ответов, отказ в переводе с замороженного кошелька, состояние в ответе
+// о балансе, закрытие с причиной, историю изменений состояния и запись
+// исполнителем администратора из токена, а не из тела запроса.
+func TestWalletStatusHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.New()
+	for _, address := range []string{"status-a", "status-b"} {
+		if err := store.Wallets().Create(&database.Wallet{Address: address, Balance: 10000}); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+	h := NewHandler(business.NewService(store, business.Options{}))
+	router := gin.New()
+	router.POST("/api/send", h.SendHandler)
+	router.GET("/api/wallet/:address/balance", h.GetBalanceHandler)
+	router.DELETE("/api/wallets/:address", h.CloseWalletHandler)
+	admin := router.Group("/api/admin", AdminAuth(map[string]string{"status-token": "security"}))
+	admin.POST("/wallets/:address/freeze", h.FreezeWalletHandler)
+	admin.POST("/wallets/:address/unfreeze", h.UnfreezeWalletHandler)
+	admin.GET("/wallets/:address/status-history", h.GetWalletStatusHistoryHandler)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		if strings.HasPrefix(target, "/api/admin/") {
+			request.Header.Set("Authorization", "Bearer status-token")
+		}
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if recorder := serve("POST", "/api/admin/wallets/status-a/freeze", `{"actor":"security"}`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус 400, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/admin/wallets/status-unknown/freeze", `{"reason":"взлом"}`); recorder.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/admin/wallets/status-a/unfreeze", `{"reason":"проверка"}`); recorder.Code != http.StatusConflict {
+		t.Errorf("Ожидался статус 409, получен %d: %s", recorder.Code, recorder.Body)
+	}
+
+	// Исполнитель из тела запроса игнорируется
+	recorder := serve("POST", "/api/admin/wallets/status-a/freeze", `{"reason":"взлом","actor":"someone-else","block_incoming":true}`)
+	var wallet business.WalletResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &wallet); err != nil || wallet.Status != "frozen" || !wallet.BlockIncoming {
+		t.Fatalf("Неверный ответ при заморозке: %d %s, %v", recorder.Code, recorder.Body, err)
+	}
+
+	recorder = serve("POST", "/api/send", `{"from":"status-a","to":"status-b","amount":"1"}`)
+	var resp ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil || recorder.Code != http.StatusForbidden || resp.Code != "SENDER_FROZEN" {
+		t.Errorf("Ожидался статус 403 с кодом SENDER_FROZEN, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	recorder = serve("POST", "/api/send", `{"from":"status-b","to":"status-a","amount":"1"}`)
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil || recorder.Code != http.StatusForbidden || resp.Code != "RECIPIENT_FROZEN" {
+		t.Errorf("Ожидался статус 403 с кодом RECIPIENT_FROZEN, получен %d: %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = serve("GET", "/api/wallet/status-a/balance", "")
+	var balance business.BalanceResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &balance); err != nil || balance.Status != "frozen" {
+		t.Errorf("Баланс должен содержать состояние frozen: %s, %v", recorder.Body, err)
+	}
+
+	if recorder := serve("POST", "/api/admin/wallets/status-a/unfreeze", `{"reason":"проверено"}`); recorder.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("POST", "/api/send", `{"from":"status-a","to":"status-b","amount":"100"}`); recorder.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve("DELETE", "/api/wallets/status-a", `{"reason":"по заявлению","actor":"client"}`); recorder.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = serve("GET", "/api/admin/wallets/status-a/status-history", "")
+	var history []business.WalletStatusChangeResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &history); err != nil || len(history) != 3 ||
+		history[0].Actor != "security" || history[1].Actor != "security" ||
+		history[2].ToStatus != "closed" || history[2].Reason != "по заявлению" || history[2].Actor != "" {
+		t.Errorf("Неверная история состояний: %s, %v", recorder.Body, err)
+	}
+}
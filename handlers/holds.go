@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+	"payment_system_api/money"
+)
+
+// CreateHoldRequest представляет тело запроса для POST /api/holds.
+type CreateHoldRequest struct {
+	Wallet    string         `json:"wallet" binding:"required"` // кошелёк, на котором резервируются средства
+	To        string         `json:"to" binding:"required"`     // кошелёк, на который будут зачислены списания
+	Amount    money.Decimal  `json:"amount" binding:"required"` // сумма десятичной строкой, например "12.34"
+	Currency  money.Currency `json:"currency"`                  // необязательная валюта ISO 4217
+	ExpiresIn int64          `json:"expires_in"`                // срок резервирования в секундах, 0 — по умолчанию
+}
+
+// CaptureHoldRequest представляет тело запроса для POST /api/holds/{id}/capture.
+type CaptureHoldRequest struct {
+	Amount *money.Decimal `json:"amount"` // списываемая сумма, без неё — весь остаток
+}
+
+// CreateHoldHandler обрабатывает POST /api/holds.
+//
+// Резервирует сумму на кошельке Wallet для последующего списания на кошелёк To.
+// Зарезервированная сумма уменьшает доступный баланс кошелька, но не учётный.
+// Возвращает:
+// - 201 Created со сведениями о резервировании
+// - 400 Bad Request, если тело запроса, сумма или срок неверные
+// - 402 Payment Required, если доступного баланса недостаточно
+// - 403 Forbidden, если кошелек отправителя или получателя заморожен
+// - 404 Not Found, если кошелек не найден
+// - 422 Unprocessable Entity, если валюты кошельков не совпадают
+// или резервирование нарушает лимиты расходов кошелька (код LIMIT_EXCEEDED)
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) CreateHoldHandler(c *gin.Context) {
+	var req CreateHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+	if req.ExpiresIn < 0 || req.ExpiresIn > int64(business.MaxHoldTTL/time.Second) {
+		writeBusinessError(c, business.ErrInvalidHoldTTL, "")
+		return
+	}
+
+	hold, err := h.service.CreateHold(business.HoldRequest{
+		WalletAddress: req.Wallet,
+		ToAddress:     req.To,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		TTL:           time.Duration(req.ExpiresIn) * time.Second,
+	})
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось зарезервировать средства")
+		return
+	}
+	c.JSON(http.StatusCreated, hold)
+}
+
+// GetHoldHandler обрабатывает GET /api/holds/{id}.
+//
+// Возвращает сведения о резервировании.
+// Если резервирование не найдено — 404 Not Found.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) GetHoldHandler(c *gin.Context) {
+	hold, err := h.service.GetHold(c.Param("id"))
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить резервирование")
+		return
+	}
+	c.JSON(http.StatusOK, hold)
+}
+
+// CaptureHoldHandler обрабатывает POST /api/holds/{id}/capture.
+//
+// Списывает сумму из резервирования переводом на кошелёк получателя.
+// Без тела запроса или суммы списывается весь несписанный остаток;
+// резервирование можно списывать частями. Лимиты расходов при списании
+// не проверяются: резервирование проверено по ним при создании.
+// Возвращает:
+// - 200 OK с резервированием и транзакцией перевода
+// - 400 Bad Request, если тело запроса или сумма неверные
+// - 404 Not Found, если резервирование или кошелек получателя не найдены
+// - 409 Conflict, если резервирование уже списано, отменено или истекло
+// - 422 Unprocessable Entity, если сумма больше остатка резервирования
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) CaptureHoldHandler(c *gin.Context) {
+	var req CaptureHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+
+	captured, err := h.service.CaptureHold(c.Param("id"), req.Amount)
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось списать зарезервированные средства")
+		return
+	}
+	c.JSON(http.StatusOK, captured)
+}
+
+// VoidHoldHandler обрабатывает POST /api/holds/{id}/void.
+//
+// Отменяет резервирование и освобождает несписанный остаток.
+// Возвращает:
+// - 200 OK с отменённым резервированием
+// - 404 Not Found, если резервирование не найдено
+// - 409 Conflict, если резервирование уже списано, отменено или истекло
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) VoidHoldHandler(c *gin.Context) {
+	hold, err := h.service.VoidHold(c.Param("id"))
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось отменить резервирование")
+		return
+	}
+	c.JSON(http.StatusOK, hold)
+}
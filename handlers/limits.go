@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+	"payment_system_api/money"
+)
+
+// SetWalletLimitsRequest представляет тело запроса для PUT /api/admin/wallets/{address}/limits.
+//
+// Отсутствующее поле или null снимает соответствующий лимит.
+type SetWalletLimitsRequest struct {
+	MaxTransfer  *money.Decimal `json:"max_transfer"`  // максимальная сумма одного перевода в валюте кошелька
+	DailyTotal   *money.Decimal `json:"daily_total"`   // лимит расходов за сутки UTC
+	MonthlyTotal *money.Decimal `json:"monthly_total"` // лимит расходов за месяц UTC
+	HourlyCount  *int           `json:"hourly_count"`  // лимит числа переводов за последний час
+}
+
+// GetWalletLimitsHandler обрабатывает GET /api/admin/wallets/{address}/limits.
+//
+// Возвращает лимиты расходов кошелька и их текущее использование.
+// Доступен только администраторам (см. AdminAuth).
+// Если кошелёк не найден — 404 Not Found.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) GetWalletLimitsHandler(c *gin.Context) {
+	limits, err := h.service.GetWalletLimits(c.Param("address"))
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить лимиты кошелька")
+		return
+	}
+	c.JSON(http.StatusOK, limits)
+}
+
+// SetWalletLimitsHandler обрабатывает PUT /api/admin/wallets/{address}/limits.
+//
+// Заменяет лимиты расходов кошелька. Доступен только администраторам (см. AdminAuth).
+// Возвращает:
+// - 200 OK с новыми лимитами
+// - 400 Bad Request, если тело запроса неверное или лимит не положителен
+// - 404 Not Found, если кошелек не найден
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) SetWalletLimitsHandler(c *gin.Context) {
+	var req SetWalletLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+
+	limits, err := h.service.SetWalletLimits(c.Param("address"), business.WalletLimitsRequest{
+		MaxTransfer:  req.MaxTransfer,
+		DailyTotal:   req.DailyTotal,
+		MonthlyTotal: req.MonthlyTotal,
+		HourlyCount:  req.HourlyCount,
+	})
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось установить лимиты кошелька")
+		return
+	}
+	c.JSON(http.StatusOK, limits)
+}
+
+// DeleteWalletLimitsHandler обрабатывает DELETE /api/admin/wallets/{address}/limits.
+//
+// Снимает все лимиты расходов кошелька. Доступен только администраторам (см. AdminAuth).
+// Если кошелёк не найден — 404 Not Found.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) DeleteWalletLimitsHandler(c *gin.Context) {
+	if err := h.service.DeleteWalletLimits(c.Param("address")); err != nil {
+		writeBusinessError(c, err, "Не удалось снять лимиты кошелька")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"сообщение": "Лимиты кошелька сняты"})
+}
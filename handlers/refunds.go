@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/money"
+)
+
+// RefundRequest представляет тело запроса для POST /api/transactions/{uuid}/refund.
+type RefundRequest struct {
+	Amount *money.Decimal `json:"amount"` // возвращаемая сумма в валюте перевода, без неё — весь невозвращённый остаток
+}
+
+// RefundTransactionHandler обрабатывает POST /api/transactions/{uuid}/refund.
+//
+// Возвращает отправителю транзакции сумму из тела запроса переводом
+// в обратном направлении. Без тела запроса или суммы возвращается весь
+// невозвращённый остаток; транзакцию можно возвращать частями.
+// Возвращает:
+// - 201 Created с транзакцией возврата
+// - 400 Bad Request, если тело запроса или сумма неверные
+// - 404 Not Found, если транзакция или кошельки её участников не найдены
+// - 409 Conflict, если транзакция сама является возвратом или у получателя
+// недостаточно средств для возврата
+// - 422 Unprocessable Entity, если сумма больше невозвращённого остатка
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) RefundTransactionHandler(c *gin.Context) {
+	var req RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+
+	refund, err := h.service.RefundTransaction(c.Param("uuid"), req.Amount)
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось выполнить возврат")
+		return
+	}
+	c.JSON(http.StatusCreated, refund)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+	"payment_system_api/money"
+)
+
+// ScheduleTransferRequest представляет тело запроса для POST /api/scheduled-transfers.
+type ScheduleTransferRequest struct {
+	From      string         `json:"from" binding:"required"`
+	To        string         `json:"to" binding:"required"`
+	Amount    money.Decimal  `json:"amount" binding:"required"`     // сумма десятичной строкой, например "12.34"
+	Currency  money.Currency `json:"currency"`                      // необязательная валюта перевода ISO 4217
+	ExecuteAt time.Time      `json:"execute_at" binding:"required"` // время выполнения в формате RFC 3339
+
+	ToCurrency money.Currency `json:"to_currency"` // валюта зачисления для перевода с конвертацией
+}
+
+// ScheduleTransferHandler обрабатывает POST /api/scheduled-transfers.
+//
+// Планирует перевод на время execute_at. Кошельки, валюты и сумма проверяются
+// сразу, достаточность средств — в момент выполнения.
+// Возвращает:
+// - 201 Created с запланированным переводом
+// - 400 Bad Request, если тело запроса, сумма или время выполнения неверные
+// - 404 Not Found, если кошелек не найден
+// - 422 Unprocessable Entity, если валюты кошельков или перевода не совпадают
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) ScheduleTransferHandler(c *gin.Context) {
+	var req ScheduleTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+
+	scheduled, err := h.service.ScheduleTransfer(business.ScheduledTransferRequest{
+		TransferRequest: business.TransferRequest{
+			FromAddress: req.From,
+			ToAddress:   req.To,
+			Amount:      req.Amount,
+			Currency:    req.Currency,
+			ToCurrency:  req.ToCurrency,
+		},
+		ExecuteAt: req.ExecuteAt,
+	})
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось запланировать перевод")
+		return
+	}
+	c.JSON(http.StatusCreated, scheduled)
+}
+
+// GetScheduledTransferHandler обрабатывает GET /api/scheduled-transfers/{id}.
+//
+// Возвращает запланированный перевод с его состоянием и результатом выполнения.
+// Если перевод не найден — 404 Not Found.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) GetScheduledTransferHandler(c *gin.Context) {
+	scheduled, err := h.service.GetScheduledTransfer(c.Param("id"))
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить запланированный перевод")
+		return
+	}
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// CancelScheduledTransferHandler обрабатывает POST /api/scheduled-transfers/{id}/cancel.
+//
+// Отменяет перевод, который ещё ожидает выполнения.
+// Возвращает:
+// - 200 OK с отменённым переводом
+// - 404 Not Found, если перевод не найден
+// - 409 Conflict, если перевод уже выполнен, неуспешен или отменён
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) CancelScheduledTransferHandler(c *gin.Context) {
+	scheduled, err := h.service.CancelScheduledTransfer(c.Param("id"))
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось отменить запланированный перевод")
+		return
+	}
+	c.JSON(http.StatusOK, scheduled)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+	"payment_system_api/money"
+)
+
+// CreateStandingOrderRequest представляет тело запроса для POST /api/standing-orders.
+//
+// Расписание задаётся ровно одним из полей cron и interval.
+type CreateStandingOrderRequest struct {
+	From     string         `json:"from" binding:"required"`
+	To       string         `json:"to" binding:"required"`
+	Amount   money.Decimal  `json:"amount" binding:"required"` // сумма десятичной строкой, например "12.34"
+	Currency money.Currency `json:"currency"`                  // необязательная валюта перевода ISO 4217
+
+	ToCurrency money.Currency `json:"to_currency"` // валюта зачисления для перевода с конвертацией
+
+	Cron          string     `json:"cron"`           // cron-выражение из пяти полей в UTC
+	Interval      int64      `json:"interval"`       // интервал между выполнениями в секундах
+	StartAt       *time.Time `json:"start_at"`       // необязательное начало расписания, по умолчанию — сейчас
+	EndAt         *time.Time `json:"end_at"`         // необязательная дата окончания
+	MaxRuns       int        `json:"max_runs"`       // необязательное максимальное число выполнений
+	MaxRetries    *int       `json:"max_retries"`    // необязательное число повторных попыток при недостатке средств
+	RetryInterval int64      `json:"retry_interval"` // необязательная задержка перед повторной попыткой в секундах
+}
+
+// CreateStandingOrderHandler обрабатывает POST /api/standing-orders.
+//
+// Создаёт регулярный платёж, который фоновая задача выполняет по расписанию.
+// Кошельки, валюты и сумма проверяются сразу, достаточность средств — при каждом выполнении.
+// Возвращает:
+// - 201 Created с регулярным платежом
+// - 400 Bad Request, если тело запроса, сумма или расписание неверные
+// - 404 Not Found, если кошелек не найден
+// - 422 Unprocessable Entity, если валюты кошельков или перевода не совпадают
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) CreateStandingOrderHandler(c *gin.Context) {
+	var req CreateStandingOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+
+	orderReq := business.StandingOrderRequest{
+		TransferRequest: business.TransferRequest{
+			FromAddress: req.From,
+			ToAddress:   req.To,
+			Amount:      req.Amount,
+			Currency:    req.Currency,
+			ToCurrency:  req.ToCurrency,
+		},
+		Cron:          req.Cron,
+		Interval:      time.Duration(req.Interval) * time.Second,
+		EndAt:         req.EndAt,
+		MaxRuns:       req.MaxRuns,
+		MaxRetries:    req.MaxRetries,
+		RetryInterval: time.Duration(req.RetryInterval) * time.Second,
+	}
+	if req.StartAt != nil {
+		orderReq.StartAt = *req.StartAt
+	}
+	order, err := h.service.CreateStandingOrder(orderReq)
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось создать регулярный платёж")
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+// GetStandingOrderHandler обрабатывает GET /api/standing-orders/{id}.
+//
+// Возвращает регулярный платёж с его состоянием и моментом следующей попытки.
+// Если платёж не найден — 404 Not Found.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) GetStandingOrderHandler(c *gin.Context) {
+	order, err := h.service.GetStandingOrder(c.Param("id"))
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить регулярный платёж")
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// GetStandingOrderRunsHandler обрабатывает GET /api/standing-orders/{id}/runs.
+//
+// Возвращает историю попыток выполнения регулярного платежа.
+// Если платёж не найден — 404 Not Found.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) GetStandingOrderRunsHandler(c *gin.Context) {
+	runs, err := h.service.GetStandingOrderRuns(c.Param("id"))
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить историю регулярного платежа")
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// CancelStandingOrderHandler обрабатывает POST /api/standing-orders/{id}/cancel.
+//
+// Отменяет активный регулярный платёж.
+// Возвращает:
+// - 200 OK с отменённым платежом
+// - 404 Not Found, если платёж не найден
+// - 409 Conflict, если платёж уже завершён или отменён
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) CancelStandingOrderHandler(c *gin.Context) {
+	order, err := h.service.CancelStandingOrder(c.Param("id"))
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось отменить регулярный платёж")
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"payment_system_api/business"
+)
+
+// LastEventIDHeader — заголовок, в котором клиент SSE при переподключении
+// передаёт идентификатор последнего полученного события.
+const LastEventIDHeader = "Last-Event-ID"
+
+// streamHeartbeat — период проверки соединения потока при отсутствии транзакций.
+const streamHeartbeat = 15 * time.Second
+
+// streamWriteTimeout — максимальное время записи сообщения WebSocket.
+const streamWriteTimeout = 10 * time.Second
+
+// StreamMessage — сообщение потока транзакций через WebSocket.
+type StreamMessage struct {
+	ID          string                       `json:"id"`          // идентификатор события для возобновления потока
+	Transaction business.TransactionResponse `json:"transaction"` // новая транзакция
+}
+
+// upgrader переводит HTTP-соединение в WebSocket.
+//
+// Запросы с любых источников разрешены: API не использует cookie,
+// а панели мониторинга открываются с других доменов.
+var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+// StreamTransactionsHandler обрабатывает GET /api/transactions/stream?address=A.
+//
+// Передаёт новые транзакции в формате Server-Sent Events по мере их фиксации:
+// событие transaction с непрозрачным идентификатором события в поле id
+// и TransactionResponse в поле data. Необязательный параметр address оставляет
+// только переводы кошелька. Транзакции читаются из общего хранилища, поэтому
+// поток получает и переводы других экземпляров сервиса (см. Options.StreamPollInterval).
+// При переподключении клиент передаёт заголовок Last-Event-ID (или параметр
+// last_event_id) и получает все транзакции, зафиксированные после этого события.
+// При отсутствии транзакций периодически отправляется комментарий-пинг.
+// Возвращает:
+// - 200 OK с потоком событий
+// - 400 Bad Request, если идентификатор последнего события неверный (код INVALID_CURSOR)
+// - 404 Not Found, если кошелек не найден
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) StreamTransactionsHandler(c *gin.Context) {
+	lastEventID := c.GetHeader(LastEventIDHeader)
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	stream, ok := h.subscribeTransactions(c, lastEventID)
+	if !ok {
+		return
+	}
+	defer stream.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	for {
+		event, err := nextStreamEvent(c.Request.Context(), stream)
+		switch {
+		case err != nil:
+			return
+		case event == nil:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+		default:
+			data, err := json.Marshal(event.Transaction)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(c.Writer, "id: %s\nevent: transaction\ndata: %s\n\n", event.ID, data)
+		}
+		c.Writer.Flush()
+	}
+}
+
+// StreamTransactionsWSHandler обрабатывает GET /api/transactions/stream/ws?address=A&last_event_id=ID.
+//
+// Передаёт новые транзакции через WebSocket текстовыми сообщениями StreamMessage
+// по мере их фиксации. Параметры совпадают с StreamTransactionsHandler,
+// идентификатор последнего полученного события передаётся в last_event_id.
+// При отсутствии транзакций периодически отправляется ping.
+// До установления соединения ошибки возвращаются как в StreamTransactionsHandler.
+func (h *Handler) StreamTransactionsWSHandler(c *gin.Context) {
+	stream, ok := h.subscribeTransactions(c, c.Query("last_event_id"))
+	if !ok {
+		return
+	}
+	defer stream.Close()
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade уже отправил ответ с ошибкой
+		return
+	}
+	defer conn.Close()
+
+	// Входящие сообщения не ожидаются; чтение нужно, чтобы обработать
+	// управляющие сообщения и заметить закрытие соединения клиентом
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		event, err := nextStreamEvent(ctx, stream)
+		if err := conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+			return
+		}
+		switch {
+		case err != nil:
+			return
+		case event == nil:
+			err = conn.WriteMessage(websocket.PingMessage, nil)
+		default:
+			err = conn.WriteJSON(StreamMessage{ID: event.ID, Transaction: event.Transaction})
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// subscribeTransactions открывает поток транзакций по параметру запроса address
+// с идентификатором последнего полученного события lastEventID (пустая строка — нет).
+//
+// При ошибке отправляет ответ с ней и возвращает false.
+func (h *Handler) subscribeTransactions(c *gin.Context, lastEventID string) (*business.TransactionStream, bool) {
+	stream, err := h.service.SubscribeTransactions(c.Query("address"), lastEventID)
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось открыть поток транзакций")
+		return nil, false
+	}
+	return stream, true
+}
+
+// nextStreamEvent ожидает следующее событие потока stream не дольше streamHeartbeat
+// и возвращает nil без ошибки, если событий за это время не было.
+func nextStreamEvent(ctx context.Context, stream *business.TransactionStream) (*business.TransactionEvent, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, streamHeartbeat)
+	defer cancel()
+	event, err := stream.Next(waitCtx)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, nil
+	}
+	return event, err
+}
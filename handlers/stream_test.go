@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"payment_system_api/business"
+	"payment_system_api/database"
+	"payment_system_api/memory"
+)
+
+// newStreamServer возвращает тестовый сервер с обработчиками потоков транзакций
+// и сервис над хранилищем в памяти с кошельками stream-a и stream-b.
+func newStreamServer(t *testing.T) (*httptest.Server, *business.Service) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store := memory.New()
+	for _, address := range []string{"stream-a", "stream-b"} {
+		if err := store.Wallets().Create(&database.Wallet{Address: address, Balance: 10000}); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+	service := business.NewService(store, business.Options{})
+	h := NewHandler(service)
+	router := gin.New()
+	router.GET("/api/transactions/stream", h.StreamTransactionsHandler)
+	router.GET("/api/transactions/stream/ws", h.StreamTransactionsWSHandler)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, service
+}
+
+// sendTestTransfer переводит единицу с кошелька stream-a на stream-b.
+func sendTestTransfer(t *testing.T, service *business.Service) *business.TransactionResponse {
+	t.Helper()
+	response, err := service.SendMoney(business.TransferRequest{FromAddress: "stream-a", ToAddress: "stream-b", Amount: "1"})
+	if err != nil {
+		t.Fatalf("Не удалось выполнить перевод: %v", err)
+	}
+	return response
+}
+
+// TestStreamTransactionsHandler проверяет поток транзакций в формате
+// Server-Sent Events и его возобновление по заголовку Last-Event-ID.
+func TestStreamTransactionsHandler(t *testing.T) {
+	server, service := newStreamServer(t)
+
+	resp, err := http.Get(server.URL + "/api/transactions/stream?address=unknown")
+	if err != nil {
+		t.Fatalf("Запрос не выполнен: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Ожидался статус 404, получен %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/api/transactions/stream?address=stream-b")
+	if err != nil {
+		t.Fatalf("Запрос не выполнен: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Неверный ответ: статус %d, Content-Type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	first := sendTestTransfer(t, service)
+	second := sendTestTransfer(t, service)
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (id string, transaction business.TransactionResponse) {
+		t.Helper()
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("Не удалось прочитать событие: %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &transaction); err != nil {
+					t.Fatalf("Неверные данные события: %v", err)
+				}
+			case line == "" && id != "":
+				return id, transaction
+			}
+		}
+	}
+	firstID, transaction := readEvent()
+	if transaction.UUID != first.UUID {
+		t.Errorf("Получена транзакция %s, ожидалась %s", transaction.UUID, first.UUID)
+	}
+
+	// Переподключение после первого события
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/transactions/stream", nil)
+	req.Header.Set(LastEventIDHeader, firstID)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Запрос не выполнен: %v", err)
+	}
+	defer resp.Body.Close()
+	reader = bufio.NewReader(resp.Body)
+	if _, transaction := readEvent(); transaction.UUID != second.UUID {
+		t.Errorf("После переподключения получена транзакция %s, ожидалась %s", transaction.UUID, second.UUID)
+	}
+}
+
+// TestStreamTransactionsWSHandler проверяет поток транзакций через WebSocket
+// и его возобновление по параметру last_event_id.
+func TestStreamTransactionsWSHandler(t *testing.T) {
+	server, service := newStreamServer(t)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/transactions/stream/ws"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Не удалось подключиться: %v", err)
+	}
+	defer conn.Close()
+	first := sendTestTransfer(t, service)
+	second := sendTestTransfer(t, service)
+
+	var message StreamMessage
+	if err := conn.ReadJSON(&message); err != nil || message.Transaction.UUID != first.UUID {
+		t.Fatalf("Получено сообщение %+v, ошибка %v, ожидалась транзакция %s", message, err, first.UUID)
+	}
+
+	resumed, _, err := websocket.DefaultDialer.Dial(url+"?last_event_id="+message.ID, nil)
+	if err != nil {
+		t.Fatalf("Не удалось переподключиться: %v", err)
+	}
+	defer resumed.Close()
+	if err := resumed.ReadJSON(&message); err != nil || message.Transaction.UUID != second.UUID {
+		t.Errorf("После переподключения получено %+v, ошибка %v, ожидалась транзакция %s", message, err, second.UUID)
+	}
+
+	if _, resp, err := websocket.DefaultDialer.Dial(url+"?last_event_id=x", nil); err == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Ожидался отказ с 400 для некорректного last_event_id, ошибка %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+)
+
+// WalletStatusRequest представляет тело запроса для POST /api/admin/wallets/{address}/freeze
+// и POST /api/admin/wallets/{address}/unfreeze.
+//
+// Исполнителем изменения записывается администратор, проверенный AdminAuth.
+type WalletStatusRequest struct {
+	Reason        string `json:"reason" binding:"required"` // причина изменения состояния
+	BlockIncoming bool   `json:"block_incoming"`            // при заморозке: блокировать и входящие переводы
+}
+
+// FreezeWalletHandler обрабатывает POST /api/admin/wallets/{address}/freeze.
+//
+// Замораживает кошелёк: он перестаёт отправлять переводы,
+// а при block_incoming — и получать их. Доступен только администраторам.
+// Возвращает:
+// - 200 OK со сведениями о кошельке
+// - 400 Bad Request, если не указана причина
+// - 404 Not Found, если кошелек не найден или закрыт
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) FreezeWalletHandler(c *gin.Context) {
+	var req WalletStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+
+	wallet, err := h.service.FreezeWallet(c.Param("address"), business.WalletStatusRequest{
+		Reason:        req.Reason,
+		Actor:         adminPrincipal(c),
+		BlockIncoming: req.BlockIncoming,
+	})
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось заморозить кошелек")
+		return
+	}
+	c.JSON(http.StatusOK, wallet)
+}
+
+// UnfreezeWalletHandler обрабатывает POST /api/admin/wallets/{address}/unfreeze.
+//
+// Снимает заморозку с кошелька; поле block_incoming не используется.
+// Доступен только администраторам.
+// Возвращает:
+// - 200 OK со сведениями о кошельке
+// - 400 Bad Request, если не указана причина
+// - 404 Not Found, если кошелек не найден или закрыт
+// - 409 Conflict, если кошелек не заморожен
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) UnfreezeWalletHandler(c *gin.Context) {
+	var req WalletStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+
+	wallet, err := h.service.UnfreezeWallet(c.Param("address"), business.WalletStatusRequest{
+		Reason: req.Reason,
+		Actor:  adminPrincipal(c),
+	})
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось разморозить кошелек")
+		return
+	}
+	c.JSON(http.StatusOK, wallet)
+}
+
+// GetWalletStatusHistoryHandler обрабатывает GET /api/admin/wallets/{address}/status-history.
+//
+// Возвращает историю изменений состояния кошелька, в том числе закрытого.
+// Доступен только администраторам.
+// Если кошелек не найден — 404 Not Found.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) GetWalletStatusHistoryHandler(c *gin.Context) {
+	history, err := h.service.GetWalletStatusHistory(c.Param("address"))
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить историю состояний кошелька")
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
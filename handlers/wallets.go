@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+	"payment_system_api/money"
+)
+
+// CreateWalletRequest представляет тело запроса для POST /api/wallets.
+type CreateWalletRequest struct {
+	Currency money.Currency `json:"currency"` // валюта кошелька ISO 4217, по умолчанию RUB
+}
+
+// CreateWalletHandler обрабатывает POST /api/wallets.
+//
+// Открывает новый кошелёк с нулевым балансом в указанной валюте.
+// Тело запроса необязательно.
+// Возвращает:
+// - 201 Created со сведениями о кошельке
+// - 400 Bad Request, если тело запроса неверное
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) CreateWalletHandler(c *gin.Context) {
+	var req CreateWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+
+	wallet, err := h.service.CreateWallet(req.Currency)
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось создать кошелек")
+		return
+	}
+	c.JSON(http.StatusCreated, wallet)
+}
+
+// GetWalletHandler обрабатывает GET /api/wallets/{address}.
+//
+// Возвращает полные сведения о кошельке.
+// Если кошелек не найден или закрыт — 404 Not Found.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) GetWalletHandler(c *gin.Context) {
+	wallet, err := h.service.GetWallet(c.Param("address"))
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить кошелек")
+		return
+	}
+	c.JSON(http.StatusOK, wallet)
+}
+
+// ListWalletsHandler обрабатывает GET /api/wallets?page=N&page_size=M.
+//
+// Возвращает страницу списка открытых кошельков. По умолчанию page=1, page_size=20,
+// page_size не может превышать business.MaxWalletPageSize.
+// Если параметры некорректны — 400 Bad Request.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) ListWalletsHandler(c *gin.Context) {
+	page, pageErr := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, sizeErr := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if pageErr != nil || sizeErr != nil || page <= 0 || pageSize <= 0 || pageSize > business.MaxWalletPageSize {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверные параметры 'page' или 'page_size'", "")
+		return
+	}
+
+	wallets, err := h.service.ListWallets(page, pageSize)
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить список кошельков")
+		return
+	}
+	c.JSON(http.StatusOK, wallets)
+}
+
+// CloseWalletRequest представляет необязательное тело запроса для DELETE /api/wallets/{address}.
+type CloseWalletRequest struct {
+	Reason string `json:"reason"` // причина закрытия
+}
+
+// CloseWalletHandler обрабатывает DELETE /api/wallets/{address}.
+//
+// Закрывает кошелёк с нулевым балансом. Причина из тела запроса сохраняется
+// в истории состояний кошелька; исполнитель не записывается, так как эндпоинт
+// не требует аутентификации.
+// Возвращает:
+// - 200 OK при успешном закрытии
+// - 400 Bad Request, если тело запроса неверное
+// - 404 Not Found, если кошелек не найден или уже закрыт
+// - 409 Conflict, если на кошельке есть средства
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) CloseWalletHandler(c *gin.Context) {
+	var req CloseWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+
+	err := h.service.CloseWallet(c.Param("address"), business.WalletStatusRequest{Reason: req.Reason})
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось закрыть кошелек")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"сообщение": "Кошелек закрыт"})
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+	"payment_system_api/database"
+)
+
+// CreateWebhookRequest представляет тело запроса для POST /api/admin/webhooks.
+type CreateWebhookRequest struct {
+	URL    string `json:"url" binding:"required"` // адрес получателя http или https
+	Wallet string `json:"wallet"`                 // кошелёк подписки, пусто — все кошельки
+}
+
+// CreateWebhookHandler обрабатывает POST /api/admin/webhooks.
+//
+// Регистрирует вебхук, получающий события о списаниях и зачислениях
+// кошелька Wallet или всех кошельков. Ключ подписи запросов возвращается
+// только в этом ответе.
+// Возвращает:
+// - 201 Created со сведениями о вебхуке и ключом подписи
+// - 400 Bad Request, если тело запроса или адрес вебхука неверные
+// - 404 Not Found, если кошелек не найден
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) CreateWebhookHandler(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверное тело запроса", err.Error())
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(req.URL, req.Wallet)
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось зарегистрировать вебхук")
+		return
+	}
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// ListWebhooksHandler обрабатывает GET /api/admin/webhooks.
+//
+// Возвращает все вебхуки без ключей подписи.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) ListWebhooksHandler(c *gin.Context) {
+	webhooks, err := h.service.ListWebhooks()
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить список вебхуков")
+		return
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhookHandler обрабатывает DELETE /api/admin/webhooks/{id}.
+//
+// Удаляет вебхук вместе с его доставками.
+// Возвращает:
+// - 200 OK при успешном удалении
+// - 400 Bad Request, если идентификатор некорректен
+// - 404 Not Found, если вебхук не найден
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) DeleteWebhookHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteWebhook(id); err != nil {
+		writeBusinessError(c, err, "Не удалось удалить вебхук")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"сообщение": "Вебхук удален"})
+}
+
+// ListWebhookDeliveriesHandler обрабатывает
+// GET /api/admin/webhooks/deliveries?webhook_id=ID&status=S&page=N&page_size=M.
+//
+// Возвращает страницу списка доставок вебхуков от новых к старым.
+// Необязательные параметры webhook_id и status (pending, delivered или dead)
+// ограничивают выборку. По умолчанию page=1, page_size=20,
+// page_size не может превышать business.MaxDeliveryPageSize.
+// Если параметры некорректны — 400 Bad Request.
+// При внутренних ошибках — 500 Internal Server Error.
+func (h *Handler) ListWebhookDeliveriesHandler(c *gin.Context) {
+	page, pageErr := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, sizeErr := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if pageErr != nil || sizeErr != nil || page <= 0 || pageSize <= 0 || pageSize > business.MaxDeliveryPageSize {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверные параметры 'page' или 'page_size'", "")
+		return
+	}
+
+	var filter business.DeliveryFilter
+	if value := c.Query("webhook_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || id == 0 {
+			writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверный параметр 'webhook_id'", "")
+			return
+		}
+		filter.WebhookID = uint(id)
+	}
+	switch status := c.Query("status"); status {
+	case "", database.DeliveryPending, database.DeliveryDelivered, database.DeliveryDead:
+		filter.Status = status
+	default:
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверный параметр 'status'", "")
+		return
+	}
+
+	deliveries, err := h.service.ListWebhookDeliveries(filter, page, pageSize)
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось получить список доставок вебхуков")
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// ReplayWebhookDeliveryHandler обрабатывает POST /api/admin/webhooks/deliveries/{id}/replay.
+//
+// Ставит доставку, в том числе выполненную или мёртвую, в очередь
+// на немедленную повторную отправку.
+// Возвращает:
+// - 200 OK с обновлённой доставкой
+// - 400 Bad Request, если идентификатор некорректен
+// - 404 Not Found, если доставка не найдена
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) ReplayWebhookDeliveryHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	delivery, err := h.service.ReplayWebhookDelivery(id)
+	if err != nil {
+		writeBusinessError(c, err, "Не удалось повторить доставку вебхука")
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// DeleteWebhookDeliveryHandler обрабатывает DELETE /api/admin/webhooks/deliveries/{id}.
+//
+// Удаляет доставку; невыполненная доставка больше не отправляется.
+// Возвращает:
+// - 200 OK при успешном удалении
+// - 400 Bad Request, если идентификатор некорректен
+// - 404 Not Found, если доставка не найдена
+// - 500 Internal Server Error при других ошибках
+func (h *Handler) DeleteWebhookDeliveryHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteWebhookDelivery(id); err != nil {
+		writeBusinessError(c, err, "Не удалось удалить доставку вебхука")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"сообщение": "Доставка вебхука удалена"})
+}
+
+// parseID считывает положительный идентификатор из параметра пути id.
+//
+// Если он некорректен, отправляет 400 Bad Request и возвращает false.
+func parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		writeError(c, http.StatusBadRequest, CodeInvalidRequest, "Неверный идентификатор", "")
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -1,50 +1,295 @@
 // Package main запускает API сервера платёжной системы.
 // Он настраивает маршруты, подключается к базе данных, выполняет миграции
 // и запускает HTTP-сервер на Gin.
+//
+// Подкоманда migrate управляет миграциями базы данных без запуска сервера:
+//
+//	payment_api migrate up | down [N] | status
 package main
 
 import (
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"payment_system_api/business"
 	"payment_system_api/config"
 	"payment_system_api/database"
+	"payment_system_api/events"
+	"payment_system_api/fx"
 	"payment_system_api/handlers"
+	"payment_system_api/memory"
 )
 
 // main инициализирует сервер приложения.
 //
 // Она выполняет следующие шаги:
 // 1. Загружает конфигурацию.
-// 2. Подключается к базе данных.
-// 3. Выполняет миграции и начальную настройку данных.
-// 4. Настраивает маршруты API.
-// 5. Запускает HTTP-сервер на порту 8080.
+// 2. Открывает хранилище данных: базу данных или хранилище в памяти.
+// 3. Выполняет начальную настройку данных.
+// 4. Запускает фоновые задачи.
+// 5. Настраивает маршруты API.
+// 6. Запускает HTTP-сервер на порту 8080.
 func main() {
 
 	// Загрузка конфигурации
 	cfg := config.LoadConfig()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
+	}
 
-	// Подключение к базе данных
-	database.ConnectDB(cfg.DatabaseURL)
+	// Открытие хранилища и создание начальных данных
+	store := openStore(cfg)
+	database.InitialSetup(store)
+
+	// Настройки бизнес-логики и источник курсов для переводов с конвертацией
+	options := business.Options{
+		FXQuoteTTL:              cfg.FXQuoteTTL,
+		IdempotencyKeyRetention: cfg.IdempotencyKeyTTL,
+		HoldTTL:                 cfg.HoldTTL,
+		WebhookMaxAttempts:      cfg.WebhookMaxAttempts,
+		WebhookRetryBackoff:     cfg.WebhookRetryBackoff,
+
+		StandingOrderMaxRetries:    &cfg.StandingOrderMaxRetries,
+		StandingOrderRetryInterval: cfg.StandingOrderRetryInterval,
+
+		StreamPollInterval: cfg.StreamPollInterval,
+	}
+	if cfg.FXRatesFile != "" {
+		provider, err := fx.LoadStaticProvider(cfg.FXRatesFile)
+		if err != nil {
+			log.Fatalf("Не удалось загрузить курсы валют: %v", err)
+		}
+		options.RateProvider = provider
+	}
+	service := business.NewService(store, options)
+
+	if err := service.BackfillLedger(); err != nil {
+		log.Fatalf("Не удалось перенести кошельки в главную книгу: %v", err)
+	}
+
+	// Периодическая очистка истёкших ключей идемпотентности
+	go purgeIdempotencyKeys(service, time.Hour)
+
+	// Публикация событий из исходящей очереди; без получателя события копятся в очереди
+	if cfg.EventsPublisher != "" {
+		publisher, err := openPublisher(cfg.EventsPublisher)
+		if err != nil {
+			log.Fatalf("Не удалось открыть получателя событий: %v", err)
+		}
+		go relayEvents(service, publisher, cfg.EventsRelayInterval)
+	}
+
+	// Освобождение истёкших резервирований средств
+	go expireHolds(service, time.Minute)
 
-	// Выполнение миграции и создание начальных данных
+	// Отправка вебхуков о списаниях и зачислениях
+	go deliverWebhooks(service, time.Second)
+
+	// Выполнение запланированных переводов, время которых наступило
+	go executeScheduledTransfers(service, cfg.SchedulerInterval)
+
+	// Выполнение регулярных платежей по расписанию
+	go executeStandingOrders(service, cfg.SchedulerInterval)
+
+	// Настройка Gin и маршрутов
+	if len(cfg.AdminTokens) == 0 {
+		log.Println("ADMIN_TOKENS не задана: административные эндпоинты отклоняют все запросы")
+	}
+	router := newRouter(handlers.NewHandler(service), handlers.AdminAuth(cfg.AdminTokens))
+	log.Println("Старт сервера на порту 8080")
+	if err := router.Run(":8080"); err != nil {
+		log.Fatalf("Не удалось запустить сервер: %v", err)
+	}
+}
+
+// openStore открывает хранилище данных, выбранное в конфигурации.
+//
+// Для базы данных подключается к ней и применяет неприменённые миграции.
+func openStore(cfg *config.Config) database.Store {
+	if cfg.Storage == config.StorageMemory {
+		log.Println("Используется хранилище в памяти: данные не сохраняются после остановки сервера")
+		return memory.New()
+	}
+	database.ConnectDB(cfg.DatabaseURL)
 	database.Migrate()
-	database.InitialSetup()
+	return database.NewStore(database.DB)
+}
+
+// openPublisher возвращает получателя событий по описанию spec:
+// "stdout" — стандартный вывод, "file:<путь>" — дозапись в файл.
+func openPublisher(spec string) (events.Publisher, error) {
+	if path, ok := strings.CutPrefix(spec, "file:"); ok {
+		return events.OpenFilePublisher(path)
+	}
+	return events.NewWriterPublisher(os.Stdout), nil
+}
 
-	// Настройка Gin
+// newRouter настраивает маршруты API с обработчиками h.
+//
+// Административные эндпоинты (/api/admin) доступны только через adminAuth.
+func newRouter(h *handlers.Handler, adminAuth gin.HandlerFunc) *gin.Engine {
 	router := gin.Default()
 
 	// Группировка маршрутов
 	apiRoutes := router.Group("/api")
 	{
-		apiRoutes.POST("/send", handlers.SendHandler)
-		apiRoutes.GET("/wallet/:address/balance", handlers.GetBalanceHandler)
-		apiRoutes.GET("/transactions", handlers.GetLastTransactionsHandler)
+		apiRoutes.POST("/send", h.SendHandler)
+		apiRoutes.POST("/send/batch", h.SendBatchHandler)
+		apiRoutes.GET("/wallet/:address/balance", h.GetBalanceHandler)
+		apiRoutes.GET("/wallet/:address/transactions", h.GetWalletTransactionsHandler)
+		apiRoutes.POST("/wallets", h.CreateWalletHandler)
+		apiRoutes.GET("/wallets", h.ListWalletsHandler)
+		apiRoutes.GET("/wallets/:address", h.GetWalletHandler)
+		apiRoutes.DELETE("/wallets/:address", h.CloseWalletHandler)
+		apiRoutes.GET("/transactions", h.GetLastTransactionsHandler)
+		apiRoutes.GET("/transactions/stream", h.StreamTransactionsHandler)
+		apiRoutes.GET("/transactions/stream/ws", h.StreamTransactionsWSHandler)
+		apiRoutes.POST("/transactions/:uuid/refund", h.RefundTransactionHandler)
+		apiRoutes.GET("/fx/quote", h.GetQuoteHandler)
+		apiRoutes.GET("/ledger/verify", h.VerifyLedgerHandler)
+		apiRoutes.POST("/holds", h.CreateHoldHandler)
+		apiRoutes.GET("/holds/:id", h.GetHoldHandler)
+		apiRoutes.POST("/holds/:id/capture", h.CaptureHoldHandler)
+		apiRoutes.POST("/holds/:id/void", h.VoidHoldHandler)
+		apiRoutes.POST("/scheduled-transfers", h.ScheduleTransferHandler)
+		apiRoutes.GET("/scheduled-transfers/:id", h.GetScheduledTransferHandler)
+		apiRoutes.POST("/scheduled-transfers/:id/cancel", h.CancelScheduledTransferHandler)
+		apiRoutes.POST("/standing-orders", h.CreateStandingOrderHandler)
+		apiRoutes.GET("/standing-orders/:id", h.GetStandingOrderHandler)
+		apiRoutes.GET("/standing-orders/:id/runs", h.GetStandingOrderRunsHandler)
+		apiRoutes.POST("/standing-orders/:id/cancel", h.CancelStandingOrderHandler)
 	}
-	log.Println("Старт сервера на порту 8080")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatalf("Не удалось запустить сервер: %v", err)
+
+	// Административные маршруты
+	adminRoutes := router.Group("/api/admin", adminAuth)
+	{
+		adminRoutes.GET("/wallets/:address/limits", h.GetWalletLimitsHandler)
+		adminRoutes.PUT("/wallets/:address/limits", h.SetWalletLimitsHandler)
+		adminRoutes.DELETE("/wallets/:address/limits", h.DeleteWalletLimitsHandler)
+		adminRoutes.POST("/wallets/:address/freeze", h.FreezeWalletHandler)
+		adminRoutes.POST("/wallets/:address/unfreeze", h.UnfreezeWalletHandler)
+		adminRoutes.GET("/wallets/:address/status-history", h.GetWalletStatusHistoryHandler)
+		adminRoutes.POST("/webhooks", h.CreateWebhookHandler)
+		adminRoutes.GET("/webhooks", h.ListWebhooksHandler)
+		adminRoutes.DELETE("/webhooks/:id", h.DeleteWebhookHandler)
+		adminRoutes.GET("/webhooks/deliveries", h.ListWebhookDeliveriesHandler)
+		adminRoutes.POST("/webhooks/deliveries/:id/replay", h.ReplayWebhookDeliveryHandler)
+		adminRoutes.DELETE("/webhooks/deliveries/:id", h.DeleteWebhookDeliveryHandler)
+	}
+	return router
+}
+
+// purgeIdempotencyKeys раз в interval освобождает истёкшие ключи идемпотентности.
+func purgeIdempotencyKeys(service *business.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		purged, err := service.PurgeExpiredIdempotencyKeys()
+		if err != nil {
+			log.Printf("Не удалось очистить ключи идемпотентности: %v", err)
+			continue
+		}
+		if purged > 0 {
+			log.Printf("Освобождено ключей идемпотентности: %d", purged)
+		}
+	}
+}
+
+// eventBatchSize — максимальное число событий, публикуемых в одной транзакции.
+const eventBatchSize = 100
+
+// relayEvents раз в interval публикует через publisher накопившиеся события исходящей очереди.
+func relayEvents(service *business.Service, publisher events.Publisher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for {
+			published, err := service.PublishEvents(publisher, eventBatchSize)
+			if err != nil {
+				log.Printf("Не удалось опубликовать события: %v", err)
+			}
+			if err != nil || published < eventBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// holdBatchSize — максимальное число резервирований, освобождаемых в одной транзакции.
+const holdBatchSize = 100
+
+// expireHolds раз в interval освобождает остатки истёкших резервирований средств.
+func expireHolds(service *business.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for {
+			expired, err := service.ExpireHolds(holdBatchSize)
+			if err != nil {
+				log.Printf("Не удалось освободить истёкшие резервирования: %v", err)
+			}
+			if err != nil || expired < holdBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// webhookBatchSize — максимальное число доставок вебхуков, выбираемых за раз.
+const webhookBatchSize = 100
+
+// deliverWebhooks раз в interval отправляет доставки вебхуков, время попытки которых наступило.
+func deliverWebhooks(service *business.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := service.DeliverWebhooks(webhookBatchSize); err != nil {
+			log.Printf("Не удалось отправить вебхуки: %v", err)
+		}
+	}
+}
+
+// scheduledBatchSize — максимальное число запланированных переводов
+// или попыток регулярных платежей, выполняемых за раз.
+const scheduledBatchSize = 100
+
+// executeScheduledTransfers раз в interval выполняет запланированные переводы,
+// время которых наступило.
+func executeScheduledTransfers(service *business.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for {
+			executed, err := service.ExecuteScheduledTransfers(scheduledBatchSize)
+			if err != nil {
+				log.Printf("Не удалось выполнить запланированные переводы: %v", err)
+			}
+			if err != nil || executed < scheduledBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// executeStandingOrders раз в interval выполняет попытки регулярных платежей,
+// время которых наступило.
+func executeStandingOrders(service *business.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for {
+			executed, err := service.ExecuteStandingOrders(scheduledBatchSize)
+			if err != nil {
+				log.Printf("Не удалось выполнить регулярные платежи: %v", err)
+			}
+			if err != nil || executed < scheduledBatchSize {
+				break
+			}
+		}
 	}
 }
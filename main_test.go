@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"payment_system_api/business"
+	"payment_system_api/database"
+	"payment_system_api/handlers"
+	"payment_system_api/memory"
+)
+
+// TestAPIWithMemoryStorage проверяет работу API над хранилищем в памяти
+// без внешних сервисов: перевод между начальными кошельками, повтор запроса
+// с ключом идемпотентности, отказ при нехватке средств, сверку главной книги
+// и доступ к административным эндпоинтам, включая вебхуки, только с токеном.
+func TestAPIWithMemoryStorage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.New()
+	database.InitialSetup(store)
+	service := business.NewService(store, business.Options{})
+	if err := service.BackfillLedger(); err != nil {
+		t.Fatalf("Не удалось перенести кошельки в главную книгу: %v", err)
+	}
+	router := newRouter(handlers.NewHandler(service), handlers.AdminAuth(map[string]string{"main-admin-token": "operator"}))
+
+	request := func(method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	var page business.WalletPage
+	recorder := request("GET", "/api/wallets?page_size=2", "", nil)
+	if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil || page.Total != 10 || len(page.Wallets) != 2 {
+		t.Fatalf("Ожидалось 10 начальных кошельков, получено %s", recorder.Body)
+	}
+	from, to := page.Wallets[0].Address, page.Wallets[1].Address
+
+	send := `{"from":"` + from + `","to":"` + to + `","amount":"12.34"}`
+	key := map[string]string{handlers.IdempotencyKeyHeader: "main-test"}
+	if recorder := request("POST", "/api/send", send, key); recorder.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	recorder = request("POST", "/api/send", send, key)
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Повтор должен вернуть исходную транзакцию, получен %d: %s", recorder.Code, recorder.Body)
+	}
+
+	overdraft := `{"from":"` + from + `","to":"` + to + `","amount":"1000"}`
+	if recorder := request("POST", "/api/send", overdraft, nil); recorder.Code != http.StatusPaymentRequired {
+		t.Errorf("Ожидался статус 402, получен %d: %s", recorder.Code, recorder.Body)
+	}
+
+	var balance business.BalanceResponse
+	recorder = request("GET", "/api/wallet/"+from+"/balance", "", nil)
+	if err := json.Unmarshal(recorder.Body.Bytes(), &balance); err != nil || balance.Balance != "87.66" {
+		t.Errorf("Ожидался баланс 87.66, получено %s", recorder.Body)
+	}
+
+	if recorder := request("GET", "/api/ledger/verify", "", nil); recorder.Code != http.StatusOK {
+		t.Errorf("Главная книга не сходится: %s", recorder.Body)
+	}
+
+	// Административные эндпоинты требуют токена администратора
+	limits := `{"max_transfer":"1"}`
+	if recorder := request("PUT", "/api/admin/wallets/"+from+"/limits", limits, nil); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Без токена ожидался статус 401, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := request("PUT", "/api/wallets/"+from+"/limits", limits, nil); recorder.Code != http.StatusNotFound {
+		t.Errorf("Лимиты доступны вне /api/admin: статус %d", recorder.Code)
+	}
+	admin := map[string]string{"Authorization": "Bearer main-admin-token"}
+	if recorder := request("PUT", "/api/admin/wallets/"+from+"/limits", limits, admin); recorder.Code != http.StatusOK {
+		t.Errorf("С токеном ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	freeze := `{"reason":"проверка"}`
+	if recorder := request("POST", "/api/admin/wallets/"+to+"/freeze", freeze, nil); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Заморозка без токена: ожидался статус 401, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := request("POST", "/api/admin/wallets/"+to+"/freeze", freeze, admin); recorder.Code != http.StatusOK {
+		t.Errorf("Заморозка с токеном: ожидался статус 200, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	webhook := `{"url":"https://example.com/hook"}`
+	if recorder := request("POST", "/api/webhooks", webhook, nil); recorder.Code != http.StatusNotFound {
+		t.Errorf("Вебхуки доступны вне /api/admin: статус %d", recorder.Code)
+	}
+	if recorder := request("GET", "/api/admin/webhooks/deliveries", "", nil); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Доставки без токена: ожидался статус 401, получен %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := request("POST", "/api/admin/webhooks", webhook, admin); recorder.Code != http.StatusCreated {
+		t.Errorf("Вебхук с токеном: ожидался статус 201, получен %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+// TestMigrateCommand проверяет подкоманду migrate над базой данных SQLite:
+// применение миграций, вывод их состояния, откат и отказ при неизвестной команде.
+func TestMigrateCommand(t *testing.T) {
+	database.ConnectDB("sqlite://" + filepath.Join(t.TempDir(), "migrate.db"))
+	db := database.DB
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		if err := migrateCommand(db, args, &out); err != nil {
+			t.Fatalf("migrate %s вернула ошибку: %v", strings.Join(args, " "), err)
+		}
+		return out.String()
+	}
+
+	statuses, err := database.MigrationStatuses(db)
+	if err != nil {
+		t.Fatalf("Не удалось получить состояние миграций: %v", err)
+	}
+	if out := run("up"); out != fmt.Sprintf("Применено миграций: %d\n", len(statuses)) {
+		t.Errorf("migrate up: неожиданный вывод %q", out)
+	}
+	if out := run("status"); strings.Contains(out, "не применена") || !strings.Contains(out, "0001_initial_schema") {
+		t.Errorf("migrate status после up: неожиданный вывод %q", out)
+	}
+	if out := run("down"); !strings.Contains(out, "Откачено миграций: 1") {
+		t.Errorf("migrate down: неожиданный вывод %q", out)
+	}
+	if out := run("status"); strings.Count(out, "не применена") != 1 {
+		t.Errorf("migrate status после down: неожиданный вывод %q", out)
+	}
+
+	for _, args := range [][]string{nil, {"sideways"}, {"down", "0"}, {"up", "1"}} {
+		if err := migrateCommand(db, args, io.Discard); err == nil {
+			t.Errorf("migrate %q: ожидалась ошибка", args)
+		}
+	}
+}
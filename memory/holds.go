@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// holdRepository — реализация database.HoldRepository в памяти.
+type holdRepository struct {
+	s *Store
+}
+
+// Create сохраняет новое резервирование.
+func (r holdRepository) Create(hold *database.Hold) error {
+	return r.s.write(func(d *state) error {
+		if hold.Amount <= 0 {
+			return database.ErrNonPositiveAmount
+		}
+		for _, address := range []string{hold.WalletAddress, hold.ToAddress} {
+			if _, ok := d.addresses[address]; !ok {
+				return database.ErrUnknownWallet
+			}
+		}
+		// Как и GORM, заданное время создания сохраняется
+		now := time.Now()
+		hold.ID = d.nextID()
+		hold.UUID = uuid.New().String()
+		if hold.CreatedAt.IsZero() {
+			hold.CreatedAt = now
+		}
+		hold.UpdatedAt = now
+		stored := *hold
+		d.holds[stored.ID] = &stored
+		d.holdUUIDs[stored.UUID] = stored.ID
+		r.s.onRollback(func() {
+			delete(d.holds, stored.ID)
+			delete(d.holdUUIDs, stored.UUID)
+		})
+		return nil
+	})
+}
+
+// FindByUUID возвращает резервирование по UUID.
+func (r holdRepository) FindByUUID(uuid string) (*database.Hold, error) {
+	var hold database.Hold
+	err := r.s.read(func(d *state) error {
+		id, ok := d.holdUUIDs[uuid]
+		if !ok {
+			return database.ErrNotFound
+		}
+		hold = *d.holds[id]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// LockByUUID возвращает резервирование по UUID.
+// Транзакции хранилища выполняются по очереди, поэтому отдельная блокировка не нужна.
+func (r holdRepository) LockByUUID(uuid string) (*database.Hold, error) {
+	return r.FindByUUID(uuid)
+}
+
+// LockExpired возвращает истёкшие активные резервирования в порядке возрастания идентификатора.
+func (r holdRepository) LockExpired(now time.Time, limit int) ([]database.Hold, error) {
+	var holds []database.Hold
+	err := r.s.read(func(d *state) error {
+		for _, hold := range d.holds {
+			if hold.Status == database.HoldActive && !hold.ExpiresAt.After(now) {
+				holds = append(holds, *hold)
+			}
+		}
+		return nil
+	})
+	sort.Slice(holds, func(i, j int) bool { return holds[i].ID < holds[j].ID })
+	return holds[:min(len(holds), limit)], err
+}
+
+// SumActive возвращает несписанный остаток активных резервирований кошелька address,
+// созданных не раньше since.
+func (r holdRepository) SumActive(address string, since time.Time) (money.Amount, error) {
+	var total money.Amount
+	err := r.s.read(func(d *state) error {
+		for _, hold := range d.holds {
+			if hold.WalletAddress == address && hold.Status == database.HoldActive && !hold.CreatedAt.Before(since) {
+				total += hold.Remaining()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// Update сохраняет списанную сумму и состояние резервирования.
+func (r holdRepository) Update(hold *database.Hold) error {
+	return r.s.write(func(d *state) error {
+		stored, ok := d.holds[hold.ID]
+		if !ok {
+			return nil
+		}
+		if hold.Captured < 0 || hold.Captured > stored.Amount {
+			return database.ErrNonPositiveAmount
+		}
+		previous := *stored
+		stored.Captured = hold.Captured
+		stored.Status = hold.Status
+		stored.UpdatedAt = time.Now()
+		hold.UpdatedAt = stored.UpdatedAt
+		r.s.onRollback(func() { *stored = previous })
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"time"
+
+	"payment_system_api/database"
+)
+
+// walletLimitRepository — реализация database.WalletLimitRepository в памяти.
+type walletLimitRepository struct {
+	s *Store
+}
+
+// Find возвращает лимиты кошелька address.
+func (r walletLimitRepository) Find(address string) (*database.WalletLimit, error) {
+	var limit database.WalletLimit
+	err := r.s.read(func(d *state) error {
+		stored, ok := d.limits[address]
+		if !ok {
+			return database.ErrNotFound
+		}
+		limit = *stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+// Save создаёт или заменяет лимиты кошелька.
+func (r walletLimitRepository) Save(limit *database.WalletLimit) error {
+	return r.s.write(func(d *state) error {
+		for _, amount := range []*int64{(*int64)(limit.MaxTransfer), (*int64)(limit.DailyTotal), (*int64)(limit.MonthlyTotal)} {
+			if amount != nil && *amount <= 0 {
+				return database.ErrNonPositiveAmount
+			}
+		}
+		if limit.HourlyCount != nil && *limit.HourlyCount <= 0 {
+			return database.ErrNonPositiveAmount
+		}
+		if _, ok := d.addresses[limit.WalletAddress]; !ok {
+			return database.ErrUnknownWallet
+		}
+
+		now := time.Now()
+		previous, existed := d.limits[limit.WalletAddress]
+		if existed {
+			limit.ID, limit.CreatedAt = previous.ID, previous.CreatedAt
+		} else {
+			limit.ID, limit.CreatedAt = d.nextID(), now
+		}
+		limit.UpdatedAt = now
+		stored := *limit
+		d.limits[stored.WalletAddress] = &stored
+		r.s.onRollback(func() {
+			if existed {
+				d.limits[stored.WalletAddress] = previous
+			} else {
+				delete(d.limits, stored.WalletAddress)
+			}
+		})
+		return nil
+	})
+}
+
+// Delete удаляет лимиты кошелька address.
+func (r walletLimitRepository) Delete(address string) error {
+	return r.s.write(func(d *state) error {
+		previous, ok := d.limits[address]
+		if !ok {
+			return nil
+		}
+		delete(d.limits, address)
+		r.s.onRollback(func() { d.limits[address] = previous })
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"payment_system_api/database"
+)
+
+// outboxRepository — реализация database.OutboxRepository в памяти.
+type outboxRepository struct {
+	s *Store
+}
+
+// Create сохраняет новое событие.
+func (r outboxRepository) Create(event *database.OutboxEvent) error {
+	return r.s.write(func(d *state) error {
+		event.ID = d.nextID()
+		event.CreatedAt = time.Now()
+		stored := *event
+		d.outbox = append(d.outbox, &stored)
+		r.s.onRollback(func() { d.outbox = d.outbox[:len(d.outbox)-1] })
+		return nil
+	})
+}
+
+// TryLock всегда успешен: транзакции хранилища выполняются по очереди.
+func (r outboxRepository) TryLock() (bool, error) {
+	return true, nil
+}
+
+// Pending возвращает неопубликованные события в порядке создания.
+func (r outboxRepository) Pending(limit int) ([]database.OutboxEvent, error) {
+	var events []database.OutboxEvent
+	err := r.s.read(func(d *state) error {
+		for _, event := range d.outbox {
+			if len(events) == limit {
+				break
+			}
+			if event.PublishedAt == nil {
+				events = append(events, *event)
+			}
+		}
+		return nil
+	})
+	return events, err
+}
+
+// MarkPublished отмечает событие опубликованным.
+func (r outboxRepository) MarkPublished(id uint, at time.Time) error {
+	return r.update(id, func(event *database.OutboxEvent) {
+		event.PublishedAt = &at
+	})
+}
+
+// MarkFailed сохраняет неудачную попытку публикации события.
+func (r outboxRepository) MarkFailed(id uint, reason string) error {
+	return r.update(id, func(event *database.OutboxEvent) {
+		event.Attempts++
+		event.LastError = reason
+	})
+}
+
+// update применяет change к событию id, если оно существует.
+func (r outboxRepository) update(id uint, change func(event *database.OutboxEvent)) error {
+	return r.s.write(func(d *state) error {
+		// События хранятся по возрастанию идентификаторов
+		i := sort.Search(len(d.outbox), func(i int) bool { return d.outbox[i].ID >= id })
+		if i == len(d.outbox) || d.outbox[i].ID != id {
+			return nil
+		}
+		event := d.outbox[i]
+		previous := *event
+		change(event)
+		r.s.onRollback(func() { *event = previous })
+		return nil
+	})
+}
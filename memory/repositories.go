@@ -0,0 +1,677 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// walletRepository — реализация database.WalletRepository в памяти.
+type walletRepository struct {
+	s *Store
+}
+
+// Create сохраняет новый кошелёк.
+func (r walletRepository) Create(wallet *database.Wallet) error {
+	return r.s.write(func(d *state) error {
+		if _, ok := d.addresses[wallet.Address]; ok {
+			return database.ErrDuplicate
+		}
+		if wallet.Balance < 0 {
+			return database.ErrNegativeBalance
+		}
+		now := time.Now()
+		wallet.ID = d.nextID()
+		wallet.CreatedAt, wallet.UpdatedAt = now, now
+		if wallet.Currency == "" {
+			wallet.Currency = money.DefaultCurrency
+		}
+		if wallet.Status == "" {
+			wallet.Status = database.WalletActive
+		}
+		stored := *wallet
+		d.wallets[stored.ID] = &stored
+		d.addresses[stored.Address] = stored.ID
+		r.s.onRollback(func() {
+			delete(d.wallets, stored.ID)
+			delete(d.addresses, stored.Address)
+		})
+		return nil
+	})
+}
+
+// FindByAddress возвращает открытый кошелёк по адресу.
+func (r walletRepository) FindByAddress(address string) (*database.Wallet, error) {
+	wallet, err := r.FindByAddressUnscoped(address)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.DeletedAt.Valid {
+		return nil, database.ErrNotFound
+	}
+	return wallet, nil
+}
+
+// FindByAddressUnscoped возвращает кошелёк по адресу, в том числе закрытый.
+func (r walletRepository) FindByAddressUnscoped(address string) (*database.Wallet, error) {
+	var wallet database.Wallet
+	err := r.s.read(func(d *state) error {
+		id, ok := d.addresses[address]
+		if !ok {
+			return database.ErrNotFound
+		}
+		wallet = *d.wallets[id]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// LockByAddress возвращает открытый кошелёк по адресу.
+// Транзакции хранилища выполняются по очереди, поэтому отдельная блокировка не нужна.
+func (r walletRepository) LockByAddress(address string) (*database.Wallet, error) {
+	return r.FindByAddress(address)
+}
+
+// List возвращает страницу открытых кошельков в порядке открытия.
+func (r walletRepository) List(offset, limit int) ([]database.Wallet, error) {
+	var wallets []database.Wallet
+	err := r.s.read(func(d *state) error {
+		for _, wallet := range d.openWallets() {
+			if offset > 0 {
+				offset--
+				continue
+			}
+			if len(wallets) == limit {
+				break
+			}
+			wallets = append(wallets, *wallet)
+		}
+		return nil
+	})
+	return wallets, err
+}
+
+// Count возвращает число открытых кошельков.
+func (r walletRepository) Count() (int64, error) {
+	var count int64
+	err := r.s.read(func(d *state) error {
+		count = int64(len(d.openWallets()))
+		return nil
+	})
+	return count, err
+}
+
+// CountUnscoped возвращает число кошельков, включая закрытые.
+func (r walletRepository) CountUnscoped() (int64, error) {
+	var count int64
+	err := r.s.read(func(d *state) error {
+		count = int64(len(d.wallets))
+		return nil
+	})
+	return count, err
+}
+
+// AddBalance изменяет баланс кошелька id на delta.
+func (r walletRepository) AddBalance(id uint, delta money.Amount) error {
+	return r.s.write(func(d *state) error {
+		wallet, ok := d.wallets[id]
+		if !ok {
+			return nil
+		}
+		if wallet.Balance+delta < 0 || wallet.Balance+delta < wallet.Held {
+			return database.ErrNegativeBalance
+		}
+		previous := *wallet
+		wallet.Balance += delta
+		wallet.UpdatedAt = time.Now()
+		r.s.onRollback(func() { *wallet = previous })
+		return nil
+	})
+}
+
+// AddHeld изменяет зарезервированную сумму кошелька id на delta.
+func (r walletRepository) AddHeld(id uint, delta money.Amount) error {
+	return r.s.write(func(d *state) error {
+		wallet, ok := d.wallets[id]
+		if !ok {
+			return nil
+		}
+		if wallet.Held+delta < 0 || wallet.Held+delta > wallet.Balance {
+			return database.ErrNegativeBalance
+		}
+		previous := *wallet
+		wallet.Held += delta
+		wallet.UpdatedAt = time.Now()
+		r.s.onRollback(func() { *wallet = previous })
+		return nil
+	})
+}
+
+// Close закрывает кошелёк, помечая его удалённым через DeletedAt.
+func (r walletRepository) Close(wallet *database.Wallet) error {
+	return r.s.write(func(d *state) error {
+		stored, ok := d.wallets[wallet.ID]
+		if !ok || stored.DeletedAt.Valid {
+			return nil
+		}
+		previous := *stored
+		stored.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		wallet.DeletedAt = stored.DeletedAt
+		r.s.onRollback(func() { *stored = previous })
+		return nil
+	})
+}
+
+// UpdateStatus сохраняет состояние кошелька и блокировку входящих переводов.
+func (r walletRepository) UpdateStatus(wallet *database.Wallet) error {
+	return r.s.write(func(d *state) error {
+		stored, ok := d.wallets[wallet.ID]
+		if !ok {
+			return nil
+		}
+		previous := *stored
+		stored.Status = wallet.Status
+		stored.BlockIncoming = wallet.BlockIncoming
+		stored.UpdatedAt = time.Now()
+		wallet.UpdatedAt = stored.UpdatedAt
+		r.s.onRollback(func() { *stored = previous })
+		return nil
+	})
+}
+
+// RecordStatusChange сохраняет запись об изменении состояния кошелька.
+func (r walletRepository) RecordStatusChange(change *database.WalletStatusChange) error {
+	return r.s.write(func(d *state) error {
+		change.ID = d.nextID()
+		change.CreatedAt = time.Now()
+		stored := *change
+		d.statusChanges = append(d.statusChanges, &stored)
+		r.s.onRollback(func() { d.statusChanges = d.statusChanges[:len(d.statusChanges)-1] })
+		return nil
+	})
+}
+
+// FindStatusChanges возвращает изменения состояния кошелька в порядке записи.
+func (r walletRepository) FindStatusChanges(address string) ([]database.WalletStatusChange, error) {
+	var changes []database.WalletStatusChange
+	err := r.s.read(func(d *state) error {
+		for _, change := range d.statusChanges {
+			if change.WalletAddress == address {
+				changes = append(changes, *change)
+			}
+		}
+		return nil
+	})
+	return changes, err
+}
+
+// openWallets возвращает открытые кошельки в порядке открытия.
+func (d *state) openWallets() []*database.Wallet {
+	wallets := make([]*database.Wallet, 0, len(d.wallets))
+	for _, wallet := range d.wallets {
+		if !wallet.DeletedAt.Valid {
+			wallets = append(wallets, wallet)
+		}
+	}
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].ID < wallets[j].ID })
+	return wallets
+}
+
+// transactionRepository — реализация database.TransactionRepository в памяти.
+type transactionRepository struct {
+	s *Store
+}
+
+// Create сохраняет новую транзакцию.
+func (r transactionRepository) Create(transaction *database.Transaction) error {
+	return r.s.write(func(d *state) error {
+		if transaction.IdempotencyKey != nil {
+			if _, ok := d.idempotency[*transaction.IdempotencyKey]; ok {
+				return database.ErrDuplicate
+			}
+		}
+		if transaction.Amount <= 0 || transaction.ToAmount <= 0 {
+			return database.ErrNonPositiveAmount
+		}
+		for _, address := range []string{transaction.FromAddress, transaction.ToAddress} {
+			if _, ok := d.addresses[address]; !ok {
+				return database.ErrUnknownWallet
+			}
+		}
+		transaction.ID = d.nextID()
+		transaction.UUID = uuid.New().String()
+		transaction.Timestamp = time.Now()
+		stored := *transaction
+		d.transactions = append(d.transactions, &stored)
+		if stored.IdempotencyKey != nil {
+			d.idempotency[*stored.IdempotencyKey] = stored.ID
+		}
+		r.s.onRollback(func() {
+			d.transactions = d.transactions[:len(d.transactions)-1]
+			if stored.IdempotencyKey != nil {
+				delete(d.idempotency, *stored.IdempotencyKey)
+			}
+		})
+		return nil
+	})
+}
+
+// FindByIdempotencyKey возвращает транзакцию с ключом идемпотентности key.
+func (r transactionRepository) FindByIdempotencyKey(key string) (*database.Transaction, error) {
+	var transaction database.Transaction
+	err := r.s.read(func(d *state) error {
+		id, ok := d.idempotency[key]
+		if !ok {
+			return database.ErrNotFound
+		}
+		transaction = *d.transaction(id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// FindByUUID возвращает транзакцию по UUID.
+func (r transactionRepository) FindByUUID(uuid string) (*database.Transaction, error) {
+	var transaction database.Transaction
+	err := r.s.read(func(d *state) error {
+		stored := d.transactionByUUID(uuid)
+		if stored == nil {
+			return database.ErrNotFound
+		}
+		transaction = *stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// LockByUUID возвращает транзакцию по UUID.
+// Транзакции хранилища выполняются по очереди, поэтому отдельная блокировка не нужна.
+func (r transactionRepository) LockByUUID(uuid string) (*database.Transaction, error) {
+	return r.FindByUUID(uuid)
+}
+
+// AddRefunded изменяет сумму возвратов транзакции id на delta.
+func (r transactionRepository) AddRefunded(id uint, delta money.Amount) error {
+	return r.s.write(func(d *state) error {
+		transaction := d.transaction(id)
+		if transaction == nil {
+			return nil
+		}
+		if transaction.Refunded+delta < 0 || transaction.Refunded+delta > transaction.Amount {
+			return database.ErrRefundExceedsAmount
+		}
+		previous := *transaction
+		transaction.Refunded += delta
+		r.s.onRollback(func() { *transaction = previous })
+		return nil
+	})
+}
+
+// FindRefunds возвращает возвраты транзакций с UUID из uuids.
+func (r transactionRepository) FindRefunds(uuids []string) ([]database.Transaction, error) {
+	originals := make(map[string]bool, len(uuids))
+	for _, uuid := range uuids {
+		originals[uuid] = true
+	}
+	var refunds []database.Transaction
+	err := r.s.read(func(d *state) error {
+		for _, transaction := range d.transactions {
+			if transaction.RefundOf != nil && originals[*transaction.RefundOf] {
+				refunds = append(refunds, *transaction)
+			}
+		}
+		return nil
+	})
+	return refunds, err
+}
+
+// SumOutgoing возвращает сумму и число переводов с кошелька address, созданных не раньше since.
+func (r transactionRepository) SumOutgoing(address string, since time.Time) (money.Amount, int, error) {
+	var (
+		total money.Amount
+		count int
+	)
+	err := r.s.read(func(d *state) error {
+		for _, transaction := range d.transactions {
+			if transaction.FromAddress == address && transaction.RefundOf == nil && !transaction.Timestamp.Before(since) {
+				total += transaction.Amount
+				count++
+			}
+		}
+		return nil
+	})
+	return total, count, err
+}
+
+// ReleaseIdempotencyKey очищает ключ идемпотентности транзакции id.
+func (r transactionRepository) ReleaseIdempotencyKey(id uint) error {
+	return r.s.write(func(d *state) error {
+		if transaction := d.transaction(id); transaction != nil {
+			r.release(d, transaction)
+		}
+		return nil
+	})
+}
+
+// ReleaseIdempotencyKeys очищает ключи идемпотентности транзакций, созданных не позже cutoff.
+func (r transactionRepository) ReleaseIdempotencyKeys(cutoff time.Time) (int64, error) {
+	var released int64
+	err := r.s.write(func(d *state) error {
+		for _, transaction := range d.transactions {
+			if transaction.IdempotencyKey != nil && !transaction.Timestamp.After(cutoff) {
+				r.release(d, transaction)
+				released++
+			}
+		}
+		return nil
+	})
+	return released, err
+}
+
+// release очищает ключ идемпотентности транзакции transaction.
+func (r transactionRepository) release(d *state, transaction *database.Transaction) {
+	if transaction.IdempotencyKey == nil {
+		return
+	}
+	previous := *transaction
+	delete(d.idempotency, *transaction.IdempotencyKey)
+	transaction.IdempotencyKey = nil
+	transaction.RequestHash = ""
+	r.s.onRollback(func() {
+		*transaction = previous
+		d.idempotency[*previous.IdempotencyKey] = previous.ID
+	})
+}
+
+// Find возвращает транзакции, выбранные query.
+func (r transactionRepository) Find(query database.TransactionQuery) ([]database.Transaction, error) {
+	var transactions []database.Transaction
+	err := r.s.read(func(d *state) error {
+		for _, transaction := range d.transactions {
+			if matches(transaction, query) {
+				transactions = append(transactions, *transaction)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		return after(transactions[i].Timestamp, transactions[i].ID, transactions[j].Timestamp, transactions[j].ID)
+	})
+	if query.Limit > 0 && len(transactions) > query.Limit {
+		transactions = transactions[:query.Limit]
+	}
+	return transactions, nil
+}
+
+// FindAfter возвращает транзакции с позицией больше after.
+//
+// Транзакции хранилища выполняются по очереди, поэтому позиция
+// определяется идентификатором, а XID всегда 0.
+func (r transactionRepository) FindAfter(after database.CommitPosition, address string, limit int) ([]database.Transaction, error) {
+	var transactions []database.Transaction
+	err := r.s.read(func(d *state) error {
+		i := sort.Search(len(d.transactions), func(i int) bool { return d.transactions[i].ID > after.ID })
+		for _, transaction := range d.transactions[i:] {
+			if len(transactions) == limit {
+				break
+			}
+			if address == "" || transaction.FromAddress == address || transaction.ToAddress == address {
+				transactions = append(transactions, *transaction)
+			}
+		}
+		return nil
+	})
+	return transactions, err
+}
+
+// LastCommitPosition возвращает позицию последней транзакции.
+func (r transactionRepository) LastCommitPosition() (database.CommitPosition, error) {
+	var position database.CommitPosition
+	err := r.s.read(func(d *state) error {
+		if len(d.transactions) > 0 {
+			position.ID = d.transactions[len(d.transactions)-1].ID
+		}
+		return nil
+	})
+	return position, err
+}
+
+// transaction возвращает транзакцию по идентификатору или nil.
+func (d *state) transaction(id uint) *database.Transaction {
+	// Идентификаторы растут в порядке создания транзакций
+	i := sort.Search(len(d.transactions), func(i int) bool { return d.transactions[i].ID >= id })
+	if i < len(d.transactions) && d.transactions[i].ID == id {
+		return d.transactions[i]
+	}
+	return nil
+}
+
+// transactionByUUID возвращает транзакцию по UUID или nil.
+func (d *state) transactionByUUID(uuid string) *database.Transaction {
+	for _, transaction := range d.transactions {
+		if transaction.UUID == uuid {
+			return transaction
+		}
+	}
+	return nil
+}
+
+// matches сообщает, удовлетворяет ли транзакция t условиям query.
+func matches(t *database.Transaction, query database.TransactionQuery) bool {
+	if query.Address != "" {
+		outgoing := query.Outgoing && t.FromAddress == query.Address && inRange(t.Amount, query)
+		incoming := query.Incoming && t.ToAddress == query.Address && inRange(t.ToAmount, query)
+		if !outgoing && !incoming {
+			return false
+		}
+	}
+	if query.Since != nil && t.Timestamp.Before(*query.Since) {
+		return false
+	}
+	if query.Until != nil && !t.Timestamp.Before(*query.Until) {
+		return false
+	}
+	if query.Before != nil && !after(query.Before.Timestamp, query.Before.ID, t.Timestamp, t.ID) {
+		return false
+	}
+	return true
+}
+
+// inRange сообщает, попадает ли сумма amount в границы суммы query.
+func inRange(amount money.Amount, query database.TransactionQuery) bool {
+	if query.MinAmount != nil && amount < *query.MinAmount {
+		return false
+	}
+	if query.MaxAmount != nil && amount > *query.MaxAmount {
+		return false
+	}
+	return true
+}
+
+// after сообщает, идёт ли позиция (t1, id1) позже позиции (t2, id2).
+func after(t1 time.Time, id1 uint, t2 time.Time, id2 uint) bool {
+	if !t1.Equal(t2) {
+		return t1.After(t2)
+	}
+	return id1 > id2
+}
+
+// ledgerRepository — реализация database.LedgerRepository в памяти.
+type ledgerRepository struct {
+	s *Store
+}
+
+// FindWalletAccount возвращает счёт кошелька walletID.
+func (r ledgerRepository) FindWalletAccount(walletID uint) (*database.Account, error) {
+	var account database.Account
+	err := r.s.read(func(d *state) error {
+		id, ok := d.walletAccounts[walletID]
+		if !ok {
+			return database.ErrNotFound
+		}
+		account = *d.accounts[id]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CreateAccount сохраняет новый счёт.
+func (r ledgerRepository) CreateAccount(account *database.Account) error {
+	return r.s.write(func(d *state) error {
+		return r.createAccount(d, account)
+	})
+}
+
+// createAccount сохраняет новый счёт, проверяя уникальность кода и кошелька.
+func (r ledgerRepository) createAccount(d *state, account *database.Account) error {
+	if _, ok := d.accountCodes[account.Code]; ok {
+		return database.ErrDuplicate
+	}
+	if account.WalletID != nil {
+		if _, ok := d.walletAccounts[*account.WalletID]; ok {
+			return database.ErrDuplicate
+		}
+	}
+	account.ID = d.nextID()
+	account.CreatedAt = time.Now()
+	stored := *account
+	d.accounts[stored.ID] = &stored
+	d.accountCodes[stored.Code] = stored.ID
+	if stored.WalletID != nil {
+		d.walletAccounts[*stored.WalletID] = stored.ID
+	}
+	r.s.onRollback(func() {
+		delete(d.accounts, stored.ID)
+		delete(d.accountCodes, stored.Code)
+		if stored.WalletID != nil {
+			delete(d.walletAccounts, *stored.WalletID)
+		}
+	})
+	return nil
+}
+
+// SystemAccount возвращает системный счёт вида kind в валюте currency, открывая его при необходимости.
+func (r ledgerRepository) SystemAccount(kind string, currency money.Currency) (*database.Account, error) {
+	account := database.Account{Code: kind + ":" + string(currency), Kind: kind, Currency: currency}
+	err := r.s.write(func(d *state) error {
+		if id, ok := d.accountCodes[account.Code]; ok {
+			account = *d.accounts[id]
+			return nil
+		}
+		return r.createAccount(d, &account)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CreateEntry сохраняет запись журнала вместе с её проводками.
+func (r ledgerRepository) CreateEntry(entry *database.JournalEntry, postings []database.Posting) error {
+	return r.s.write(func(d *state) error {
+		now := time.Now()
+		entry.ID = d.nextID()
+		entry.CreatedAt = now
+		storedEntry := *entry
+		d.entries = append(d.entries, &storedEntry)
+		for i := range postings {
+			postings[i].ID = d.nextID()
+			postings[i].JournalEntryID = entry.ID
+			postings[i].CreatedAt = now
+			stored := postings[i]
+			d.postings = append(d.postings, &stored)
+		}
+		r.s.onRollback(func() {
+			d.entries = d.entries[:len(d.entries)-1]
+			d.postings = d.postings[:len(d.postings)-len(postings)]
+		})
+		return nil
+	})
+}
+
+// WalletsWithoutAccount возвращает открытые кошельки, у которых ещё нет счёта.
+func (r ledgerRepository) WalletsWithoutAccount() ([]database.Wallet, error) {
+	var wallets []database.Wallet
+	err := r.s.read(func(d *state) error {
+		for _, wallet := range d.openWallets() {
+			if _, ok := d.walletAccounts[wallet.ID]; !ok {
+				wallets = append(wallets, *wallet)
+			}
+		}
+		return nil
+	})
+	return wallets, err
+}
+
+// BalanceMismatches возвращает открытые кошельки, баланс которых не совпадает с проводками.
+func (r ledgerRepository) BalanceMismatches() ([]database.BalanceMismatch, error) {
+	var mismatches []database.BalanceMismatch
+	err := r.s.read(func(d *state) error {
+		sums := make(map[uint]money.Amount)
+		for _, posting := range d.postings {
+			sums[posting.AccountID] += posting.Amount
+		}
+		for _, wallet := range d.openWallets() {
+			var ledger money.Amount
+			if id, ok := d.walletAccounts[wallet.ID]; ok {
+				ledger = sums[id]
+			}
+			if ledger != wallet.Balance {
+				mismatches = append(mismatches, database.BalanceMismatch{
+					Address:  wallet.Address,
+					Currency: wallet.Currency,
+					Balance:  wallet.Balance,
+					Ledger:   ledger,
+				})
+			}
+		}
+		return nil
+	})
+	return mismatches, err
+}
+
+// UnbalancedEntryIDs возвращает записи журнала с ненулевой суммой проводок в какой-либо валюте.
+func (r ledgerRepository) UnbalancedEntryIDs() ([]uint, error) {
+	var ids []uint
+	err := r.s.read(func(d *state) error {
+		type key struct {
+			entry    uint
+			currency money.Currency
+		}
+		sums := make(map[key]money.Amount)
+		for _, posting := range d.postings {
+			sums[key{posting.JournalEntryID, posting.Currency}] += posting.Amount
+		}
+		unbalanced := make(map[uint]bool)
+		for k, sum := range sums {
+			if sum != 0 && !unbalanced[k.entry] {
+				unbalanced[k.entry] = true
+				ids = append(ids, k.entry)
+			}
+		}
+		return nil
+	})
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, err
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"payment_system_api/database"
+)
+
+// scheduledTransferRepository — реализация database.ScheduledTransferRepository в памяти.
+type scheduledTransferRepository struct {
+	s *Store
+}
+
+// Create сохраняет новый запланированный перевод.
+func (r scheduledTransferRepository) Create(transfer *database.ScheduledTransfer) error {
+	return r.s.write(func(d *state) error {
+		if transfer.Amount <= 0 {
+			return database.ErrNonPositiveAmount
+		}
+		for _, address := range []string{transfer.FromAddress, transfer.ToAddress} {
+			if _, ok := d.addresses[address]; !ok {
+				return database.ErrUnknownWallet
+			}
+		}
+		now := time.Now()
+		transfer.ID = d.nextID()
+		transfer.UUID = uuid.New().String()
+		transfer.CreatedAt, transfer.UpdatedAt = now, now
+		stored := *transfer
+		d.scheduled[stored.ID] = &stored
+		d.scheduledUUIDs[stored.UUID] = stored.ID
+		r.s.onRollback(func() {
+			delete(d.scheduled, stored.ID)
+			delete(d.scheduledUUIDs, stored.UUID)
+		})
+		return nil
+	})
+}
+
+// FindByUUID возвращает запланированный перевод по UUID.
+func (r scheduledTransferRepository) FindByUUID(uuid string) (*database.ScheduledTransfer, error) {
+	var transfer database.ScheduledTransfer
+	err := r.s.read(func(d *state) error {
+		id, ok := d.scheduledUUIDs[uuid]
+		if !ok {
+			return database.ErrNotFound
+		}
+		transfer = *d.scheduled[id]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// LockByUUID возвращает запланированный перевод по UUID.
+// Транзакции хранилища выполняются по очереди, поэтому отдельная блокировка не нужна.
+func (r scheduledTransferRepository) LockByUUID(uuid string) (*database.ScheduledTransfer, error) {
+	return r.FindByUUID(uuid)
+}
+
+// LockDue возвращает наступившие ожидающие переводы в порядке числа прерванных
+// попыток и времени выполнения.
+func (r scheduledTransferRepository) LockDue(now time.Time, limit int) ([]database.ScheduledTransfer, error) {
+	var transfers []database.ScheduledTransfer
+	err := r.s.read(func(d *state) error {
+		for _, transfer := range d.scheduled {
+			if transfer.Status == database.ScheduledPending && !transfer.ExecuteAt.After(now) {
+				transfers = append(transfers, *transfer)
+			}
+		}
+		return nil
+	})
+	sort.Slice(transfers, func(i, j int) bool {
+		if transfers[i].Attempts != transfers[j].Attempts {
+			return transfers[i].Attempts < transfers[j].Attempts
+		}
+		if !transfers[i].ExecuteAt.Equal(transfers[j].ExecuteAt) {
+			return transfers[i].ExecuteAt.Before(transfers[j].ExecuteAt)
+		}
+		return transfers[i].ID < transfers[j].ID
+	})
+	return transfers[:min(len(transfers), limit)], err
+}
+
+// Update сохраняет состояние и результат выполнения перевода.
+func (r scheduledTransferRepository) Update(transfer *database.ScheduledTransfer) error {
+	return r.s.write(func(d *state) error {
+		stored, ok := d.scheduled[transfer.ID]
+		if !ok {
+			return nil
+		}
+		previous := *stored
+		stored.Status = transfer.Status
+		stored.TransactionUUID = transfer.TransactionUUID
+		stored.ErrorCode = transfer.ErrorCode
+		stored.Error = transfer.Error
+		stored.ExecutedAt = transfer.ExecutedAt
+		stored.Attempts = transfer.Attempts
+		stored.UpdatedAt = time.Now()
+		transfer.UpdatedAt = stored.UpdatedAt
+		r.s.onRollback(func() { *stored = previous })
+		return nil
+	})
+}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"payment_system_api/database"
+)
+
+// standingOrderRepository — реализация database.StandingOrderRepository в памяти.
+type standingOrderRepository struct {
+	s *Store
+}
+
+// Create сохраняет новый регулярный платёж.
+func (r standingOrderRepository) Create(order *database.StandingOrder) error {
+	return r.s.write(func(d *state) error {
+		if order.Amount <= 0 {
+			return database.ErrNonPositiveAmount
+		}
+		for _, address := range []string{order.FromAddress, order.ToAddress} {
+			if _, ok := d.addresses[address]; !ok {
+				return database.ErrUnknownWallet
+			}
+		}
+		now := time.Now()
+		order.ID = d.nextID()
+		order.UUID = uuid.New().String()
+		order.CreatedAt, order.UpdatedAt = now, now
+		stored := *order
+		d.standingOrders[stored.ID] = &stored
+		d.standingOrderUUIDs[stored.UUID] = stored.ID
+		r.s.onRollback(func() {
+			delete(d.standingOrders, stored.ID)
+			delete(d.standingOrderUUIDs, stored.UUID)
+		})
+		return nil
+	})
+}
+
+// FindByUUID возвращает регулярный платёж по UUID.
+func (r standingOrderRepository) FindByUUID(uuid string) (*database.StandingOrder, error) {
+	var order database.StandingOrder
+	err := r.s.read(func(d *state) error {
+		id, ok := d.standingOrderUUIDs[uuid]
+		if !ok {
+			return database.ErrNotFound
+		}
+		order = *d.standingOrders[id]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// LockByUUID возвращает регулярный платёж по UUID.
+// Транзакции хранилища выполняются по очереди, поэтому отдельная блокировка не нужна.
+func (r standingOrderRepository) LockByUUID(uuid string) (*database.StandingOrder, error) {
+	return r.FindByUUID(uuid)
+}
+
+// LockDue возвращает активные платежи, время попытки которых наступило, в порядке времени попытки.
+func (r standingOrderRepository) LockDue(now time.Time, limit int) ([]database.StandingOrder, error) {
+	var orders []database.StandingOrder
+	err := r.s.read(func(d *state) error {
+		for _, order := range d.standingOrders {
+			if order.Status == database.StandingOrderActive && !order.NextAttemptAt.After(now) {
+				orders = append(orders, *order)
+			}
+		}
+		return nil
+	})
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].NextAttemptAt.Equal(orders[j].NextAttemptAt) {
+			return orders[i].NextAttemptAt.Before(orders[j].NextAttemptAt)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders[:min(len(orders), limit)], err
+}
+
+// Update сохраняет состояние платежа и расписание следующей попытки.
+func (r standingOrderRepository) Update(order *database.StandingOrder) error {
+	return r.s.write(func(d *state) error {
+		stored, ok := d.standingOrders[order.ID]
+		if !ok {
+			return nil
+		}
+		previous := *stored
+		stored.Status = order.Status
+		stored.Runs = order.Runs
+		stored.Attempt = order.Attempt
+		stored.RunAt = order.RunAt
+		stored.NextAttemptAt = order.NextAttemptAt
+		stored.UpdatedAt = time.Now()
+		order.UpdatedAt = stored.UpdatedAt
+		r.s.onRollback(func() { *stored = previous })
+		return nil
+	})
+}
+
+// CreateRun сохраняет запись о попытке выполнения платежа.
+func (r standingOrderRepository) CreateRun(run *database.StandingOrderRun) error {
+	return r.s.write(func(d *state) error {
+		run.ID = d.nextID()
+		run.CreatedAt = time.Now()
+		stored := *run
+		d.standingOrderRuns = append(d.standingOrderRuns, &stored)
+		r.s.onRollback(func() { d.standingOrderRuns = d.standingOrderRuns[:len(d.standingOrderRuns)-1] })
+		return nil
+	})
+}
+
+// FindRuns возвращает историю попыток выполнения платежа в порядке создания.
+func (r standingOrderRepository) FindRuns(orderID uint) ([]database.StandingOrderRun, error) {
+	var runs []database.StandingOrderRun
+	err := r.s.read(func(d *state) error {
+		for _, run := range d.standingOrderRuns {
+			if run.StandingOrderID == orderID {
+				runs = append(runs, *run)
+			}
+		}
+		return nil
+	})
+	return runs, err
+}
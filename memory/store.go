@@ -0,0 +1,210 @@
+// Package memory содержит хранилище данных платёжной системы в памяти процесса.
+//
+// Хранилище реализует database.Store и служит рабочим хранилищем сервиса,
+// когда он запущен с STORAGE=memory, — например, для локальной разработки
+// без базы данных. Транзакции хранилища выполняются строго по очереди,
+// поэтому блокировка отдельных кошельков не требуется.
+//
+// Хранилище не сохраняет данные: они теряются при остановке процесса.
+// Оно также не разделяется между процессами, поэтому сервис с ним нельзя
+// запускать в нескольких экземплярах — каждый увидит только свои данные.
+package memory
+
+import (
+	"sync"
+
+	"payment_system_api/database"
+)
+
+// Store — хранилище в памяти, реализующее database.Store.
+type Store struct {
+	mu   *sync.RWMutex
+	data *state
+	tx   *txLog // журнал отката, nil вне транзакции
+}
+
+// state — данные хранилища.
+type state struct {
+	wallets   map[uint]*database.Wallet // кошельки по идентификатору
+	addresses map[string]uint           // идентификаторы кошельков по адресу
+
+	transactions []*database.Transaction // транзакции в порядке создания
+	idempotency  map[string]uint         // идентификаторы транзакций по ключу идемпотентности
+
+	accounts       map[uint]*database.Account // счета по идентификатору
+	accountCodes   map[string]uint            // идентификаторы счетов по коду
+	walletAccounts map[uint]uint              // идентификаторы счетов по идентификатору кошелька
+	entries        []*database.JournalEntry   // записи журнала в порядке создания
+	postings       []*database.Posting        // проводки в порядке создания
+
+	outbox []*database.OutboxEvent // события исходящей очереди в порядке создания
+
+	webhooks   map[uint]*database.Webhook         // вебхуки по идентификатору
+	deliveries map[uint]*database.WebhookDelivery // доставки вебхуков по идентификатору
+
+	holds     map[uint]*database.Hold // резервирования по идентификатору
+	holdUUIDs map[string]uint         // идентификаторы резервирований по UUID
+
+	scheduled      map[uint]*database.ScheduledTransfer // запланированные переводы по идентификатору
+	scheduledUUIDs map[string]uint                      // идентификаторы запланированных переводов по UUID
+
+	standingOrders     map[uint]*database.StandingOrder // регулярные платежи по идентификатору
+	standingOrderUUIDs map[string]uint                  // идентификаторы регулярных платежей по UUID
+	standingOrderRuns  []*database.StandingOrderRun     // попытки выполнения регулярных платежей в порядке создания
+
+	limits map[string]*database.WalletLimit // лимиты расходов по адресу кошелька
+
+	statusChanges []*database.WalletStatusChange // изменения состояния кошельков в порядке записи
+
+	lastID uint // последний выданный идентификатор записи
+}
+
+// txLog — журнал отката транзакции хранилища.
+type txLog struct {
+	undo []func()
+}
+
+// New возвращает пустое хранилище.
+func New() *Store {
+	return &Store{
+		mu: &sync.RWMutex{},
+		data: &state{
+			wallets:        make(map[uint]*database.Wallet),
+			addresses:      make(map[string]uint),
+			idempotency:    make(map[string]uint),
+			accounts:       make(map[uint]*database.Account),
+			accountCodes:   make(map[string]uint),
+			walletAccounts: make(map[uint]uint),
+			webhooks:       make(map[uint]*database.Webhook),
+			deliveries:     make(map[uint]*database.WebhookDelivery),
+			holds:          make(map[uint]*database.Hold),
+			holdUUIDs:      make(map[string]uint),
+			scheduled:      make(map[uint]*database.ScheduledTransfer),
+			scheduledUUIDs: make(map[string]uint),
+
+			standingOrders:     make(map[uint]*database.StandingOrder),
+			standingOrderUUIDs: make(map[string]uint),
+
+			limits: make(map[string]*database.WalletLimit),
+		},
+	}
+}
+
+// Wallets возвращает репозиторий кошельков.
+func (s *Store) Wallets() database.WalletRepository {
+	return walletRepository{s}
+}
+
+// Transactions возвращает репозиторий транзакций.
+func (s *Store) Transactions() database.TransactionRepository {
+	return transactionRepository{s}
+}
+
+// Ledger возвращает репозиторий главной книги.
+func (s *Store) Ledger() database.LedgerRepository {
+	return ledgerRepository{s}
+}
+
+// Outbox возвращает исходящую очередь событий.
+func (s *Store) Outbox() database.OutboxRepository {
+	return outboxRepository{s}
+}
+
+// Webhooks возвращает репозиторий вебхуков.
+func (s *Store) Webhooks() database.WebhookRepository {
+	return webhookRepository{s}
+}
+
+// Holds возвращает репозиторий резервирований средств.
+func (s *Store) Holds() database.HoldRepository {
+	return holdRepository{s}
+}
+
+// ScheduledTransfers возвращает репозиторий запланированных переводов.
+func (s *Store) ScheduledTransfers() database.ScheduledTransferRepository {
+	return scheduledTransferRepository{s}
+}
+
+// StandingOrders возвращает репозиторий регулярных платежей.
+func (s *Store) StandingOrders() database.StandingOrderRepository {
+	return standingOrderRepository{s}
+}
+
+// Limits возвращает репозиторий лимитов расходов кошельков.
+func (s *Store) Limits() database.WalletLimitRepository {
+	return walletLimitRepository{s}
+}
+
+// InTransaction выполняет fn в транзакции хранилища.
+//
+// На время транзакции хранилище блокируется целиком. Если fn возвращает
+// ошибку или паникует, все изменения, сделанные в транзакции, откатываются.
+// Вложенный вызов выполняет fn в уже открытой транзакции и, как точка
+// сохранения базы данных, при ошибке откатывает только изменения fn.
+func (s *Store) InTransaction(fn func(tx database.Store) error) (err error) {
+	tx := s
+	if s.tx == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		tx = &Store{mu: s.mu, data: s.data, tx: &txLog{}}
+	}
+
+	mark := len(tx.tx.undo)
+	committed := false
+	defer func() {
+		if !committed {
+			tx.tx.rollbackTo(mark)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// rollbackTo отменяет в обратном порядке изменения транзакции,
+// сделанные после первых mark записей журнала.
+func (l *txLog) rollbackTo(mark int) {
+	for i := len(l.undo) - 1; i >= mark; i-- {
+		l.undo[i]()
+	}
+	l.undo = l.undo[:mark]
+}
+
+// read выполняет fn с доступом к данным на чтение.
+func (s *Store) read(fn func(data *state) error) error {
+	if s.tx == nil {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+	return fn(s.data)
+}
+
+// write выполняет fn с доступом к данным на запись.
+//
+// fn регистрирует отмену своих изменений через onRollback.
+func (s *Store) write(fn func(data *state) error) error {
+	if s.tx == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	return fn(s.data)
+}
+
+// onRollback регистрирует отмену изменения на случай отката транзакции.
+// Вне транзакции изменения применяются сразу и не отменяются.
+func (s *Store) onRollback(undo func()) {
+	if s.tx != nil {
+		s.tx.undo = append(s.tx.undo, undo)
+	}
+}
+
+// nextID выдаёт идентификатор новой записи.
+//
+// Как и последовательности базы данных, выданные идентификаторы
+// не возвращаются при откате транзакции.
+func (d *state) nextID() uint {
+	d.lastID++
+	return d.lastID
+}
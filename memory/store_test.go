@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"payment_system_api/database"
+	"payment_system_api/money"
+)
+
+// TestInTransactionRollback проверяет, что изменения транзакции, завершившейся
+// ошибкой, откатываются, а изменения успешной транзакции сохраняются.
+func TestInTransactionRollback(t *testing.T) {
+	store := New()
+	if err := store.Wallets().Create(&database.Wallet{Address: "a", Balance: 100}); err != nil {
+		t.Fatalf("Не удалось создать кошелек: %v", err)
+	}
+
+	errAbort := errors.New("abort")
+	err := store.InTransaction(func(tx database.Store) error {
+		wallet, err := tx.Wallets().LockByAddress("a")
+		if err != nil {
+			return err
+		}
+		if err := tx.Wallets().AddBalance(wallet.ID, -40); err != nil {
+			return err
+		}
+		if err := tx.Wallets().Create(&database.Wallet{Address: "b"}); err != nil {
+			return err
+		}
+		key := "key"
+		if err := tx.Transactions().Create(&database.Transaction{FromAddress: "a", ToAddress: "b", Amount: 40, ToAmount: 40, IdempotencyKey: &key}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Ожидалась ошибка транзакции, получена %v", err)
+	}
+
+	wallet, err := store.Wallets().FindByAddress("a")
+	if err != nil || wallet.Balance != 100 {
+		t.Errorf("Баланс должен откатиться до 100, получено %+v, %v", wallet, err)
+	}
+	if _, err := store.Wallets().FindByAddress("b"); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("Созданный в транзакции кошелек должен исчезнуть, получена ошибка %v", err)
+	}
+	if _, err := store.Transactions().FindByIdempotencyKey("key"); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("Созданная в транзакции запись должна исчезнуть, получена ошибка %v", err)
+	}
+
+	err = store.InTransaction(func(tx database.Store) error {
+		return tx.Wallets().AddBalance(wallet.ID, -40)
+	})
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if wallet, _ := store.Wallets().FindByAddress("a"); wallet.Balance != 60 {
+		t.Errorf("Ожидался баланс 60, получено %d", wallet.Balance)
+	}
+}
+
+// TestNestedTransactionRollback проверяет, что вложенная транзакция,
+// завершившаяся ошибкой, откатывает только свои изменения, а внешняя
+// транзакция сохраняет остальные.
+func TestNestedTransactionRollback(t *testing.T) {
+	store := New()
+	if err := store.Wallets().Create(&database.Wallet{Address: "a", Balance: 100}); err != nil {
+		t.Fatalf("Не удалось создать кошелек: %v", err)
+	}
+
+	errAbort := errors.New("abort")
+	err := store.InTransaction(func(tx database.Store) error {
+		wallet, err := tx.Wallets().LockByAddress("a")
+		if err != nil {
+			return err
+		}
+		if err := tx.Wallets().AddBalance(wallet.ID, -10); err != nil {
+			return err
+		}
+		err = tx.InTransaction(func(tx database.Store) error {
+			if err := tx.Wallets().AddBalance(wallet.ID, -20); err != nil {
+				return err
+			}
+			if err := tx.Wallets().Create(&database.Wallet{Address: "b"}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Errorf("Ожидалась ошибка вложенной транзакции, получена %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+
+	if wallet, err := store.Wallets().FindByAddress("a"); err != nil || wallet.Balance != 90 {
+		t.Errorf("Ожидался баланс 90, получено %+v, %v", wallet, err)
+	}
+	if _, err := store.Wallets().FindByAddress("b"); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("Созданный во вложенной транзакции кошелек должен исчезнуть, получена ошибка %v", err)
+	}
+}
+
+// TestFindTransactions проверяет выборку транзакций кошелька по направлению,
+// границам суммы и позиции.
+func TestFindTransactions(t *testing.T) {
+	store := New()
+	for _, address := range []string{"a", "b", "c"} {
+		if err := store.Wallets().Create(&database.Wallet{Address: address}); err != nil {
+			t.Fatalf("Не удалось создать кошелек: %v", err)
+		}
+	}
+	transfers := []database.Transaction{
+		{FromAddress: "a", ToAddress: "b", Amount: 100, ToAmount: 100},
+		{FromAddress: "b", ToAddress: "a", Amount: 200, ToAmount: 200},
+		{FromAddress: "a", ToAddress: "c", Amount: 300, ToAmount: 300},
+	}
+	for i := range transfers {
+		if err := store.Transactions().Create(&transfers[i]); err != nil {
+			t.Fatalf("Не удалось создать транзакцию: %v", err)
+		}
+	}
+
+	minAmount := money.Amount(150)
+	cases := []struct {
+		name  string
+		query database.TransactionQuery
+		want  []uint
+	}{
+		{"все", database.TransactionQuery{}, []uint{transfers[2].ID, transfers[1].ID, transfers[0].ID}},
+		{"исходящие", database.TransactionQuery{Address: "a", Outgoing: true}, []uint{transfers[2].ID, transfers[0].ID}},
+		{"входящие", database.TransactionQuery{Address: "a", Incoming: true}, []uint{transfers[1].ID}},
+		{"минимальная сумма", database.TransactionQuery{Address: "a", Outgoing: true, Incoming: true, MinAmount: &minAmount},
+			[]uint{transfers[2].ID, transfers[1].ID}},
+		{"после позиции", database.TransactionQuery{Before: &database.Position{Timestamp: transfers[1].Timestamp, ID: transfers[1].ID}},
+			[]uint{transfers[0].ID}},
+		{"лимит", database.TransactionQuery{Limit: 1}, []uint{transfers[2].ID}},
+	}
+	for _, tc := range cases {
+		found, err := store.Transactions().Find(tc.query)
+		if err != nil {
+			t.Fatalf("%s: неожиданная ошибка %v", tc.name, err)
+		}
+		var got []uint
+		for _, transaction := range found {
+			got = append(got, transaction.ID)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: ожидалось %v, получено %v", tc.name, tc.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: ожидалось %v, получено %v", tc.name, tc.want, got)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"payment_system_api/database"
+)
+
+// webhookRepository — реализация database.WebhookRepository в памяти.
+type webhookRepository struct {
+	s *Store
+}
+
+// Create сохраняет новый вебхук.
+func (r webhookRepository) Create(webhook *database.Webhook) error {
+	return r.s.write(func(d *state) error {
+		if webhook.WalletAddress != nil {
+			if _, ok := d.addresses[*webhook.WalletAddress]; !ok {
+				return database.ErrUnknownWallet
+			}
+		}
+		webhook.ID = d.nextID()
+		webhook.CreatedAt = time.Now()
+		stored := *webhook
+		d.webhooks[stored.ID] = &stored
+		r.s.onRollback(func() { delete(d.webhooks, stored.ID) })
+		return nil
+	})
+}
+
+// Find возвращает вебхук по идентификатору.
+func (r webhookRepository) Find(id uint) (*database.Webhook, error) {
+	var webhook database.Webhook
+	err := r.s.read(func(d *state) error {
+		stored, ok := d.webhooks[id]
+		if !ok {
+			return database.ErrNotFound
+		}
+		webhook = *stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// List возвращает все вебхуки в порядке регистрации.
+func (r webhookRepository) List() ([]database.Webhook, error) {
+	return r.filter(func(*database.Webhook) bool { return true })
+}
+
+// Delete удаляет вебхук вместе с его доставками.
+func (r webhookRepository) Delete(id uint) error {
+	return r.s.write(func(d *state) error {
+		webhook, ok := d.webhooks[id]
+		if !ok {
+			return database.ErrNotFound
+		}
+		delete(d.webhooks, id)
+		r.s.onRollback(func() { d.webhooks[id] = webhook })
+		for _, delivery := range d.deliveries {
+			if delivery.WebhookID == id {
+				r.deleteDelivery(d, delivery)
+			}
+		}
+		return nil
+	})
+}
+
+// Subscribers возвращает вебхуки кошелька и вебхуки всех кошельков.
+func (r webhookRepository) Subscribers(address string) ([]database.Webhook, error) {
+	return r.filter(func(webhook *database.Webhook) bool {
+		return webhook.WalletAddress == nil || *webhook.WalletAddress == address
+	})
+}
+
+// filter возвращает вебхуки, для которых match возвращает true, в порядке регистрации.
+func (r webhookRepository) filter(match func(webhook *database.Webhook) bool) ([]database.Webhook, error) {
+	var webhooks []database.Webhook
+	err := r.s.read(func(d *state) error {
+		for _, webhook := range d.webhooks {
+			if match(webhook) {
+				webhooks = append(webhooks, *webhook)
+			}
+		}
+		return nil
+	})
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, err
+}
+
+// CreateDelivery сохраняет новую доставку.
+func (r webhookRepository) CreateDelivery(delivery *database.WebhookDelivery) error {
+	return r.s.write(func(d *state) error {
+		delivery.ID = d.nextID()
+		delivery.CreatedAt = time.Now()
+		stored := *delivery
+		d.deliveries[stored.ID] = &stored
+		r.s.onRollback(func() { delete(d.deliveries, stored.ID) })
+		return nil
+	})
+}
+
+// FindDelivery возвращает доставку по идентификатору.
+func (r webhookRepository) FindDelivery(id uint) (*database.WebhookDelivery, error) {
+	var delivery database.WebhookDelivery
+	err := r.s.read(func(d *state) error {
+		stored, ok := d.deliveries[id]
+		if !ok {
+			return database.ErrNotFound
+		}
+		delivery = *stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries возвращает страницу доставок от новых к старым.
+func (r webhookRepository) ListDeliveries(query database.DeliveryQuery, offset, limit int) ([]database.WebhookDelivery, error) {
+	var deliveries []database.WebhookDelivery
+	err := r.s.read(func(d *state) error {
+		deliveries = selectDeliveries(d, query)
+		return nil
+	})
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	if offset >= len(deliveries) {
+		return nil, err
+	}
+	return deliveries[offset:min(offset+limit, len(deliveries))], err
+}
+
+// CountDeliveries возвращает число доставок, выбранных query.
+func (r webhookRepository) CountDeliveries(query database.DeliveryQuery) (int64, error) {
+	var count int64
+	err := r.s.read(func(d *state) error {
+		count = int64(len(selectDeliveries(d, query)))
+		return nil
+	})
+	return count, err
+}
+
+// selectDeliveries возвращает доставки, выбранные query, в произвольном порядке.
+func selectDeliveries(d *state, query database.DeliveryQuery) []database.WebhookDelivery {
+	var deliveries []database.WebhookDelivery
+	for _, delivery := range d.deliveries {
+		if query.WebhookID != 0 && delivery.WebhookID != query.WebhookID {
+			continue
+		}
+		if query.Status != "" && delivery.Status != query.Status {
+			continue
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries
+}
+
+// ClaimDeliveries выбирает наступившие доставки и переносит их следующую попытку.
+// Транзакции хранилища выполняются по очереди, поэтому блокировка не нужна.
+func (r webhookRepository) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]database.WebhookDelivery, error) {
+	var claimed []database.WebhookDelivery
+	err := r.s.write(func(d *state) error {
+		var due []*database.WebhookDelivery
+		for _, delivery := range d.deliveries {
+			if delivery.Status == database.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+				due = append(due, delivery)
+			}
+		}
+		sort.Slice(due, func(i, j int) bool {
+			if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+				return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+			}
+			return due[i].ID < due[j].ID
+		})
+		for _, delivery := range due[:min(limit, len(due))] {
+			previous := *delivery
+			delivery.NextAttemptAt = now.Add(lease)
+			r.s.onRollback(func() { *delivery = previous })
+			claimed = append(claimed, *delivery)
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// UpdateDelivery сохраняет состояние доставки.
+func (r webhookRepository) UpdateDelivery(delivery *database.WebhookDelivery) error {
+	return r.s.write(func(d *state) error {
+		stored, ok := d.deliveries[delivery.ID]
+		if !ok {
+			return nil
+		}
+		previous := *stored
+		stored.Status = delivery.Status
+		stored.Attempts = delivery.Attempts
+		stored.NextAttemptAt = delivery.NextAttemptAt
+		stored.LastError = delivery.LastError
+		stored.ResponseStatus = delivery.ResponseStatus
+		stored.DeliveredAt = delivery.DeliveredAt
+		r.s.onRollback(func() { *stored = previous })
+		return nil
+	})
+}
+
+// DeleteDelivery удаляет доставку.
+func (r webhookRepository) DeleteDelivery(id uint) error {
+	return r.s.write(func(d *state) error {
+		delivery, ok := d.deliveries[id]
+		if !ok {
+			return database.ErrNotFound
+		}
+		r.deleteDelivery(d, delivery)
+		return nil
+	})
+}
+
+// deleteDelivery удаляет доставку delivery с возможностью отката.
+func (r webhookRepository) deleteDelivery(d *state, delivery *database.WebhookDelivery) {
+	delete(d.deliveries, delivery.ID)
+	r.s.onRollback(func() { d.deliveries[delivery.ID] = delivery })
+}
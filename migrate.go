@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"payment_system_api/config"
+	"payment_system_api/database"
+)
+
+// migrateUsage описывает аргументы подкоманды migrate.
+const migrateUsage = "использование: payment_api migrate up | down [N] | status"
+
+// runMigrate выполняет подкоманду migrate с аргументами args
+// над базой данных из конфигурации cfg, не запуская сервер.
+//
+// При ошибке завершает работу программы.
+func runMigrate(cfg *config.Config, args []string) {
+	if cfg.Storage != config.StorageDatabase {
+		log.Fatalf("Миграции выполняются только для хранилища в базе данных, STORAGE=%s", cfg.Storage)
+	}
+	database.ConnectDB(cfg.DatabaseURL)
+	if err := migrateCommand(database.DB, args, os.Stdout); err != nil {
+		log.Fatalf("Миграция базы данных не удалась: %v", err)
+	}
+}
+
+// migrateCommand выполняет над базой данных db команду args и выводит результат в out:
+//   - up — применяет все неприменённые миграции;
+//   - down [N] — откатывает N последних применённых миграций, по умолчанию одну;
+//   - status — выводит список миграций и время их применения.
+func migrateCommand(db *gorm.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	switch command := args[0]; {
+	case command == "up" && len(args) == 1:
+		applied, err := database.MigrateUp(db)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Применено миграций: %d\n", applied)
+	case command == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("некорректное число миграций для отката: %q", args[1])
+			}
+			steps = n
+		}
+		rolledBack, err := database.MigrateDown(db, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Откачено миграций: %d\n", rolledBack)
+	case command == "status" && len(args) == 1:
+		statuses, err := database.MigrationStatuses(db)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "не применена"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(out, "%04d_%s\t%s\n", status.Version, status.Name, applied)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
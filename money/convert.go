@@ -0,0 +1,45 @@
+package money
+
+import "math/big"
+
+// Convert переводит сумму amount в валюте from в валюту to по курсу rate
+// (количество единиц to за одну единицу from).
+//
+// Результат округляется до минимальных единиц валюты to по правилу
+// банковского округления: половина округляется к ближайшему чётному.
+// Возвращает ErrOutOfRange, если результат превышает MaxAmount.
+func Convert(amount Amount, from Currency, rate *big.Rat, to Currency) (Amount, error) {
+	// amount / 10^expFrom * rate * 10^expTo
+	value := new(big.Rat).SetInt64(int64(amount))
+	value.Mul(value, rate)
+	value.Mul(value, pow10(to.Exponent()))
+	value.Quo(value, pow10(from.Exponent()))
+
+	rounded := roundHalfEven(value)
+	if !rounded.IsInt64() || Amount(rounded.Int64()) > MaxAmount || Amount(rounded.Int64()) < -MaxAmount {
+		return 0, ErrOutOfRange
+	}
+	return Amount(rounded.Int64()), nil
+}
+
+// roundHalfEven округляет рациональное число до целого по правилу банковского округления.
+func roundHalfEven(value *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	// Сравниваем удвоенный остаток со знаменателем, чтобы определить, больше ли он половины.
+	doubled := new(big.Int).Abs(remainder)
+	doubled.Lsh(doubled, 1)
+	cmp := doubled.Cmp(value.Denom())
+	if cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1) {
+		if value.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+// pow10 возвращает 10^n в виде рационального числа.
+func pow10(n int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil))
+}
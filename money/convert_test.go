@@ -0,0 +1,46 @@
+package money
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestConvert проверяет конвертацию сумм по курсу.
+//
+// Проверяются разные числа знаков после запятой у валют и банковское
+// округление: половина округляется к ближайшему чётному.
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		amount Amount
+		from   Currency
+		rate   string
+		to     Currency
+		want   Amount
+	}{
+		{1000, "USD", "92.5", "RUB", 92500}, // 10.00 USD → 925.00 RUB
+		{100, "USD", "150.25", "JPY", 150},  // 1.00 USD → 150.25 JPY → 150
+		{100, "USD", "150.5", "JPY", 150},   // 150.5 → 150 (к чётному)
+		{100, "USD", "151.5", "JPY", 152},   // 151.5 → 152 (к чётному)
+		{100, "USD", "150.51", "JPY", 151},  // больше половины — вверх
+		{150, "JPY", "0.0067", "USD", 100},  // 150 JPY → 1.005 USD → 1.00 (к чётному)
+		{1000, "KWD", "3.25", "USD", 325},   // 1.000 KWD → 3.25 USD
+		{1, "RUB", "0.005", "USD", 0},       // 0.01 RUB → 0.00005 USD → 0
+		{-100, "USD", "150.5", "JPY", -150}, // отрицательные суммы симметричны
+		{-100, "USD", "151.5", "JPY", -152},
+	}
+	for _, tc := range cases {
+		rate, _ := new(big.Rat).SetString(tc.rate)
+		got, err := Convert(tc.amount, tc.from, rate, tc.to)
+		if err != nil {
+			t.Errorf("Convert(%d %s × %s → %s): неожиданная ошибка %v", tc.amount, tc.from, tc.rate, tc.to, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Convert(%d %s × %s → %s): ожидалось %d, получено %d", tc.amount, tc.from, tc.rate, tc.to, tc.want, got)
+		}
+	}
+
+	if _, err := Convert(MaxAmount, "RUB", big.NewRat(2, 1), "RUB"); err != ErrOutOfRange {
+		t.Errorf("Ожидалась ошибка ErrOutOfRange, получена %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Currency — трёхбуквенный код валюты по ISO 4217, например "RUB".
+type Currency string
+
+// DefaultCurrency — валюта кошельков, для которых валюта не указана явно.
+const DefaultCurrency Currency = "RUB"
+
+// ErrUnknownCurrency — код валюты не входит в список поддерживаемых.
+var ErrUnknownCurrency = errors.New("неизвестный код валюты")
+
+// exponents содержит число знаков после запятой (minor unit) для поддерживаемых валют ISO 4217.
+var exponents = map[Currency]int{
+	"RUB": 2, "USD": 2, "EUR": 2, "GBP": 2, "CHF": 2, "CNY": 2, "KZT": 2, "BYN": 2,
+	"UAH": 2, "TRY": 2, "INR": 2, "AED": 2, "CAD": 2, "AUD": 2, "SEK": 2, "NOK": 2,
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0, "PYG": 0, "UGX": 0,
+	"KWD": 3, "BHD": 3, "OMR": 3, "JOD": 3, "TND": 3, "LYD": 3, "IQD": 3,
+}
+
+// ParseCurrency проверяет код валюты и возвращает его как Currency.
+//
+// Код приводится к верхнему регистру. Возвращает ErrUnknownCurrency,
+// если валюта не поддерживается.
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(code))
+	if _, ok := exponents[currency]; !ok {
+		return "", ErrUnknownCurrency
+	}
+	return currency, nil
+}
+
+// Exponent возвращает число знаков после запятой в суммах валюты.
+//
+// Для неизвестной валюты возвращает 2.
+func (c Currency) Exponent() int {
+	if exponent, ok := exponents[c]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// UnmarshalJSON разбирает код валюты из JSON-строки и проверяет его.
+func (c *Currency) UnmarshalJSON(data []byte) error {
+	var code string
+	if err := json.Unmarshal(data, &code); err != nil {
+		return ErrUnknownCurrency
+	}
+	currency, err := ParseCurrency(code)
+	if err != nil {
+		return err
+	}
+	*c = currency
+	return nil
+}
@@ -0,0 +1,179 @@
+// Package money содержит типы денежных сумм, общие для API, бизнес-логики и базы данных.
+//
+// Суммы хранятся точно — целым числом минимальных единиц валюты (Amount),
+// а в API передаются десятичной строкой, например "12.34" (Decimal).
+// Число знаков после запятой определяется валютой (Currency).
+//
+// Политика округления: суммы никогда не округляются неявно.
+// Значение, в котором знаков после запятой больше, чем допускает валюта,
+// отклоняется с ошибкой ErrTooManyDecimals. Единственное место, где
+// округление неизбежно, — конвертация по курсу (Convert): результат
+// округляется до минимальных единиц валюты зачисления по правилу
+// банковского округления (половина — к ближайшему чётному).
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// MaxAmount — максимальная по модулю сумма в минимальных единицах валюты.
+const MaxAmount Amount = 999_999_999_999_999
+
+// Ошибки разбора денежных сумм.
+var (
+	// ErrInvalidFormat — строка не является десятичным числом.
+	ErrInvalidFormat = errors.New("сумма должна быть десятичным числом в виде строки, например \"12.34\"")
+	// ErrTooManyDecimals — в сумме больше знаков после запятой, чем допускает валюта.
+	ErrTooManyDecimals = errors.New("слишком много знаков после запятой в сумме")
+	// ErrOutOfRange — сумма превышает MaxAmount.
+	ErrOutOfRange = errors.New("сумма вне допустимого диапазона")
+)
+
+// Amount — денежная сумма в минимальных единицах валюты (копейках, центах, иенах).
+type Amount int64
+
+// Decimal формирует десятичное представление суммы в валюте currency.
+func (a Amount) Decimal(currency Currency) Decimal {
+	return Decimal(format(int64(a), currency.Exponent()))
+}
+
+// format форматирует value минимальных единиц с exponent знаками после запятой.
+func format(value int64, exponent int) string {
+	sign := ""
+	abs := uint64(value)
+	if value < 0 {
+		sign = "-"
+		abs = uint64(-value)
+	}
+	digits := strconv.FormatUint(abs, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	point := len(digits) - exponent
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// Decimal — точная десятичная сумма в виде строки, например "12.34".
+//
+// Decimal используется на границе API: сумма ещё не привязана к валюте
+// и переводится в Amount методом Amount, когда валюта известна.
+type Decimal string
+
+// ParseDecimal проверяет, что s — десятичное число, и возвращает его как Decimal.
+//
+// Допускаются необязательный знак минус, целая часть и дробная часть после точки.
+// Экспоненциальная запись, пробелы и разделители разрядов не допускаются.
+func ParseDecimal(s string) (Decimal, error) {
+	if _, _, _, err := split(s); err != nil {
+		return "", err
+	}
+	return Decimal(s), nil
+}
+
+// Amount переводит десятичную сумму в минимальные единицы валюты currency.
+//
+// Возвращает ErrTooManyDecimals, если знаков после запятой больше,
+// чем допускает валюта, и ErrOutOfRange, если сумма превышает MaxAmount.
+func (d Decimal) Amount(currency Currency) (Amount, error) {
+	negative, intPart, fracPart, err := split(string(d))
+	if err != nil {
+		return 0, err
+	}
+	exponent := currency.Exponent()
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > exponent {
+		return 0, ErrTooManyDecimals
+	}
+
+	digits := strings.TrimLeft(intPart+fracPart+strings.Repeat("0", exponent-len(fracPart)), "0")
+	if digits == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || Amount(value) > MaxAmount {
+		return 0, ErrOutOfRange
+	}
+	if negative {
+		value = -value
+	}
+	return Amount(value), nil
+}
+
+// Sign возвращает -1, 0 или +1 в зависимости от знака суммы.
+// Для некорректной строки возвращает 0.
+func (d Decimal) Sign() int {
+	negative, intPart, fracPart, err := split(string(d))
+	if err != nil || strings.Trim(intPart+fracPart, "0") == "" {
+		return 0
+	}
+	if negative {
+		return -1
+	}
+	return 1
+}
+
+// Canonical возвращает каноническую запись суммы без незначащих нулей,
+// например "012.50" → "12.5". Равные суммы имеют одинаковую каноническую запись.
+func (d Decimal) Canonical() string {
+	negative, intPart, fracPart, err := split(string(d))
+	if err != nil {
+		return string(d)
+	}
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	fracPart = strings.TrimRight(fracPart, "0")
+	s := intPart
+	if fracPart != "" {
+		s += "." + fracPart
+	}
+	if negative && s != "0" {
+		s = "-" + s
+	}
+	return s
+}
+
+// split разбирает десятичную строку на знак, целую и дробную части.
+func split(s string) (negative bool, intPart, fracPart string, err error) {
+	negative = strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	intPart, fracPart, hasPoint := strings.Cut(s, ".")
+	if intPart == "" || (hasPoint && fracPart == "") || !isDigits(intPart) || !isDigits(fracPart) {
+		return false, "", "", ErrInvalidFormat
+	}
+	return negative, intPart, fracPart, nil
+}
+
+// isDigits сообщает, состоит ли строка только из десятичных цифр.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// UnmarshalJSON разбирает сумму из JSON-строки.
+//
+// JSON-числа не принимаются, поскольку при разборе в float64 теряется точность.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return ErrInvalidFormat
+	}
+	value, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = value
+	return nil
+}
@@ -0,0 +1,132 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestDecimalAmount проверяет перевод десятичных строк в минимальные единицы валюты.
+//
+// Проверяются точность (в том числе значения, теряющие точность в float64),
+// число знаков после запятой для разных валют, отказ от неявного округления
+// и строгая проверка формата и диапазона.
+func TestDecimalAmount(t *testing.T) {
+	cases := []struct {
+		input    string
+		currency Currency
+		want     Amount
+		wantErr  error
+	}{
+		{"12.34", "RUB", 1234, nil},
+		{"0.29", "RUB", 29, nil},
+		{"0.1", "USD", 10, nil},
+		{"5", "RUB", 500, nil},
+		{"007.50", "RUB", 750, nil},
+		{"1.000", "RUB", 100, nil},
+		{"0", "RUB", 0, nil},
+		{"-3.01", "RUB", -301, nil},
+		{"150", "JPY", 150, nil},
+		{"1.234", "KWD", 1234, nil},
+		{"9999999999999.99", "RUB", MaxAmount, nil},
+		{"10000000000000.00", "RUB", 0, ErrOutOfRange},
+		{"99999999999999999999", "RUB", 0, ErrOutOfRange},
+		{"0.291", "RUB", 0, ErrTooManyDecimals},
+		{"1.5", "JPY", 0, ErrTooManyDecimals},
+		{"1.2345", "KWD", 0, ErrTooManyDecimals},
+		{"", "RUB", 0, ErrInvalidFormat},
+		{"-", "RUB", 0, ErrInvalidFormat},
+		{".5", "RUB", 0, ErrInvalidFormat},
+		{"5.", "RUB", 0, ErrInvalidFormat},
+		{"1e3", "RUB", 0, ErrInvalidFormat},
+		{"+1", "RUB", 0, ErrInvalidFormat},
+		{" 1", "RUB", 0, ErrInvalidFormat},
+		{"1,5", "RUB", 0, ErrInvalidFormat},
+	}
+
+	for _, tc := range cases {
+		got, err := Decimal(tc.input).Amount(tc.currency)
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%q %s: ожидалась ошибка %v, получена %v", tc.input, tc.currency, tc.wantErr, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q %s: ожидалось %d, получено %d", tc.input, tc.currency, tc.want, got)
+		}
+	}
+}
+
+// TestAmountDecimal проверяет форматирование сумм в десятичные строки.
+func TestAmountDecimal(t *testing.T) {
+	cases := []struct {
+		amount   Amount
+		currency Currency
+		want     Decimal
+	}{
+		{0, "RUB", "0.00"},
+		{5, "RUB", "0.05"},
+		{29, "RUB", "0.29"},
+		{1234, "RUB", "12.34"},
+		{-301, "RUB", "-3.01"},
+		{MaxAmount, "RUB", "9999999999999.99"},
+		{150, "JPY", "150"},
+		{5, "KWD", "0.005"},
+		{1234, "KWD", "1.234"},
+	}
+	for _, tc := range cases {
+		if got := tc.amount.Decimal(tc.currency); got != tc.want {
+			t.Errorf("Amount(%d).Decimal(%s): ожидалось %q, получено %q", tc.amount, tc.currency, tc.want, got)
+		}
+	}
+}
+
+// TestDecimalCanonical проверяет каноническую запись и знак десятичных сумм.
+func TestDecimalCanonical(t *testing.T) {
+	cases := []struct {
+		input     Decimal
+		canonical string
+		sign      int
+	}{
+		{"012.50", "12.5", 1},
+		{"0.00", "0", 0},
+		{"-0", "0", 0},
+		{"-1.10", "-1.1", -1},
+		{"7", "7", 1},
+	}
+	for _, tc := range cases {
+		if got := tc.input.Canonical(); got != tc.canonical {
+			t.Errorf("%q.Canonical(): ожидалось %q, получено %q", tc.input, tc.canonical, got)
+		}
+		if got := tc.input.Sign(); got != tc.sign {
+			t.Errorf("%q.Sign(): ожидалось %d, получено %d", tc.input, tc.sign, got)
+		}
+	}
+}
+
+// TestJSON проверяет, что суммы принимаются в JSON только строками,
+// а коды валют проверяются при разборе.
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(Amount(1234).Decimal("RUB"))
+	if err != nil {
+		t.Fatalf("Не удалось закодировать сумму: %v", err)
+	}
+	if string(data) != `"12.34"` {
+		t.Errorf("Ожидалось %q, получено %s", `"12.34"`, data)
+	}
+
+	var decimal Decimal
+	if err := json.Unmarshal([]byte(`"0.29"`), &decimal); err != nil || decimal != "0.29" {
+		t.Errorf("Ожидалось \"0.29\" без ошибки, получено %q, %v", decimal, err)
+	}
+	if err := json.Unmarshal([]byte(`0.29`), &decimal); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("JSON-число должно отклоняться, получена ошибка %v", err)
+	}
+
+	var currency Currency
+	if err := json.Unmarshal([]byte(`"usd"`), &currency); err != nil || currency != "USD" {
+		t.Errorf("Ожидалось USD без ошибки, получено %q, %v", currency, err)
+	}
+	if err := json.Unmarshal([]byte(`"XXX"`), &currency); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("Неизвестная валюта должна отклоняться, получена ошибка %v", err)
+	}
+}
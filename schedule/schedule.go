@@ -0,0 +1,174 @@
+// Package schedule вычисляет моменты выполнения регулярных платежей
+// по cron-выражению или фиксированному интервалу.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron — cron-выражение не соответствует формату.
+var ErrInvalidCron = errors.New("некорректное cron-выражение")
+
+// Schedule — расписание выполнения.
+type Schedule interface {
+	// Next возвращает первый момент выполнения строго после after
+	// или нулевое время, если такого момента нет.
+	Next(after time.Time) time.Time
+}
+
+// Interval — расписание с фиксированным интервалом Every, начиная с момента Start.
+type Interval struct {
+	Start time.Time     // момент первого выполнения
+	Every time.Duration // интервал между выполнениями, больше нуля
+}
+
+// Next возвращает первый момент Start + k*Every, k ≥ 0, строго после after.
+func (i Interval) Next(after time.Time) time.Time {
+	if after.Before(i.Start) {
+		return i.Start
+	}
+	return i.Start.Add((after.Sub(i.Start)/i.Every + 1) * i.Every)
+}
+
+// cronSearchLimit — горизонт поиска момента выполнения по cron-выражению.
+// Выражения вроде «30 февраля» не выполняются никогда, и поиск для них
+// прекращается по достижении горизонта.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// Cron — расписание по cron-выражению из пяти полей:
+// минуты, часы, день месяца, месяц и день недели (0 и 7 — воскресенье).
+//
+// Поле — это «*», число, диапазон «a-b» или список таких значений через
+// запятую; к «*» и диапазону можно добавить шаг «/n». Если ограничены и день
+// месяца, и день недели, подходит день, совпадающий хотя бы с одним из них.
+// Моменты вычисляются в UTC.
+type Cron struct {
+	minutes, hours, days, months, weekdays uint64 // допустимые значения полей битами
+	anyDay, anyWeekday                     bool   // поле дня месяца или дня недели равно «*»
+}
+
+// ParseCron разбирает cron-выражение expr.
+// Возвращает ошибку, оборачивающую ErrInvalidCron, если выражение некорректно.
+func ParseCron(expr string) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: ожидалось 5 полей, получено %d", ErrInvalidCron, len(fields))
+	}
+	var (
+		c   Cron
+		err error
+	)
+	if c.minutes, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if c.hours, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if c.days, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if c.months, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if c.weekdays, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	if c.weekdays&(1<<7) != 0 {
+		c.weekdays |= 1
+	}
+	c.anyDay = fields[2] == "*"
+	c.anyWeekday = fields[4] == "*"
+	return &c, nil
+}
+
+// parseField разбирает поле cron-выражения со значениями от low до high.
+func parseField(field string, low, high int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		span, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%w: неверный шаг в %q", ErrInvalidCron, part)
+			}
+			span, step = part[:i], n
+		}
+
+		from, to := low, high
+		if span != "*" {
+			bounds := strings.SplitN(span, "-", 2)
+			var err error
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("%w: неверное значение %q", ErrInvalidCron, part)
+			}
+			to = from
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("%w: неверное значение %q", ErrInvalidCron, part)
+				}
+			} else if step != 1 {
+				// Шаг без диапазона, например «5/15», означает «с 5 до конца»
+				to = high
+			}
+		}
+		if from < low || to > high || from > to {
+			return 0, fmt.Errorf("%w: значение %q вне диапазона %d-%d", ErrInvalidCron, part, low, high)
+		}
+		for v := from; v <= to; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// Next возвращает первый момент выполнения строго после after с точностью до минуты
+// или нулевое время, если выражение не выполняется в ближайшие пять лет.
+func (c *Cron) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+	for t.Before(limit) {
+		switch {
+		case !has(c.months, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !has(c.hours, t.Hour()):
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !has(c.minutes, t.Minute()):
+			// Переход сразу к ближайшей допустимой минуте этого часа
+			if next := c.minutes >> (t.Minute() + 1); next != 0 {
+				t = t.Add(time.Duration(bits.TrailingZeros64(next)+1) * time.Minute)
+			} else {
+				t = t.Truncate(time.Hour).Add(time.Hour)
+			}
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchDay сообщает, подходит ли день t под поля дня месяца и дня недели.
+func (c *Cron) matchDay(t time.Time) bool {
+	day, weekday := has(c.days, t.Day()), has(c.weekdays, int(t.Weekday()))
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// has сообщает, входит ли значение v во множество set.
+func has(set uint64, v int) bool {
+	return set&(1<<v) != 0
+}
@@ -0,0 +1,76 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestInterval проверяет моменты выполнения расписания с фиксированным интервалом.
+func TestInterval(t *testing.T) {
+	start := time.Date(2025, 8, 25, 12, 0, 0, 0, time.UTC)
+	interval := Interval{Start: start, Every: time.Hour}
+
+	tests := []struct {
+		after time.Time
+		want  time.Time
+	}{
+		{start.Add(-time.Minute), start},
+		{start, start.Add(time.Hour)},
+		{start.Add(90 * time.Minute), start.Add(2 * time.Hour)},
+		{start.Add(2 * time.Hour), start.Add(3 * time.Hour)},
+	}
+	for _, tt := range tests {
+		if got := interval.Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("Next(%v) = %v, ожидалось %v", tt.after, got, tt.want)
+		}
+	}
+}
+
+// TestCron проверяет разбор cron-выражений и вычисление следующего момента выполнения.
+func TestCron(t *testing.T) {
+	// Понедельник
+	after := time.Date(2025, 8, 25, 12, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 8, 25, 12, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 8, 25, 12, 15, 0, 0, time.UTC)},
+		{"5 * * * *", time.Date(2025, 8, 25, 13, 5, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2025, 8, 26, 9, 0, 0, 0, time.UTC)},
+		{"0 9 1 * *", time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 0", time.Date(2025, 8, 31, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2025, 8, 31, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2025, 8, 26, 9, 0, 0, 0, time.UTC)},
+		{"0 9 15 * 3", time.Date(2025, 8, 27, 9, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"30 8,20 * 1,6 *", time.Date(2026, 1, 1, 8, 30, 0, 0, time.UTC)},
+		{"10-20/5 12 * * *", time.Date(2025, 8, 25, 12, 10, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := cron.Next(after); !got.Equal(tt.want) {
+			t.Errorf("Next для %q = %v, ожидалось %v", tt.expr, got, tt.want)
+		}
+	}
+
+	never, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("ParseCron: %v", err)
+	}
+	if got := never.Next(after); !got.IsZero() {
+		t.Errorf("Ожидалось нулевое время для 30 февраля, получено %v", got)
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr); !errors.Is(err, ErrInvalidCron) {
+			t.Errorf("ParseCron(%q): ожидалась ошибка ErrInvalidCron, получена %v", expr, err)
+		}
+	}
+}